@@ -3,9 +3,21 @@ package main
 import (
 	"context"
 	"flashcard/config"
+	"flashcard/internal/cards"
+	"flashcard/internal/classes"
+	"flashcard/internal/clientsync"
+	"flashcard/internal/dictionary"
+	"flashcard/internal/notifications"
+	"flashcard/internal/grading"
+	"flashcard/internal/notetypes"
+	"flashcard/internal/quiz"
+	"flashcard/internal/speech"
+	"flashcard/internal/storage"
 	"flashcard/internal/topics"
+	"flashcard/internal/translation"
 	"flashcard/internal/user"
 	"flashcard/internal/words"
+	"flashcard/middleware"
 	"log"
 	"net/http"
 	"os"
@@ -44,6 +56,12 @@ func main() {
 
 	r := gin.Default()
 
+	// Only trust X-Forwarded-For from configured proxies, so clients cannot
+	// pick their own IP and escape the per-IP rate limits.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
+
 r.Use(func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
 		log.Printf("Request from origin: '%s'", origin)
@@ -52,7 +70,8 @@ r.Use(func(c *gin.Context) {
 		
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Accept, X-Requested-With, Cache-Control")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Accept, X-Requested-With, Cache-Control, If-Match, If-None-Match")
+		c.Header("Access-Control-Expose-Headers", "Retry-After, ETag")
 		c.Header("Access-Control-Max-Age", "86400")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
@@ -66,22 +85,158 @@ r.Use(func(c *gin.Context) {
 	})
 	userCollections := mongoClient.Database("flashcard").Collection("users")
 	userRepository := user.NewUserRepository(userCollections)
-	userService := user.NewUserService(userRepository)
-	userHandler := user.NewUserHandler(userService)
+
+	tombstoneCollections := mongoClient.Database("flashcard").Collection("sync_tombstones")
+	tombstoneRepository := clientsync.NewTombstoneRepository(tombstoneCollections)
+	reviewReceiptCollections := mongoClient.Database("flashcard").Collection("sync_review_receipts")
+	reviewReceiptRepository := clientsync.NewReceiptRepository(reviewReceiptCollections)
+
+	topicCollections := mongoClient.Database("flashcard").Collection("topics")
+	topicRepository := topics.NewTopicRepository(topicCollections)
+	topicActivityCollections := mongoClient.Database("flashcard").Collection("topic_activities")
+	topicActivityRepository := topics.NewTopicActivityRepository(topicActivityCollections)
+	topicAccess := topics.NewTopicAccess(topicRepository, topicActivityRepository)
 
 	wordsCollections := mongoClient.Database("flashcard").Collection("words")
 	wordsRepository := words.NewWordRepository(wordsCollections)
-	wordsService := words.NewWordService(wordsRepository)
+	wordProgressCollections := mongoClient.Database("flashcard").Collection("word_progress")
+	wordProgressRepository := words.NewProgressRepository(wordProgressCollections)
+	blobStore, err := storage.NewLocalBlobStore(cfg.BlobStorageDir)
+	if err != nil {
+		panic(err)
+	}
+	noteTypeCollections := mongoClient.Database("flashcard").Collection("note_types")
+	noteTypeRepository := notetypes.NewNoteTypeRepository(noteTypeCollections)
+	noteTypeService := notetypes.NewNoteTypeService(noteTypeRepository, wordsRepository)
+	noteTypeHandler := notetypes.NewNoteTypeHandler(noteTypeService)
+
+	cardCollections := mongoClient.Database("flashcard").Collection("cards")
+	cardRepository := cards.NewCardRepository(cardCollections)
+	cardScheduleCollections := mongoClient.Database("flashcard").Collection("card_schedules")
+	cardScheduleRepository := cards.NewScheduleRepository(cardScheduleCollections)
+	cardService := cards.NewCardService(cardRepository, cardScheduleRepository, wordsRepository, wordProgressRepository, topicAccess)
+	cardHandler := cards.NewCardHandler(cardService)
+
+	dictionaryEntryCollections := mongoClient.Database("flashcard").Collection("dictionary_entries")
+	dictionaryEntryRepository := dictionary.NewEntryRepository(dictionaryEntryCollections)
+	dictionaryImportCollections := mongoClient.Database("flashcard").Collection("dictionary_imports")
+	dictionaryImportRepository := dictionary.NewImportRepository(dictionaryImportCollections)
+	dictionaryProviders := []dictionary.DictionaryProvider{dictionary.NewOfflineProvider(dictionaryEntryRepository)}
+	if cfg.DictionaryAPIURL != "" {
+		dictionaryProviders = append(dictionaryProviders, dictionary.NewHTTPProvider(cfg.DictionaryAPIURL, cfg.DictionaryTimeout))
+	}
+	dictionaryService := dictionary.NewDictionaryService(dictionary.NewChainProvider(dictionaryProviders...), dictionaryEntryRepository, dictionaryImportRepository, cfg.DictionaryDefaultLanguage)
+	dictionaryHandler := dictionary.NewDictionaryHandler(dictionaryService)
+
+	translator, err := translation.NewTranslator(cfg)
+	if err != nil {
+		panic(err)
+	}
+	if translator != nil {
+		translationCacheCollections := mongoClient.Database("flashcard").Collection("translation_cache")
+		translator = translation.NewCachingTranslator(translator, translation.NewCacheRepository(translationCacheCollections))
+	}
+
+	ttsProvider, err := speech.NewTTSProvider(cfg)
+	if err != nil {
+		panic(err)
+	}
+	var speechService speech.SpeechService
+	if ttsProvider != nil {
+		speechClipCollections := mongoClient.Database("flashcard").Collection("speech_clips")
+		speechService = speech.NewSpeechService(ttsProvider, speech.NewClipRepository(speechClipCollections), blobStore, cfg.TTSDefaultVoice)
+	}
+
+	wordsService := words.NewWordService(wordsRepository, wordProgressRepository, topicRepository, topicAccess, blobStore, cfg.MaxAudioUploadBytes, noteTypeService, cardService, tombstoneRepository, dictionaryService, translator, speechService)
 	wordsHandler := words.NewWordHandler(wordsService)
 
-	topicCollections := mongoClient.Database("flashcard").Collection("topics")
-	topicRepository := topics.NewTopicRepository(topicCollections)
-	topicService := topics.NewTopicService(topicRepository, wordsService)
+	topicService := topics.NewTopicService(topicRepository, topicActivityRepository, wordsService, userRepository, tombstoneRepository)
 	topicHandler := topics.NewTopicHandler(topicService)
 
+	syncService := clientsync.NewSyncService(topicService, wordsService, tombstoneRepository, reviewReceiptRepository)
+	syncHandler := clientsync.NewSyncHandler(syncService)
+
+	classCollections := mongoClient.Database("flashcard").Collection("classes")
+	classRepository := classes.NewClassRepository(classCollections)
+	classService := classes.NewClassService(classRepository, topicService, wordsService, userRepository)
+	classHandler := classes.NewClassHandler(classService)
+
+	gradingService := grading.NewGradingService(wordsService)
+	gradingHandler := grading.NewGradingHandler(gradingService)
+
+	quizCollections := mongoClient.Database("flashcard").Collection("quizzes")
+	quizRepository := quiz.NewQuizRepository(quizCollections)
+	quizAttemptCollections := mongoClient.Database("flashcard").Collection("quiz_attempts")
+	quizAttemptRepository := quiz.NewAttemptRepository(quizAttemptCollections)
+	quizService := quiz.NewQuizService(quizRepository, quizAttemptRepository, wordsService)
+	quizHandler := quiz.NewQuizHandler(quizService)
+
+	notifiers := []notifications.Notifier{notifications.NewWebhookNotifier(cfg.WebhookSecret, cfg.NotificationTimeout)}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, notifications.NewEmailNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom))
+	}
+	if cfg.VAPIDPrivateKey != "" {
+		webPushNotifier, err := notifications.NewWebPushNotifier(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject, cfg.NotificationTimeout)
+		if err != nil {
+			panic(err)
+		}
+		notifiers = append(notifiers, webPushNotifier)
+	}
+	notificationPreferenceCollections := mongoClient.Database("flashcard").Collection("notification_preferences")
+	notificationPreferenceRepository := notifications.NewPreferenceRepository(notificationPreferenceCollections)
+	notificationService := notifications.NewNotificationService(notificationPreferenceRepository, cardService, userRepository, notifications.NewSystemClock(), cfg.AppURL, notifiers...)
+	notificationHandler := notifications.NewNotificationHandler(notificationService)
+
+	passwordHasher, err := user.NewPasswordHasher(cfg)
+	if err != nil {
+		panic(err)
+	}
+	passwordPolicy, err := user.NewPasswordPolicy(cfg)
+	if err != nil {
+		panic(err)
+	}
+	userService := user.NewUserService(userRepository, passwordHasher, passwordPolicy, cfg, classService, topicService, wordsService, cardService, noteTypeService, quizService, syncService, notificationService)
+	oauthProviders, err := user.NewOAuthProviders(cfg)
+	if err != nil {
+		panic(err)
+	}
+	oauthStateCollections := mongoClient.Database("flashcard").Collection("oauth_states")
+	oauthStateRepository := user.NewOAuthStateRepository(oauthStateCollections)
+	oauthService := user.NewOAuthService(oauthProviders, oauthStateRepository, userRepository, userService, cfg.OAuthStateTTL)
+	userHandler := user.NewUserHandler(userService, oauthService)
+
+	middleware.SetSessionValidator(userService)
+	go runAccountPurger(userService, cfg.AccountPurgeInterval)
+	go runReminderScheduler(notificationService, cfg.ReminderInterval)
+	go migrateWordSchema(wordsService)
+	go generateMissingCards(cardService)
+	if cfg.DictionaryImportFile != "" {
+		go importDictionary(dictionaryService, cfg.DictionaryImportFile)
+	}
+
 	words.RegisterRoutes(r, wordsHandler)
 	topics.RegisterRoutes(r, topicHandler)
-	user.RegisterRoutes(r, userHandler)
+	classes.RegisterRoutes(r, classHandler)
+	notetypes.RegisterRoutes(r, noteTypeHandler)
+	cards.RegisterRoutes(r, cardHandler)
+	quiz.RegisterRoutes(r, quizHandler)
+	grading.RegisterRoutes(r, gradingHandler)
+	clientsync.RegisterRoutes(r, syncHandler)
+	dictionary.RegisterRoutes(r, dictionaryHandler)
+	notifications.RegisterRoutes(r, notificationHandler)
+	if speechService != nil {
+		speech.RegisterRoutes(r, speech.NewSpeechHandler(speechService))
+	}
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+	ipRateLimiter := middleware.RateLimitMiddleware(rateLimitStore, middleware.RateLimit{
+		Requests: cfg.AuthRateLimit,
+		Window:   cfg.AuthRateWindow,
+	}, middleware.ClientIPKey)
+	accountRateLimiter := middleware.RateLimitMiddleware(rateLimitStore, middleware.RateLimit{
+		Requests: cfg.AccountRateLimit,
+		Window:   cfg.AccountRateWindow,
+	}, middleware.JSONFieldKey("email"))
+	user.RegisterRoutes(r, userHandler, ipRateLimiter, accountRateLimiter)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -109,4 +264,71 @@ func connectToMongoDB(uri string) (*mongo.Client, error) {
 
 	log.Println("Successfully connected to MongoDB")
 	return client, nil
-}
\ No newline at end of file
+}
+
+func runAccountPurger(userService user.UserService, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := userService.PurgeScheduledDeletions(context.Background()); err != nil {
+			log.Printf("Failed to purge deleted accounts: %v", err)
+		}
+	}
+}
+
+func runReminderScheduler(notificationService notifications.NotificationService, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reminded, err := notificationService.SendDueReminders(context.Background())
+		if err != nil {
+			log.Printf("Failed to send study reminders: %v", err)
+		}
+		if reminded > 0 {
+			log.Printf("Sent study reminders to %d users", reminded)
+		}
+	}
+}
+
+func migrateWordSchema(wordsService words.WordService) {
+
+	migrated, err := wordsService.MigrateWordSchema(context.Background())
+	if err != nil {
+		log.Printf("Failed to migrate words to schema version %d: %v", words.CurrentSchemaVersion, err)
+		return
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d words to schema version %d", migrated, words.CurrentSchemaVersion)
+	}
+}
+
+func generateMissingCards(cardService cards.CardService) {
+
+	generated, err := cardService.GenerateMissingCards(context.Background())
+	if err != nil {
+		log.Printf("Failed to generate study cards: %v", err)
+		return
+	}
+
+	if generated > 0 {
+		log.Printf("Generated study cards for %d words", generated)
+	}
+}
+
+func importDictionary(dictionaryService dictionary.DictionaryService, path string) {
+
+	imported, err := dictionaryService.ImportFile(context.Background(), path)
+	if err != nil {
+		log.Printf("Failed to import dictionary: %v", err)
+		return
+	}
+
+	if imported > 0 {
+		log.Printf("Imported %d dictionary entries from %s", imported, path)
+	}
+}
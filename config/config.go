@@ -1,12 +1,94 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	Port string
 	MongoURI string
 	MongoDB string
 	Clouldinary string
+
+	// TrustedProxies are the proxies whose X-Forwarded-For header is used
+	// for the client IP. With none, the peer address is used.
+	TrustedProxies []string
+
+	AuthRateLimit     int
+	AuthRateWindow    time.Duration
+	AccountRateLimit  int
+	AccountRateWindow time.Duration
+	MaxLoginAttempts  int
+	LockoutDuration   time.Duration
+
+	PasswordHashAlgorithm  string
+	BcryptCost             int
+	Argon2Time             uint32
+	Argon2Memory           uint32
+	Argon2Threads          uint8
+	PasswordMinLength      int
+	PasswordRequireUpper   bool
+	PasswordRequireLower   bool
+	PasswordRequireDigit   bool
+	PasswordRequireSymbol  bool
+	PasswordBreachListPath string
+
+	AccountDeletionGracePeriod time.Duration
+	AccountPurgeInterval       time.Duration
+
+	OAuthProviders map[string]OAuthProviderConfig
+	OAuthStateTTL  time.Duration
+
+	TwoFactorIssuer       string
+	TwoFactorChallengeTTL time.Duration
+
+	BlobStorageDir      string
+	MaxAudioUploadBytes int64
+
+	DictionaryImportFile      string
+	DictionaryAPIURL          string
+	DictionaryDefaultLanguage string
+	DictionaryTimeout         time.Duration
+
+	TranslationProvider string
+	TranslationAPIURL   string
+	TranslationAPIKey   string
+	TranslationTimeout  time.Duration
+
+	TTSProvider     string
+	TTSBinary       string
+	TTSDefaultVoice string
+	TTSTimeout      time.Duration
+
+	AppURL              string
+	ReminderInterval    time.Duration
+	NotificationTimeout time.Duration
+	SMTPHost            string
+	SMTPPort            int
+	SMTPUsername        string
+	SMTPPassword        string
+	SMTPFrom            string
+	VAPIDPublicKey      string
+	VAPIDPrivateKey     string
+	VAPIDSubject        string
+	WebhookSecret       string
+}
+
+type OAuthProviderConfig struct {
+	Name         string
+	Type         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	IssuerURL    string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	EmailsURL    string
+	Scopes       []string
 }
 
 func LoadConfig() *Config {
@@ -14,9 +96,118 @@ func LoadConfig() *Config {
 		Port: getEnv("PORT", "8004"),
 		MongoURI: getEnv("MONGO_URI", "mongodb://localhost:27011"),
 		MongoDB: getEnv("MONGO_DB", "flash-cards"),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		AuthRateLimit:     getEnvInt("AUTH_RATE_LIMIT", 20),
+		AuthRateWindow:    getEnvDuration("AUTH_RATE_WINDOW", time.Minute),
+		AccountRateLimit:  getEnvInt("ACCOUNT_RATE_LIMIT", 5),
+		AccountRateWindow: getEnvDuration("ACCOUNT_RATE_WINDOW", time.Minute),
+		MaxLoginAttempts:  getEnvInt("MAX_LOGIN_ATTEMPTS", 5),
+		LockoutDuration:   getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+
+		PasswordHashAlgorithm:  getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		BcryptCost:             getEnvInt("BCRYPT_COST", 12),
+		Argon2Time:             uint32(getEnvInt("ARGON2_TIME", 3)),
+		Argon2Memory:           uint32(getEnvInt("ARGON2_MEMORY_KIB", 64*1024)),
+		Argon2Threads:          uint8(getEnvInt("ARGON2_THREADS", 2)),
+		PasswordMinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:   getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+		PasswordRequireLower:   getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+		PasswordRequireDigit:   getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol:  getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordBreachListPath: getEnv("PASSWORD_BREACH_LIST", ""),
+
+		AccountDeletionGracePeriod: getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 7*24*time.Hour),
+		AccountPurgeInterval:       getEnvDuration("ACCOUNT_PURGE_INTERVAL", time.Hour),
+
+		OAuthProviders: loadOAuthProviders(),
+		OAuthStateTTL:  getEnvDuration("OAUTH_STATE_TTL", 10*time.Minute),
+
+		TwoFactorIssuer:       getEnv("TWO_FACTOR_ISSUER", "Flashcard"),
+		TwoFactorChallengeTTL: getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+
+		BlobStorageDir:      getEnv("BLOB_STORAGE_DIR", "./data/blobs"),
+		MaxAudioUploadBytes: int64(getEnvInt("MAX_AUDIO_UPLOAD_BYTES", 5<<20)),
+
+		DictionaryImportFile:      getEnv("DICTIONARY_IMPORT_FILE", ""),
+		DictionaryAPIURL:          getEnv("DICTIONARY_API_URL", ""),
+		DictionaryDefaultLanguage: getEnv("DICTIONARY_DEFAULT_LANGUAGE", "en"),
+		DictionaryTimeout:         getEnvDuration("DICTIONARY_TIMEOUT", 5*time.Second),
+
+		TranslationProvider: getEnv("TRANSLATION_PROVIDER", ""),
+		TranslationAPIURL:   getEnv("TRANSLATION_API_URL", ""),
+		TranslationAPIKey:   getEnv("TRANSLATION_API_KEY", ""),
+		TranslationTimeout:  getEnvDuration("TRANSLATION_TIMEOUT", 10*time.Second),
+
+		TTSProvider:     getEnv("TTS_PROVIDER", ""),
+		TTSBinary:       getEnv("TTS_BINARY", "espeak-ng"),
+		TTSDefaultVoice: getEnv("TTS_DEFAULT_VOICE", ""),
+		TTSTimeout:      getEnvDuration("TTS_TIMEOUT", 10*time.Second),
+
+		AppURL:              getEnv("APP_URL", ""),
+		ReminderInterval:    getEnvDuration("REMINDER_INTERVAL", time.Minute),
+		NotificationTimeout: getEnvDuration("NOTIFICATION_TIMEOUT", 10*time.Second),
+		SMTPHost:            getEnv("SMTP_HOST", ""),
+		SMTPPort:            getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:        getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:            getEnv("SMTP_FROM", ""),
+		VAPIDPublicKey:      getEnv("VAPID_PUBLIC_KEY", ""),
+		VAPIDPrivateKey:     getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:        getEnv("VAPID_SUBJECT", ""),
+		WebhookSecret:       getEnv("WEBHOOK_SECRET", ""),
 	}
 }
 
+// loadOAuthProviders reads OAUTH_PROVIDERS (e.g. "google,github,mock") and the
+// OAUTH_<NAME>_* variables of each provider. Google and GitHub get their
+// well-known endpoints by default; any other name is treated as a generic
+// OpenID Connect provider and needs OAUTH_<NAME>_ISSUER_URL.
+func loadOAuthProviders() map[string]OAuthProviderConfig {
+
+	providers := make(map[string]OAuthProviderConfig)
+
+	for _, name := range strings.Split(getEnv("OAUTH_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		defaults := OAuthProviderConfig{Type: "oidc", Scopes: []string{"openid", "email", "profile"}}
+		switch name {
+		case "google":
+			defaults.IssuerURL = "https://accounts.google.com"
+		case "github":
+			defaults = OAuthProviderConfig{
+				Type:        "github",
+				AuthURL:     "https://github.com/login/oauth/authorize",
+				TokenURL:    "https://github.com/login/oauth/access_token",
+				UserInfoURL: "https://api.github.com/user",
+				EmailsURL:   "https://api.github.com/user/emails",
+				Scopes:      []string{"read:user", "user:email"},
+			}
+		}
+
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		providers[name] = OAuthProviderConfig{
+			Name:         name,
+			Type:         getEnv(prefix+"TYPE", defaults.Type),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			IssuerURL:    getEnv(prefix+"ISSUER_URL", defaults.IssuerURL),
+			AuthURL:      getEnv(prefix+"AUTH_URL", defaults.AuthURL),
+			TokenURL:     getEnv(prefix+"TOKEN_URL", defaults.TokenURL),
+			UserInfoURL:  getEnv(prefix+"USERINFO_URL", defaults.UserInfoURL),
+			EmailsURL:    getEnv(prefix+"EMAILS_URL", defaults.EmailsURL),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", strings.Join(defaults.Scopes, " "))),
+		}
+	}
+
+	return providers
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -24,3 +215,50 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrResourceNotFound   = errors.New("resource not found")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// ServiceErrorStatus maps the sentinel errors above (wrapped with %w by the
+// services) to their HTTP status and error code, falling back to 500 for
+// anything else.
+func ServiceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrPermissionDenied):
+		return http.StatusForbidden, ErrForbidden
+	case errors.Is(err, ErrResourceNotFound):
+		return http.StatusNotFound, ErrNotFound
+	case errors.Is(err, ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, ErrVersionMismatch
+	default:
+		return http.StatusInternalServerError, ErrInvalidOperation
+	}
+}
+
+func SendServiceError(c *gin.Context, err error) {
+	statusCode, errorCode := ServiceErrorStatus(err)
+	SendError(c, statusCode, err, errorCode)
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag formats a document version as a strong entity tag.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+func SetETag(c *gin.Context, version int64) {
+	c.Header("ETag", ETag(version))
+}
+
+// NotModified sets the ETag of the current version and answers 304 when the
+// client's If-None-Match already names it.
+func NotModified(c *gin.Context, version int64) bool {
+
+	SetETag(c, version)
+
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == ETag(version) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseIfMatch returns the version named by the If-Match header, or nil when
+// the header is absent or "*".
+func ParseIfMatch(c *gin.Context) (*int64, error) {
+
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return nil, fmt.Errorf("invalid If-Match header %q", header)
+	}
+
+	return &version, nil
+}
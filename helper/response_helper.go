@@ -1,12 +1,18 @@
 package helper
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	ErrInvalidOperation = "ERR_INVALID_OPERATION"
 	ErrInvalidRequest   = "ERR_INVALID_REQUEST"
+	ErrTooManyRequests  = "ERR_TOO_MANY_REQUESTS"
+	ErrForbidden        = "ERR_FORBIDDEN"
+	ErrNotFound         = "ERR_NOT_FOUND"
+	ErrVersionMismatch  = "ERR_VERSION_MISMATCH"
 )
 
 type APIResponse struct {
@@ -26,9 +32,13 @@ func SendSuccess(c *gin.Context, statusCode int, message string, data interface{
 }
 
 func SendError( c* gin.Context, statusCode int, err error, errorCode string) {
+	message := http.StatusText(statusCode)
+	if err != nil {
+		message = err.Error()
+	}
 	c.JSON(statusCode, APIResponse {
 		StatusCode: statusCode,
-		Error: err.Error(),
+		Error: message,
 		ErrorCode: errorCode,
 	})
 }
\ No newline at end of file
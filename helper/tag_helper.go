@@ -0,0 +1,23 @@
+package helper
+
+import "strings"
+
+// NormalizeTags lowercases and trims tags, dropping empty and duplicate ones.
+func NormalizeTags(tags []string) []string {
+
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
@@ -0,0 +1,71 @@
+package helper
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ChangedFields compares a document before and after an edit by their BSON
+// encoding and returns the top-level fields to $set and to $unset, so that
+// an update only touches what actually changed.
+func ChangedFields(before bson.Raw, after interface{}) (bson.M, bson.M, error) {
+
+	encoded, err := bson.Marshal(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterElements, err := bson.Raw(encoded).Elements()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set := bson.M{}
+	present := make(map[string]struct{}, len(afterElements))
+	for _, element := range afterElements {
+		key := element.Key()
+		present[key] = struct{}{}
+		if key == "_id" {
+			continue
+		}
+		previous, err := before.LookupErr(key)
+		if err != nil || !previous.Equal(element.Value()) {
+			set[key] = element.Value()
+		}
+	}
+
+	beforeElements, err := before.Elements()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unset := bson.M{}
+	for _, element := range beforeElements {
+		if _, ok := present[element.Key()]; !ok {
+			unset[element.Key()] = ""
+		}
+	}
+
+	return set, unset, nil
+}
+
+// VersionFilter matches a document by ID and version. Documents written
+// before versioning have no version field and match version 0.
+func VersionFilter(id interface{}, version int64) bson.M {
+
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$in": bson.A{int64(0), nil}}}
+	}
+
+	return bson.M{"_id": id, "version": version}
+}
+
+// VersionedUpdate builds an update from ChangedFields output.
+func VersionedUpdate(set, unset bson.M) bson.M {
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return update
+}
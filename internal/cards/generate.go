@@ -0,0 +1,153 @@
+package cards
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"flashcard/internal/words"
+)
+
+const (
+	clozeGap      = "[...]"
+	maxClozeCards = 5
+)
+
+// termSpaces splits a multi-word term, so that its words match across any
+// run of whitespace in a sentence.
+var termSpaces = regexp.MustCompile(`\s+`)
+
+// generateCards derives the prompts a word should have: a forward card
+// always, a reverse card when it has a definition and a cloze card for each
+// example sentence that contains the word.
+func generateCards(word *words.Word) []*Card {
+
+	term := strings.TrimSpace(word.Word)
+	definition := strings.TrimSpace(word.Definition)
+
+	generated := []*Card{{
+		Kind:  KindForward,
+		Front: term,
+		Back:  definition,
+	}}
+
+	if definition != "" {
+		generated = append(generated, &Card{
+			Kind:  KindReverse,
+			Front: definition,
+			Back:  term,
+		})
+	}
+
+	matcher := newClozeMatcher(term)
+	for i, sentence := range clozeSentences(word, matcher) {
+		blanked, _ := matcher.blank(sentence)
+		generated = append(generated, &Card{
+			Kind:    KindCloze,
+			Ordinal: i,
+			Front:   blanked,
+			Back:    sentence,
+		})
+	}
+
+	return generated
+}
+
+// clozeSentences collects the distinct example sentences that mention the
+// word, primary example first.
+func clozeSentences(word *words.Word, matcher *clozeMatcher) []string {
+
+	if matcher == nil {
+		return nil
+	}
+
+	var examples []string
+	if word.Example != nil {
+		examples = append(examples, *word.Example)
+	}
+	for _, sense := range word.Senses {
+		examples = append(examples, sense.Examples...)
+	}
+
+	seen := make(map[string]struct{}, len(examples))
+	var sentences []string
+	for _, example := range examples {
+		example = strings.TrimSpace(example)
+		if example == "" {
+			continue
+		}
+		if _, ok := matcher.blank(example); !ok {
+			continue
+		}
+		if _, ok := seen[example]; ok {
+			continue
+		}
+		seen[example] = struct{}{}
+		sentences = append(sentences, example)
+		if len(sentences) == maxClozeCards {
+			break
+		}
+	}
+
+	return sentences
+}
+
+// clozeMatcher finds a term in sentences as a whole word, ignoring case.
+type clozeMatcher struct {
+	pattern *regexp.Regexp
+}
+
+// newClozeMatcher compiles the pattern for a term once, for all of a word's
+// sentences. It returns nil for an empty term.
+func newClozeMatcher(term string) *clozeMatcher {
+
+	if term == "" {
+		return nil
+	}
+
+	parts := termSpaces.Split(term, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return &clozeMatcher{pattern: regexp.MustCompile("(?i)" + strings.Join(parts, `\s+`))}
+}
+
+// blank replaces every whole-word occurrence of the term in sentence with a
+// gap and reports whether there was any. Occurrences inside a longer word,
+// such as "cat" in "concatenate", are left alone.
+func (m *clozeMatcher) blank(sentence string) (string, bool) {
+
+	var b strings.Builder
+	last, found := 0, false
+	for _, loc := range m.pattern.FindAllStringIndex(sentence, -1) {
+		if !wordBoundary(sentence, loc[0]) || !wordBoundary(sentence, loc[1]) {
+			continue
+		}
+		b.WriteString(sentence[last:loc[0]])
+		b.WriteString(clozeGap)
+		last, found = loc[1], true
+	}
+
+	if !found {
+		return sentence, false
+	}
+
+	b.WriteString(sentence[last:])
+	return b.String(), true
+}
+
+// wordBoundary reports whether position i of s is not inside a word, with
+// letters, digits and combining marks of any script counted as word runes.
+func wordBoundary(s string, i int) bool {
+
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i:])
+
+	return !isWordRune(before) || !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -0,0 +1,33 @@
+package cards
+
+import "testing"
+
+func TestClozeMatcherBlank(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		term     string
+		sentence string
+		want     string
+		wantOK   bool
+	}{
+		{"whole word", "cat", "The cat sat.", "The [...] sat.", true},
+		{"ignores case", "cat", "Cat and CAT.", "[...] and [...].", true},
+		{"skips substrings", "cat", "Concatenate the category.", "Concatenate the category.", false},
+		{"keeps the real match next to a substring", "cat", "A category for the cat", "A category for the [...]", true},
+		{"accented letters are part of words", "café", "Un cafés et un café.", "Un cafés et un [...].", true},
+		{"non-latin scripts", "кот", "Это котёнок, а это кот.", "Это котёнок, а это [...].", true},
+		{"regexp characters are literal", "c++", "I write c++ and cxx.", "I write [...] and cxx.", true},
+		{"multi-word terms match across whitespace", "give up", "Never give\tup, never give upward.", "Never [...], never give upward.", true},
+		{"punctuation is a boundary", "end", "(end)", "([...])", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := newClozeMatcher(tc.term).blank(tc.sentence)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("blank(%q) = %q, %v; want %q, %v", tc.sentence, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
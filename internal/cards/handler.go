@@ -0,0 +1,79 @@
+package cards
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CardHandler struct {
+	CardService CardService
+}
+
+func NewCardHandler(cardService CardService) *CardHandler {
+	return &CardHandler{CardService: cardService}
+}
+
+func (h *CardHandler) GetWordCards(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	cards, err := h.CardService.GetWordCards(c, c.Param("word_id"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", cards)
+}
+
+func (h *CardHandler) GetDueCards(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req DueCardsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	cards, err := h.CardService.GetDueCards(c, &req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", cards)
+}
+
+func (h *CardHandler) ReviewCard(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req ReviewCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	card, err := h.CardService.ReviewCard(c, c.Param("card_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", card)
+}
@@ -0,0 +1,110 @@
+package cards
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	KindForward = "forward"
+	KindReverse = "reverse"
+	KindCloze   = "cloze"
+)
+
+// Card is one study prompt derived from a word. Siblings share a word and
+// are told apart by kind and, for cloze cards, by ordinal; each keeps its
+// own schedule.
+type Card struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	WordID         primitive.ObjectID `json:"word_id" bson:"word_id"`
+	TopicID        primitive.ObjectID `json:"topic_id" bson:"topic_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Kind           string             `json:"kind" bson:"kind"`
+	Ordinal        int                `json:"ordinal" bson:"ordinal"`
+	Front          string             `json:"front" bson:"front"`
+	Back           string             `json:"back" bson:"back"`
+	EaseFactor     float64            `json:"ease_factor" bson:"ease_factor"`
+	IntervalDays   int                `json:"interval_days" bson:"interval_days"`
+	Repetitions    int                `json:"repetitions" bson:"repetitions"`
+	Lapses         int                `json:"lapses" bson:"lapses"`
+	DueAt          time.Time          `json:"due_at" bson:"due_at"`
+	BuriedUntil    *time.Time         `json:"buried_until,omitempty" bson:"buried_until,omitempty"`
+	LastReviewedAt *time.Time         `json:"last_reviewed_at,omitempty" bson:"last_reviewed_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CardSchedule is one user's SM-2 schedule of a card, so that everyone who
+// can see a shared topic studies its cards on their own. Cards carry the
+// same fields: stored, they hold the schedule kept before schedules were per
+// user, which belongs to the card's creator; returned, they hold the
+// schedule of the user asking.
+type CardSchedule struct {
+	ID             string             `bson:"_id"`
+	CardID         primitive.ObjectID `bson:"card_id"`
+	WordID         primitive.ObjectID `bson:"word_id"`
+	UserID         primitive.ObjectID `bson:"user_id"`
+	EaseFactor     float64            `bson:"ease_factor"`
+	IntervalDays   int                `bson:"interval_days"`
+	Repetitions    int                `bson:"repetitions"`
+	Lapses         int                `bson:"lapses"`
+	DueAt          time.Time          `bson:"due_at"`
+	BuriedUntil    *time.Time         `bson:"buried_until,omitempty"`
+	LastReviewedAt *time.Time         `bson:"last_reviewed_at,omitempty"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+func scheduleID(cardID, userID primitive.ObjectID) string {
+	return cardID.Hex() + ":" + userID.Hex()
+}
+
+// newSchedule is the schedule of a card nobody has studied, due at dueAt.
+func (card *Card) newSchedule(userID primitive.ObjectID, dueAt time.Time) *CardSchedule {
+	return &CardSchedule{
+		ID:         scheduleID(card.ID, userID),
+		CardID:     card.ID,
+		WordID:     card.WordID,
+		UserID:     userID,
+		EaseFactor: defaultEaseFactor,
+		DueAt:      dueAt,
+	}
+}
+
+// startingSchedule is a user's schedule of the card before they have one of
+// their own: what the card stores for its creator, and a new schedule, due
+// since the card was created, for anyone else.
+func (card *Card) startingSchedule(userID primitive.ObjectID) *CardSchedule {
+
+	schedule := card.newSchedule(userID, card.CreatedAt)
+	if userID == card.UserID {
+		schedule.EaseFactor = card.EaseFactor
+		schedule.IntervalDays = card.IntervalDays
+		schedule.Repetitions = card.Repetitions
+		schedule.Lapses = card.Lapses
+		schedule.DueAt = card.DueAt
+		schedule.BuriedUntil = card.BuriedUntil
+		schedule.LastReviewedAt = card.LastReviewedAt
+	}
+
+	return schedule
+}
+
+func (card *Card) applySchedule(schedule *CardSchedule) {
+	card.EaseFactor = schedule.EaseFactor
+	card.IntervalDays = schedule.IntervalDays
+	card.Repetitions = schedule.Repetitions
+	card.Lapses = schedule.Lapses
+	card.DueAt = schedule.DueAt
+	card.BuriedUntil = schedule.BuriedUntil
+	card.LastReviewedAt = schedule.LastReviewedAt
+}
+
+func (card *Card) key() cardKey {
+	return cardKey{kind: card.Kind, ordinal: card.Ordinal}
+}
+
+type cardKey struct {
+	kind    string
+	ordinal int
+}
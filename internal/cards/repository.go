@@ -0,0 +1,232 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CardRepository interface {
+	CreateCards(c context.Context, cards []*Card) error
+	GetCardByID(c context.Context, id primitive.ObjectID) (*Card, error)
+	GetCardsByWordID(c context.Context, wordID primitive.ObjectID) ([]*Card, error)
+	GetCardsByWordIDs(c context.Context, wordIDs []primitive.ObjectID) ([]*Card, error)
+	GetCardsByTopicIDs(c context.Context, topicIDs []primitive.ObjectID) ([]*Card, error)
+	GetLastReviewedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error)
+	GetCardWordIDs(c context.Context) ([]primitive.ObjectID, error)
+	UpdateCardContent(c context.Context, card *Card) error
+	ResetSchedules(c context.Context, wordIDs []primitive.ObjectID, now time.Time) error
+	DeleteCards(c context.Context, ids []primitive.ObjectID) error
+	DeleteCardsByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error
+	DeleteCardsByTopicID(c context.Context, topicID primitive.ObjectID) error
+	DeleteCardsByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type cardRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCardRepository(collection *mongo.Collection) CardRepository {
+	return &cardRepository{collection: collection}
+}
+
+func (r *cardRepository) CreateCards(c context.Context, cards []*Card) error {
+
+	if len(cards) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(cards))
+	for _, card := range cards {
+		documents = append(documents, card)
+	}
+
+	_, err := r.collection.InsertMany(c, documents)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cardRepository) GetCardByID(c context.Context, id primitive.ObjectID) (*Card, error) {
+
+	var card Card
+
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&card)
+	if err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+
+}
+
+func (r *cardRepository) GetCardsByWordID(c context.Context, wordID primitive.ObjectID) ([]*Card, error) {
+	return r.find(c, bson.M{"word_id": wordID}, options.Find().SetSort(bson.D{{Key: "kind", Value: 1}, {Key: "ordinal", Value: 1}}))
+}
+
+func (r *cardRepository) GetCardsByWordIDs(c context.Context, wordIDs []primitive.ObjectID) ([]*Card, error) {
+
+	if len(wordIDs) == 0 {
+		return nil, nil
+	}
+
+	return r.find(c, bson.M{"word_id": bson.M{"$in": wordIDs}}, nil)
+}
+
+func (r *cardRepository) GetCardsByTopicIDs(c context.Context, topicIDs []primitive.ObjectID) ([]*Card, error) {
+
+	if len(topicIDs) == 0 {
+		return nil, nil
+	}
+
+	return r.find(c, bson.M{"topic_id": bson.M{"$in": topicIDs}}, nil)
+}
+
+// GetLastReviewedAt returns when any of the user's cards was last reviewed
+// according to the schedules stored on the cards, or nil if none has been.
+func (r *cardRepository) GetLastReviewedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error) {
+
+	filter := bson.M{"user_id": userID, "last_reviewed_at": bson.M{"$exists": true}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "last_reviewed_at", Value: -1}})
+
+	var card Card
+	err := r.collection.FindOne(c, filter, opts).Decode(&card)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return card.LastReviewedAt, nil
+}
+
+func (r *cardRepository) GetCardWordIDs(c context.Context) ([]primitive.ObjectID, error) {
+
+	values, err := r.collection.Distinct(c, "word_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *cardRepository) UpdateCardContent(c context.Context, card *Card) error {
+
+	update := bson.M{"$set": bson.M{
+		"topic_id":   card.TopicID,
+		"user_id":    card.UserID,
+		"front":      card.Front,
+		"back":       card.Back,
+		"updated_at": card.UpdatedAt,
+	}}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": card.ID}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ResetSchedules puts the schedule stored on every card of the given words
+// back to the state of a newly created card, due now.
+func (r *cardRepository) ResetSchedules(c context.Context, wordIDs []primitive.ObjectID, now time.Time) error {
+
+	if len(wordIDs) == 0 {
+		return nil
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"ease_factor":   defaultEaseFactor,
+			"interval_days": 0,
+			"repetitions":   0,
+			"lapses":        0,
+			"due_at":        now,
+			"updated_at":    now,
+		},
+		"$unset": bson.M{"buried_until": "", "last_reviewed_at": ""},
+	}
+
+	_, err := r.collection.UpdateMany(c, bson.M{"word_id": bson.M{"$in": wordIDs}}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cardRepository) DeleteCards(c context.Context, ids []primitive.ObjectID) error {
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(c, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cardRepository) DeleteCardsByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error {
+
+	if len(wordIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(c, bson.M{"word_id": bson.M{"$in": wordIDs}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cardRepository) DeleteCardsByTopicID(c context.Context, topicID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"topic_id": topicID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cardRepository) DeleteCardsByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cardRepository) find(c context.Context, filter bson.M, opts *options.FindOptions) ([]*Card, error) {
+
+	var cards []*Card
+
+	cursor, err := r.collection.Find(c, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var card Card
+		if err := cursor.Decode(&card); err != nil {
+			return nil, err
+		}
+		cards = append(cards, &card)
+	}
+
+	return cards, nil
+}
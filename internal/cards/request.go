@@ -0,0 +1,10 @@
+package cards
+
+type DueCardsRequest struct {
+	TopicID string `form:"topic_id" json:"topic_id"`
+	Limit   int    `form:"limit" json:"limit"`
+}
+
+type ReviewCardRequest struct {
+	Quality *int `json:"quality" bson:"quality"`
+}
@@ -0,0 +1,18 @@
+package cards
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *CardHandler) {
+
+	cardGroup := r.Group("/api/v1/card")
+	{
+		cardGroup.GET("/due", middleware.JWTAuthMiddleware(), handler.GetDueCards)
+		cardGroup.GET("/word/:word_id", middleware.JWTAuthMiddleware(), handler.GetWordCards)
+		cardGroup.POST("/:card_id/review", middleware.JWTAuthMiddleware(), handler.ReviewCard)
+	}
+
+}
@@ -0,0 +1,125 @@
+package cards
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+	passingQuality    = 3
+	maxQuality        = 5
+
+	// knownRepetitions and knownIntervalDays are where markKnown puts a card:
+	// two correct answers in, with the interval SM-2 gives the second.
+	knownRepetitions  = 2
+	knownIntervalDays = 6
+)
+
+// review applies the SM-2 algorithm for an answer graded from 0 (blackout)
+// to 5 (perfect). Failed cards restart their repetitions and come back the
+// next day.
+func (schedule *CardSchedule) review(quality int, now time.Time) {
+
+	if quality < passingQuality {
+		schedule.Repetitions = 0
+		schedule.IntervalDays = 1
+		schedule.Lapses++
+	} else {
+		switch schedule.Repetitions {
+		case 0:
+			schedule.IntervalDays = 1
+		case 1:
+			schedule.IntervalDays = 6
+		default:
+			schedule.IntervalDays = int(math.Round(float64(schedule.IntervalDays) * schedule.EaseFactor))
+		}
+		schedule.Repetitions++
+	}
+
+	miss := float64(maxQuality - quality)
+	schedule.EaseFactor += 0.1 - miss*(0.08+miss*0.02)
+	if schedule.EaseFactor < minEaseFactor {
+		schedule.EaseFactor = minEaseFactor
+	}
+
+	schedule.DueAt = now.AddDate(0, 0, schedule.IntervalDays)
+	schedule.BuriedUntil = nil
+	schedule.LastReviewedAt = &now
+	schedule.UpdatedAt = now
+}
+
+// markKnown moves a card the user says they know past SM-2's learning steps,
+// as if it had been answered correctly twice, so it next comes up after the
+// second interval. Cards already past those steps keep their schedule; it
+// reports whether the schedule changed.
+func (schedule *CardSchedule) markKnown(now time.Time) bool {
+
+	if schedule.Repetitions >= knownRepetitions {
+		return false
+	}
+
+	schedule.Repetitions = knownRepetitions
+	schedule.IntervalDays = knownIntervalDays
+	schedule.DueAt = now.AddDate(0, 0, knownIntervalDays)
+	schedule.BuriedUntil = nil
+	schedule.UpdatedAt = now
+
+	return true
+}
+
+// due reports whether the card is due at now and not buried.
+func (schedule *CardSchedule) due(now time.Time) bool {
+	return !schedule.DueAt.After(now) && (schedule.BuriedUntil == nil || !schedule.BuriedUntil.After(now))
+}
+
+// burySiblings hides the siblings of a reviewed card that would come up
+// before until, and returns the schedules it changed.
+func burySiblings(schedules []*CardSchedule, reviewedID primitive.ObjectID, until time.Time, now time.Time) []*CardSchedule {
+
+	var buried []*CardSchedule
+	for _, schedule := range schedules {
+		if schedule.CardID == reviewedID || !schedule.DueAt.Before(until) {
+			continue
+		}
+		buriedUntil := until
+		schedule.BuriedUntil = &buriedUntil
+		schedule.UpdatedAt = now
+		buried = append(buried, schedule)
+	}
+
+	return buried
+}
+
+// dueCards returns the cards due at now, most overdue first, at most limit
+// of them.
+func dueCards(cards []*Card, schedules map[primitive.ObjectID]*CardSchedule, now time.Time, limit int) []*Card {
+
+	due := make([]*Card, 0)
+	for _, card := range cards {
+		if schedules[card.ID].due(now) {
+			due = append(due, card)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return schedules[due[i].ID].DueAt.Before(schedules[due[j].ID].DueAt)
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due
+}
+
+// nextDay is the start of the UTC day after now; siblings buried by a
+// review become available again then.
+func nextDay(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
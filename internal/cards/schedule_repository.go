@@ -0,0 +1,131 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScheduleRepository stores each user's schedules of cards. Documents are
+// keyed by card and user, so a user has at most one per card.
+type ScheduleRepository interface {
+	GetSchedules(c context.Context, userID primitive.ObjectID, cardIDs []primitive.ObjectID) ([]*CardSchedule, error)
+	GetLastReviewedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error)
+	SaveSchedules(c context.Context, schedules []*CardSchedule) error
+	DeleteSchedulesByCardIDs(c context.Context, cardIDs []primitive.ObjectID) error
+	DeleteSchedulesByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error
+	DeleteSchedulesByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type scheduleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewScheduleRepository(collection *mongo.Collection) ScheduleRepository {
+	return &scheduleRepository{collection: collection}
+}
+
+func (r *scheduleRepository) GetSchedules(c context.Context, userID primitive.ObjectID, cardIDs []primitive.ObjectID) ([]*CardSchedule, error) {
+
+	if len(cardIDs) == 0 {
+		return nil, nil
+	}
+
+	var schedules []*CardSchedule
+
+	cursor, err := r.collection.Find(c, bson.M{"user_id": userID, "card_id": bson.M{"$in": cardIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var schedule CardSchedule
+		if err := cursor.Decode(&schedule); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, &schedule)
+	}
+
+	return schedules, nil
+}
+
+// GetLastReviewedAt returns when the user last reviewed any card, or nil if
+// they never have.
+func (r *scheduleRepository) GetLastReviewedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error) {
+
+	filter := bson.M{"user_id": userID, "last_reviewed_at": bson.M{"$exists": true}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "last_reviewed_at", Value: -1}})
+
+	var schedule CardSchedule
+	err := r.collection.FindOne(c, filter, opts).Decode(&schedule)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule.LastReviewedAt, nil
+}
+
+// SaveSchedules replaces the given schedules, creating missing ones.
+func (r *scheduleRepository) SaveSchedules(c context.Context, schedules []*CardSchedule) error {
+
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(schedules))
+	for _, schedule := range schedules {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": schedule.ID}).
+			SetReplacement(schedule).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(c, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *scheduleRepository) DeleteSchedulesByCardIDs(c context.Context, cardIDs []primitive.ObjectID) error {
+
+	if len(cardIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(c, bson.M{"card_id": bson.M{"$in": cardIDs}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *scheduleRepository) DeleteSchedulesByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error {
+
+	if len(wordIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(c, bson.M{"word_id": bson.M{"$in": wordIDs}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *scheduleRepository) DeleteSchedulesByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
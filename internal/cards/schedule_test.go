@@ -0,0 +1,209 @@
+package cards
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMarkKnown(t *testing.T) {
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	buried := now.Add(time.Hour)
+
+	cases := []struct {
+		name        string
+		schedule    CardSchedule
+		wantChanged bool
+		wantReps    int
+		wantDueAt   time.Time
+	}{
+		{
+			name:        "a new card skips the learning steps",
+			schedule:    CardSchedule{EaseFactor: defaultEaseFactor, DueAt: now},
+			wantChanged: true,
+			wantReps:    knownRepetitions,
+			wantDueAt:   now.AddDate(0, 0, knownIntervalDays),
+		},
+		{
+			name:        "a buried card in its first step is unburied",
+			schedule:    CardSchedule{EaseFactor: defaultEaseFactor, Repetitions: 1, IntervalDays: 1, DueAt: now, BuriedUntil: &buried},
+			wantChanged: true,
+			wantReps:    knownRepetitions,
+			wantDueAt:   now.AddDate(0, 0, knownIntervalDays),
+		},
+		{
+			name:      "a learned card keeps its schedule",
+			schedule:  CardSchedule{EaseFactor: defaultEaseFactor, Repetitions: 4, IntervalDays: 40, DueAt: now.AddDate(0, 0, 20)},
+			wantReps:  4,
+			wantDueAt: now.AddDate(0, 0, 20),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			schedule := tc.schedule
+			schedule.CardID = primitive.NewObjectID()
+
+			if changed := schedule.markKnown(now); changed != tc.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tc.wantChanged)
+			}
+			if schedule.Repetitions != tc.wantReps {
+				t.Errorf("repetitions = %d, want %d", schedule.Repetitions, tc.wantReps)
+			}
+			if !schedule.DueAt.Equal(tc.wantDueAt) {
+				t.Errorf("due at %v, want %v", schedule.DueAt, tc.wantDueAt)
+			}
+			if tc.wantChanged && schedule.BuriedUntil != nil {
+				t.Error("card is still buried")
+			}
+		})
+	}
+}
+
+func TestReview(t *testing.T) {
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name         string
+		schedule     CardSchedule
+		quality      int
+		wantReps     int
+		wantInterval int
+		wantLapses   int
+		wantEase     float64
+	}{
+		{"first pass", CardSchedule{EaseFactor: 2.5}, 4, 1, 1, 0, 2.5},
+		{"second pass", CardSchedule{EaseFactor: 2.5, Repetitions: 1, IntervalDays: 1}, 5, 2, 6, 0, 2.6},
+		{"later passes grow by the ease factor", CardSchedule{EaseFactor: 2.5, Repetitions: 2, IntervalDays: 6}, 4, 3, 15, 0, 2.5},
+		{"a failure restarts the card", CardSchedule{EaseFactor: 2.5, Repetitions: 3, IntervalDays: 15}, 2, 0, 1, 1, 2.18},
+		{"ease never drops below the minimum", CardSchedule{EaseFactor: 1.3, Repetitions: 3, IntervalDays: 15}, 0, 0, 1, 1, minEaseFactor},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			buried := now.Add(time.Hour)
+			schedule := tc.schedule
+			schedule.BuriedUntil = &buried
+			schedule.review(tc.quality, now)
+
+			if schedule.Repetitions != tc.wantReps || schedule.IntervalDays != tc.wantInterval || schedule.Lapses != tc.wantLapses {
+				t.Errorf("got repetitions %d, interval %d, lapses %d; want %d, %d, %d",
+					schedule.Repetitions, schedule.IntervalDays, schedule.Lapses, tc.wantReps, tc.wantInterval, tc.wantLapses)
+			}
+			if diff := schedule.EaseFactor - tc.wantEase; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("ease factor = %v, want %v", schedule.EaseFactor, tc.wantEase)
+			}
+			if !schedule.DueAt.Equal(now.AddDate(0, 0, tc.wantInterval)) {
+				t.Errorf("due at %v, want in %d days", schedule.DueAt, tc.wantInterval)
+			}
+			if schedule.BuriedUntil != nil {
+				t.Error("a reviewed card stays buried")
+			}
+			if schedule.LastReviewedAt == nil || !schedule.LastReviewedAt.Equal(now) {
+				t.Errorf("last reviewed at %v, want %v", schedule.LastReviewedAt, now)
+			}
+		})
+	}
+}
+
+func TestBurySiblings(t *testing.T) {
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	until := nextDay(now)
+	reviewedID := primitive.NewObjectID()
+
+	cases := []struct {
+		name       string
+		dueAt      time.Time
+		reviewed   bool
+		wantBuried bool
+	}{
+		{name: "a sibling due now is buried", dueAt: now, wantBuried: true},
+		{name: "an overdue sibling is buried", dueAt: now.AddDate(0, 0, -3), wantBuried: true},
+		{name: "a sibling due later today is buried", dueAt: until.Add(-time.Minute), wantBuried: true},
+		{name: "a sibling due tomorrow is left alone", dueAt: until},
+		{name: "a sibling due next week is left alone", dueAt: now.AddDate(0, 0, 7)},
+		{name: "the reviewed card itself is not buried", dueAt: now, reviewed: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			schedule := &CardSchedule{CardID: primitive.NewObjectID(), DueAt: tc.dueAt}
+			if tc.reviewed {
+				schedule.CardID = reviewedID
+			}
+
+			buried := burySiblings([]*CardSchedule{schedule}, reviewedID, until, now)
+
+			if got := len(buried) == 1; got != tc.wantBuried {
+				t.Fatalf("buried = %v, want %v", got, tc.wantBuried)
+			}
+			if tc.wantBuried {
+				if schedule.BuriedUntil == nil || !schedule.BuriedUntil.Equal(until) {
+					t.Errorf("buried until %v, want %v", schedule.BuriedUntil, until)
+				}
+				if schedule.due(now) {
+					t.Error("a buried card is still due")
+				}
+				if !schedule.due(until) {
+					t.Error("a buried card is not due again the next day")
+				}
+			} else if schedule.BuriedUntil != nil {
+				t.Errorf("buried until %v, want not buried", schedule.BuriedUntil)
+			}
+		})
+	}
+}
+
+func TestDueCards(t *testing.T) {
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	overdue := &Card{ID: primitive.NewObjectID()}
+	dueNow := &Card{ID: primitive.NewObjectID()}
+	buried := &Card{ID: primitive.NewObjectID()}
+	unburied := &Card{ID: primitive.NewObjectID()}
+	future := &Card{ID: primitive.NewObjectID()}
+	earlier := now.Add(-time.Hour)
+
+	schedules := map[primitive.ObjectID]*CardSchedule{
+		overdue.ID:  {DueAt: now.AddDate(0, 0, -2)},
+		dueNow.ID:   {DueAt: now},
+		buried.ID:   {DueAt: now.AddDate(0, 0, -1), BuriedUntil: &later},
+		unburied.ID: {DueAt: now.AddDate(0, 0, -1), BuriedUntil: &earlier},
+		future.ID:   {DueAt: later},
+	}
+	all := []*Card{future, dueNow, buried, unburied, overdue}
+
+	cases := []struct {
+		name  string
+		limit int
+		want  []*Card
+	}{
+		{name: "due cards, most overdue first", want: []*Card{overdue, unburied, dueNow}},
+		{name: "limited", limit: 2, want: []*Card{overdue, unburied}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			got := dueCards(all, schedules, now, tc.limit)
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d cards, want %d", len(got), len(tc.want))
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("card %d is %s, want %s", i, got[i].ID.Hex(), tc.want[i].ID.Hex())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,567 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"flashcard/helper"
+	"flashcard/internal/words"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CardService interface {
+	GetWordCards(c context.Context, wordID string, userID string) ([]*Card, error)
+	GetDueCards(c context.Context, req *DueCardsRequest, userID string) ([]*Card, error)
+	CountDueCards(c context.Context, userID primitive.ObjectID, now time.Time) (int64, error)
+	LastStudiedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error)
+	ReviewCard(c context.Context, id string, userID string, req *ReviewCardRequest) (*Card, error)
+	SyncWordCards(c context.Context, words ...*words.Word) error
+	DeleteWordCards(c context.Context, wordIDs ...primitive.ObjectID) error
+	ResetWordCards(c context.Context, userID primitive.ObjectID, wordIDs ...primitive.ObjectID) error
+	MarkWordCardsKnown(c context.Context, userID primitive.ObjectID, wordIDs ...primitive.ObjectID) error
+	CopyWordCards(c context.Context, userID primitive.ObjectID, copies map[primitive.ObjectID]*words.Word) error
+	DeleteTopicCards(c context.Context, topicID primitive.ObjectID) error
+	GenerateMissingCards(c context.Context) (int, error)
+	DeleteUserData(c context.Context, userID string) error
+}
+
+// WordStore is the part of the word repository cards read from.
+type WordStore interface {
+	GetAllWords(c context.Context, req *words.SearchWordRequest) ([]*words.Word, error)
+	GetWordByID(c context.Context, id primitive.ObjectID) (*words.Word, error)
+}
+
+// ReviewLog records word-level review totals for the user who studied a
+// card. It is implemented by the word progress repository.
+type ReviewLog interface {
+	RecordReview(c context.Context, word *words.Word, userID primitive.ObjectID, correct bool, reviewedAt time.Time) error
+}
+
+type cardService struct {
+	cardRepository     CardRepository
+	scheduleRepository ScheduleRepository
+	wordStore          WordStore
+	reviewLog          ReviewLog
+	topicAccess        words.TopicAccess
+}
+
+func NewCardService(cardRepository CardRepository, scheduleRepository ScheduleRepository, wordStore WordStore, reviewLog ReviewLog, topicAccess words.TopicAccess) CardService {
+	return &cardService{
+		cardRepository:     cardRepository,
+		scheduleRepository: scheduleRepository,
+		wordStore:          wordStore,
+		reviewLog:          reviewLog,
+		topicAccess:        topicAccess,
+	}
+}
+
+func (s *cardService) GetWordCards(c context.Context, wordID string, userID string) ([]*Card, error) {
+
+	if wordID == "" {
+		return nil, fmt.Errorf("word id is required")
+	}
+
+	objectWordID, err := primitive.ObjectIDFromHex(wordID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	word, err := s.wordStore.GetWordByID(c, objectWordID)
+	if err != nil {
+		return nil, err
+	}
+
+	if word == nil {
+		return nil, fmt.Errorf("%w: word not found", helper.ErrResourceNotFound)
+	}
+
+	if err := s.topicAccess.CanViewTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	wordCards, err := s.cardRepository.GetCardsByWordID(c, word.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withSchedules(c, objectUserID, wordCards); err != nil {
+		return nil, err
+	}
+
+	return wordCards, nil
+}
+
+func (s *cardService) GetDueCards(c context.Context, req *DueCardsRequest, userID string) ([]*Card, error) {
+
+	if req.TopicID == "" {
+		return nil, fmt.Errorf("topic id is required")
+	}
+
+	topicID, err := primitive.ObjectIDFromHex(req.TopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanViewTopic(c, topicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	topicCards, err := s.cardRepository.GetCardsByTopicIDs(c, []primitive.ObjectID{topicID})
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.schedulesOf(c, objectUserID, topicCards)
+	if err != nil {
+		return nil, err
+	}
+
+	due := dueCards(topicCards, schedules, time.Now(), req.Limit)
+	for _, card := range due {
+		card.applySchedule(schedules[card.ID])
+	}
+
+	return due, nil
+}
+
+// CountDueCards counts the cards of the topics the user can view that are
+// due for them at now and not buried.
+func (s *cardService) CountDueCards(c context.Context, userID primitive.ObjectID, now time.Time) (int64, error) {
+
+	topicIDs, err := s.topicAccess.AccessibleTopicIDs(c, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	topicCards, err := s.cardRepository.GetCardsByTopicIDs(c, topicIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	schedules, err := s.schedulesOf(c, userID, topicCards)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(dueCards(topicCards, schedules, now, 0))), nil
+}
+
+// LastStudiedAt returns the last time the user reviewed a card, or nil if
+// they never have.
+func (s *cardService) LastStudiedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error) {
+
+	studied, err := s.scheduleRepository.GetLastReviewedAt(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy, err := s.cardRepository.GetLastReviewedAt(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if studied == nil || (legacy != nil && legacy.After(*studied)) {
+		return legacy, nil
+	}
+
+	return studied, nil
+}
+
+// ReviewCard reschedules a card for the user and buries its siblings for the
+// rest of the day, so the same word is not asked again from another side
+// straight away. Anyone who can view the topic can study it; the card itself
+// is not changed.
+func (s *cardService) ReviewCard(c context.Context, id string, userID string, req *ReviewCardRequest) (*Card, error) {
+
+	if req.Quality == nil || *req.Quality < 0 || *req.Quality > maxQuality {
+		return nil, fmt.Errorf("quality must be between 0 and %d", maxQuality)
+	}
+
+	if id == "" {
+		return nil, fmt.Errorf("card id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.cardRepository.GetCardByID(c, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: card not found", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanViewTopic(c, card.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	word, err := s.wordStore.GetWordByID(c, card.WordID)
+	if err != nil {
+		return nil, err
+	}
+	if word == nil {
+		return nil, fmt.Errorf("%w: word not found", helper.ErrResourceNotFound)
+	}
+
+	siblings, err := s.cardRepository.GetCardsByWordID(c, card.WordID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.schedulesOf(c, objectUserID, siblings)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, ok := schedules[card.ID]
+	if !ok {
+		schedule = card.startingSchedule(objectUserID)
+	}
+
+	now := time.Now()
+	schedule.review(*req.Quality, now)
+
+	siblingSchedules := make([]*CardSchedule, 0, len(schedules))
+	for _, sibling := range schedules {
+		siblingSchedules = append(siblingSchedules, sibling)
+	}
+	changed := append(burySiblings(siblingSchedules, card.ID, nextDay(now), now), schedule)
+
+	if err := s.scheduleRepository.SaveSchedules(c, changed); err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewLog.RecordReview(c, word, objectUserID, *req.Quality >= passingQuality, now); err != nil {
+		return nil, err
+	}
+
+	card.applySchedule(schedule)
+
+	return card, nil
+}
+
+// schedulesOf returns the user's schedule of each of the cards, by card ID.
+func (s *cardService) schedulesOf(c context.Context, userID primitive.ObjectID, cards []*Card) (map[primitive.ObjectID]*CardSchedule, error) {
+
+	ids := make([]primitive.ObjectID, 0, len(cards))
+	for _, card := range cards {
+		ids = append(ids, card.ID)
+	}
+
+	stored, err := s.scheduleRepository.GetSchedules(c, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make(map[primitive.ObjectID]*CardSchedule, len(cards))
+	for _, schedule := range stored {
+		schedules[schedule.CardID] = schedule
+	}
+	for _, card := range cards {
+		if _, ok := schedules[card.ID]; !ok {
+			schedules[card.ID] = card.startingSchedule(userID)
+		}
+	}
+
+	return schedules, nil
+}
+
+// withSchedules fills in the user's schedules of cards about to be returned.
+func (s *cardService) withSchedules(c context.Context, userID primitive.ObjectID, cards []*Card) error {
+
+	schedules, err := s.schedulesOf(c, userID, cards)
+	if err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		card.applySchedule(schedules[card.ID])
+	}
+
+	return nil
+}
+
+// SyncWordCards regenerates the cards of each word. Cards that still exist
+// keep their schedule and only have their prompt refreshed; cards the word
+// no longer produces are deleted.
+func (s *cardService) SyncWordCards(c context.Context, syncWords ...*words.Word) error {
+
+	now := time.Now()
+	var created []*Card
+	var stale, reassigned []primitive.ObjectID
+
+	for _, word := range syncWords {
+		existing, err := s.cardRepository.GetCardsByWordID(c, word.ID)
+		if err != nil {
+			return err
+		}
+
+		existingByKey := make(map[cardKey]*Card, len(existing))
+		for _, card := range existing {
+			existingByKey[card.key()] = card
+		}
+
+		for _, card := range generateCards(word) {
+			current, ok := existingByKey[card.key()]
+			if !ok {
+				card.ID = primitive.NewObjectID()
+				card.WordID = word.ID
+				card.TopicID = word.TopicID
+				card.UserID = word.UserID
+				card.EaseFactor = defaultEaseFactor
+				card.DueAt = now
+				card.CreatedAt = now
+				card.UpdatedAt = now
+				created = append(created, card)
+				continue
+			}
+			delete(existingByKey, card.key())
+
+			if current.Front == card.Front && current.Back == card.Back && current.TopicID == word.TopicID && current.UserID == word.UserID {
+				continue
+			}
+
+			if current.UserID != word.UserID {
+				reassigned = append(reassigned, word.ID)
+			}
+
+			current.Front = card.Front
+			current.Back = card.Back
+			current.TopicID = word.TopicID
+			current.UserID = word.UserID
+			current.UpdatedAt = now
+			if err := s.cardRepository.UpdateCardContent(c, current); err != nil {
+				return err
+			}
+		}
+
+		for _, card := range existingByKey {
+			stale = append(stale, card.ID)
+		}
+	}
+
+	if err := s.scheduleRepository.DeleteSchedulesByCardIDs(c, stale); err != nil {
+		return err
+	}
+	if err := s.cardRepository.DeleteCards(c, stale); err != nil {
+		return err
+	}
+
+	// The schedule stored on a card is its creator's, so it does not pass to
+	// the word's new owner.
+	if len(reassigned) > 0 {
+		if err := s.cardRepository.ResetSchedules(c, reassigned, now); err != nil {
+			return err
+		}
+	}
+
+	return s.cardRepository.CreateCards(c, created)
+}
+
+func (s *cardService) DeleteWordCards(c context.Context, wordIDs ...primitive.ObjectID) error {
+
+	if err := s.scheduleRepository.DeleteSchedulesByWordIDs(c, wordIDs); err != nil {
+		return err
+	}
+
+	return s.cardRepository.DeleteCardsByWordIDs(c, wordIDs)
+}
+
+// ResetWordCards makes every card of the words new and due now for the user.
+func (s *cardService) ResetWordCards(c context.Context, userID primitive.ObjectID, wordIDs ...primitive.ObjectID) error {
+
+	wordCards, err := s.cardRepository.GetCardsByWordIDs(c, wordIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	schedules := make([]*CardSchedule, 0, len(wordCards))
+	for _, card := range wordCards {
+		schedule := card.newSchedule(userID, now)
+		schedule.UpdatedAt = now
+		schedules = append(schedules, schedule)
+	}
+
+	return s.scheduleRepository.SaveSchedules(c, schedules)
+}
+
+// MarkWordCardsKnown moves the user's cards of the words past the learning
+// steps, so words marked known are not asked again straight away. Cards they
+// have already learned keep their schedule.
+func (s *cardService) MarkWordCardsKnown(c context.Context, userID primitive.ObjectID, wordIDs ...primitive.ObjectID) error {
+
+	wordCards, err := s.cardRepository.GetCardsByWordIDs(c, wordIDs)
+	if err != nil {
+		return err
+	}
+
+	schedules, err := s.schedulesOf(c, userID, wordCards)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := make([]*CardSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.markKnown(now) {
+			changed = append(changed, schedule)
+		}
+	}
+
+	return s.scheduleRepository.SaveSchedules(c, changed)
+}
+
+// CopyWordCards gives copied words the cards of their source words, keyed by
+// source word ID, carrying over the user's schedules of them, and then syncs
+// them like any new word.
+func (s *cardService) CopyWordCards(c context.Context, userID primitive.ObjectID, copies map[primitive.ObjectID]*words.Word) error {
+
+	now := time.Now()
+	var created []*Card
+	var schedules []*CardSchedule
+	copiedWords := make([]*words.Word, 0, len(copies))
+
+	for sourceID, word := range copies {
+		sourceCards, err := s.cardRepository.GetCardsByWordID(c, sourceID)
+		if err != nil {
+			return err
+		}
+
+		sourceSchedules, err := s.schedulesOf(c, userID, sourceCards)
+		if err != nil {
+			return err
+		}
+
+		for _, source := range sourceCards {
+			card := &Card{
+				ID:         primitive.NewObjectID(),
+				WordID:     word.ID,
+				TopicID:    word.TopicID,
+				UserID:     word.UserID,
+				Kind:       source.Kind,
+				Ordinal:    source.Ordinal,
+				Front:      source.Front,
+				Back:       source.Back,
+				EaseFactor: defaultEaseFactor,
+				DueAt:      now,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			created = append(created, card)
+
+			schedule := *sourceSchedules[source.ID]
+			schedule.ID = scheduleID(card.ID, userID)
+			schedule.CardID = card.ID
+			schedule.WordID = word.ID
+			schedule.BuriedUntil = nil
+			schedule.UpdatedAt = now
+			schedules = append(schedules, &schedule)
+		}
+		copiedWords = append(copiedWords, word)
+	}
+
+	if err := s.cardRepository.CreateCards(c, created); err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepository.SaveSchedules(c, schedules); err != nil {
+		return err
+	}
+
+	return s.SyncWordCards(c, copiedWords...)
+}
+
+func (s *cardService) DeleteTopicCards(c context.Context, topicID primitive.ObjectID) error {
+
+	topicCards, err := s.cardRepository.GetCardsByTopicIDs(c, []primitive.ObjectID{topicID})
+	if err != nil {
+		return err
+	}
+
+	cardIDs := make([]primitive.ObjectID, 0, len(topicCards))
+	for _, card := range topicCards {
+		cardIDs = append(cardIDs, card.ID)
+	}
+	if err := s.scheduleRepository.DeleteSchedulesByCardIDs(c, cardIDs); err != nil {
+		return err
+	}
+
+	return s.cardRepository.DeleteCardsByTopicID(c, topicID)
+}
+
+// GenerateMissingCards creates cards for words saved before cards existed.
+func (s *cardService) GenerateMissingCards(c context.Context) (int, error) {
+
+	wordIDs, err := s.cardRepository.GetCardWordIDs(c)
+	if err != nil {
+		return 0, err
+	}
+
+	withCards := make(map[primitive.ObjectID]struct{}, len(wordIDs))
+	for _, id := range wordIDs {
+		withCards[id] = struct{}{}
+	}
+
+	allWords, err := s.wordStore.GetAllWords(c, &words.SearchWordRequest{})
+	if err != nil {
+		return 0, err
+	}
+
+	var missing []*words.Word
+	for _, word := range allWords {
+		if _, ok := withCards[word.ID]; !ok {
+			missing = append(missing, word)
+		}
+	}
+
+	if err := s.SyncWordCards(c, missing...); err != nil {
+		return 0, err
+	}
+
+	return len(missing), nil
+}
+
+func (s *cardService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepository.DeleteSchedulesByUserID(c, objectID); err != nil {
+		return err
+	}
+
+	return s.cardRepository.DeleteCardsByUserID(c, objectID)
+}
@@ -0,0 +1,245 @@
+package classes
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClassHandler struct {
+	ClassService ClassService
+}
+
+func NewClassHandler(classService ClassService) *ClassHandler {
+	return &ClassHandler{ClassService: classService}
+}
+
+func (h *ClassHandler) CreateClass(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req CreateClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	class, err := h.ClassService.CreateClass(c, &req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", class)
+}
+
+func (h *ClassHandler) GetClasses(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	classes, err := h.ClassService.GetClasses(c, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", classes)
+}
+
+func (h *ClassHandler) GetClass(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("class_id")
+
+	class, err := h.ClassService.GetClass(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", class)
+}
+
+func (h *ClassHandler) UpdateClass(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req UpdateClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("class_id")
+
+	err := h.ClassService.UpdateClass(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ClassHandler) DeleteClass(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("class_id")
+
+	err := h.ClassService.DeleteClass(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ClassHandler) RegenerateJoinCode(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("class_id")
+
+	class, err := h.ClassService.RegenerateJoinCode(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", class)
+}
+
+func (h *ClassHandler) JoinClass(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req JoinClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	class, err := h.ClassService.JoinClass(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", class)
+}
+
+func (h *ClassHandler) RemoveStudent(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("class_id")
+	studentID := c.Param("user_id")
+
+	err := h.ClassService.RemoveStudent(c, id, userID.(string), studentID)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ClassHandler) AssignTopic(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req AssignTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("class_id")
+
+	assignment, err := h.ClassService.AssignTopic(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", assignment)
+}
+
+func (h *ClassHandler) RemoveAssignment(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("class_id")
+	assignmentID := c.Param("assignment_id")
+
+	err := h.ClassService.RemoveAssignment(c, id, userID.(string), assignmentID)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *ClassHandler) GetDashboard(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("class_id")
+
+	dashboard, err := h.ClassService.GetDashboard(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", dashboard)
+}
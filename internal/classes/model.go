@@ -0,0 +1,41 @@
+package classes
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Class struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	TeacherID   primitive.ObjectID `json:"teacher_id" bson:"teacher_id"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	JoinCode    string             `json:"join_code" bson:"join_code"`
+	Students    []ClassStudent     `json:"students" bson:"students"`
+	Assignments []Assignment       `json:"assignments" bson:"assignments"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type ClassStudent struct {
+	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Email    string             `json:"email" bson:"email"`
+	JoinedAt time.Time          `json:"joined_at" bson:"joined_at"`
+}
+
+// Assignment links a teacher's topic to the class. Every student studies
+// their own copy of the topic so that progress is tracked per student.
+type Assignment struct {
+	ID         primitive.ObjectID `json:"id" bson:"id"`
+	TopicID    primitive.ObjectID `json:"topic_id" bson:"topic_id"`
+	TopicName  string             `json:"topic_name" bson:"topic_name"`
+	DueDate    *time.Time         `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	AssignedAt time.Time          `json:"assigned_at" bson:"assigned_at"`
+	Copies     []AssignmentCopy   `json:"copies" bson:"copies"`
+}
+
+type AssignmentCopy struct {
+	StudentID primitive.ObjectID `json:"student_id" bson:"student_id"`
+	TopicID   primitive.ObjectID `json:"topic_id" bson:"topic_id"`
+}
@@ -0,0 +1,204 @@
+package classes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ClassRepository interface {
+	CreateClass(c context.Context, class *Class) error
+	GetClassByID(c context.Context, id primitive.ObjectID) (*Class, error)
+	GetClassByJoinCode(c context.Context, joinCode string) (*Class, error)
+	GetClassesByUserID(c context.Context, userID primitive.ObjectID) ([]*Class, error)
+	UpdateClass(c context.Context, id primitive.ObjectID, fields bson.M) error
+	DeleteClass(c context.Context, id primitive.ObjectID) error
+	AddStudent(c context.Context, id primitive.ObjectID, student *ClassStudent) error
+	RemoveStudent(c context.Context, id primitive.ObjectID, userID primitive.ObjectID) error
+	AddAssignment(c context.Context, id primitive.ObjectID, assignment *Assignment) error
+	AddAssignmentCopy(c context.Context, id primitive.ObjectID, assignmentID primitive.ObjectID, assignmentCopy *AssignmentCopy) error
+	RemoveAssignment(c context.Context, id primitive.ObjectID, assignmentID primitive.ObjectID) error
+	DeleteClassesByTeacherID(c context.Context, teacherID primitive.ObjectID) error
+	RemoveStudentFromAllClasses(c context.Context, userID primitive.ObjectID) error
+}
+
+type classRepository struct {
+	collection *mongo.Collection
+}
+
+func NewClassRepository(collection *mongo.Collection) ClassRepository {
+	return &classRepository{collection: collection}
+}
+
+func (r *classRepository) CreateClass(c context.Context, class *Class) error {
+	_, err := r.collection.InsertOne(c, class)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *classRepository) GetClassByID(c context.Context, id primitive.ObjectID) (*Class, error) {
+
+	var class Class
+
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&class)
+	if err != nil {
+		return nil, err
+	}
+
+	return &class, nil
+
+}
+
+func (r *classRepository) GetClassByJoinCode(c context.Context, joinCode string) (*Class, error) {
+
+	var class Class
+
+	err := r.collection.FindOne(c, bson.M{"join_code": joinCode}).Decode(&class)
+	if err != nil {
+		return nil, err
+	}
+
+	return &class, nil
+
+}
+
+func (r *classRepository) GetClassesByUserID(c context.Context, userID primitive.ObjectID) ([]*Class, error) {
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"teacher_id": userID},
+		bson.M{"students.user_id": userID},
+	}}
+
+	var classes []*Class
+
+	cursor, err := r.collection.Find(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var class Class
+		if err := cursor.Decode(&class); err != nil {
+			return nil, err
+		}
+		classes = append(classes, &class)
+	}
+
+	return classes, nil
+}
+
+func (r *classRepository) UpdateClass(c context.Context, id primitive.ObjectID, fields bson.M) error {
+
+	fields["updated_at"] = time.Now()
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *classRepository) DeleteClass(c context.Context, id primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteOne(c, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *classRepository) AddStudent(c context.Context, id primitive.ObjectID, student *ClassStudent) error {
+
+	filter := bson.M{"_id": id, "students.user_id": bson.M{"$ne": student.UserID}}
+	update := bson.M{"$push": bson.M{"students": student}}
+
+	result, err := r.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("already a member of this class")
+	}
+
+	return nil
+}
+
+func (r *classRepository) RemoveStudent(c context.Context, id primitive.ObjectID, userID primitive.ObjectID) error {
+
+	update := bson.M{"$pull": bson.M{"students": bson.M{"user_id": userID}}}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *classRepository) AddAssignment(c context.Context, id primitive.ObjectID, assignment *Assignment) error {
+
+	update := bson.M{"$push": bson.M{"assignments": assignment}}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *classRepository) AddAssignmentCopy(c context.Context, id primitive.ObjectID, assignmentID primitive.ObjectID, assignmentCopy *AssignmentCopy) error {
+
+	filter := bson.M{"_id": id, "assignments.id": assignmentID}
+	update := bson.M{"$push": bson.M{"assignments.$.copies": assignmentCopy}}
+
+	_, err := r.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *classRepository) RemoveAssignment(c context.Context, id primitive.ObjectID, assignmentID primitive.ObjectID) error {
+
+	update := bson.M{"$pull": bson.M{"assignments": bson.M{"id": assignmentID}}}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *classRepository) DeleteClassesByTeacherID(c context.Context, teacherID primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteMany(c, bson.M{"teacher_id": teacherID})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *classRepository) RemoveStudentFromAllClasses(c context.Context, userID primitive.ObjectID) error {
+
+	update := bson.M{"$pull": bson.M{"students": bson.M{"user_id": userID}}}
+
+	_, err := r.collection.UpdateMany(c, bson.M{"students.user_id": userID}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
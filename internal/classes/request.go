@@ -0,0 +1,22 @@
+package classes
+
+import "time"
+
+type CreateClassRequest struct {
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description" bson:"description"`
+}
+
+type UpdateClassRequest struct {
+	Name        *string `json:"name" bson:"name"`
+	Description *string `json:"description" bson:"description"`
+}
+
+type JoinClassRequest struct {
+	JoinCode string `json:"join_code" bson:"join_code"`
+}
+
+type AssignTopicRequest struct {
+	TopicID string     `json:"topic_id" bson:"topic_id"`
+	DueDate *time.Time `json:"due_date" bson:"due_date"`
+}
@@ -0,0 +1,63 @@
+package classes
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RoleTeacher = "teacher"
+	RoleStudent = "student"
+)
+
+type ClassResponse struct {
+	ID           primitive.ObjectID    `json:"id"`
+	TeacherID    primitive.ObjectID    `json:"teacher_id"`
+	Name         string                `json:"name"`
+	Description  string                `json:"description"`
+	Role         string                `json:"role"`
+	JoinCode     string                `json:"join_code,omitempty"`
+	StudentCount int                   `json:"student_count"`
+	Students     []ClassStudent        `json:"students,omitempty"`
+	Assignments  []*AssignmentResponse `json:"assignments"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+// AssignmentResponse shows students the ID of their own copy of the topic
+// in StudentTopicID.
+type AssignmentResponse struct {
+	ID             primitive.ObjectID  `json:"id"`
+	TopicID        primitive.ObjectID  `json:"topic_id"`
+	TopicName      string              `json:"topic_name"`
+	DueDate        *time.Time          `json:"due_date,omitempty"`
+	AssignedAt     time.Time           `json:"assigned_at"`
+	StudentTopicID *primitive.ObjectID `json:"student_topic_id,omitempty"`
+}
+
+type DashboardResponse struct {
+	ClassID     primitive.ObjectID            `json:"class_id"`
+	Name        string                        `json:"name"`
+	Assignments []*AssignmentProgressResponse `json:"assignments"`
+}
+
+type AssignmentProgressResponse struct {
+	AssignmentID primitive.ObjectID         `json:"assignment_id"`
+	TopicID      primitive.ObjectID         `json:"topic_id"`
+	TopicName    string                     `json:"topic_name"`
+	DueDate      *time.Time                 `json:"due_date,omitempty"`
+	Students     []*StudentProgressResponse `json:"students"`
+}
+
+type StudentProgressResponse struct {
+	StudentID         primitive.ObjectID  `json:"student_id"`
+	Email             string              `json:"email"`
+	TopicID           *primitive.ObjectID `json:"topic_id,omitempty"`
+	WordCount         int                 `json:"word_count"`
+	CompletedCount    int                 `json:"completed_count"`
+	CompletionPercent float64             `json:"completion_percent"`
+	RetentionPercent  float64             `json:"retention_percent"`
+	LastStudiedAt     *time.Time          `json:"last_studied_at,omitempty"`
+	Overdue           bool                `json:"overdue"`
+}
@@ -0,0 +1,26 @@
+package classes
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *ClassHandler) {
+
+	classGroup := r.Group("/api/v1/class")
+	{
+		classGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateClass)
+		classGroup.GET("", middleware.JWTAuthMiddleware(), handler.GetClasses)
+		classGroup.POST("/join", middleware.JWTAuthMiddleware(), handler.JoinClass)
+		classGroup.GET("/:class_id", middleware.JWTAuthMiddleware(), handler.GetClass)
+		classGroup.PUT("/:class_id", middleware.JWTAuthMiddleware(), handler.UpdateClass)
+		classGroup.DELETE("/:class_id", middleware.JWTAuthMiddleware(), handler.DeleteClass)
+		classGroup.POST("/:class_id/join-code", middleware.JWTAuthMiddleware(), handler.RegenerateJoinCode)
+		classGroup.DELETE("/:class_id/students/:user_id", middleware.JWTAuthMiddleware(), handler.RemoveStudent)
+		classGroup.POST("/:class_id/assignments", middleware.JWTAuthMiddleware(), handler.AssignTopic)
+		classGroup.DELETE("/:class_id/assignments/:assignment_id", middleware.JWTAuthMiddleware(), handler.RemoveAssignment)
+		classGroup.GET("/:class_id/dashboard", middleware.JWTAuthMiddleware(), handler.GetDashboard)
+	}
+
+}
@@ -0,0 +1,550 @@
+package classes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"flashcard/helper"
+	"flashcard/internal/topics"
+	"flashcard/internal/user"
+	"flashcard/internal/words"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const joinCodeAttempts = 5
+
+type ClassService interface {
+	CreateClass(c context.Context, req *CreateClassRequest, userID string) (*ClassResponse, error)
+	GetClasses(c context.Context, userID string) ([]*ClassResponse, error)
+	GetClass(c context.Context, id string, userID string) (*ClassResponse, error)
+	UpdateClass(c context.Context, id string, userID string, req *UpdateClassRequest) error
+	DeleteClass(c context.Context, id string, userID string) error
+	RegenerateJoinCode(c context.Context, id string, userID string) (*ClassResponse, error)
+	JoinClass(c context.Context, userID string, req *JoinClassRequest) (*ClassResponse, error)
+	RemoveStudent(c context.Context, id string, userID string, studentID string) error
+	AssignTopic(c context.Context, id string, userID string, req *AssignTopicRequest) (*AssignmentResponse, error)
+	RemoveAssignment(c context.Context, id string, userID string, assignmentID string) error
+	GetDashboard(c context.Context, id string, userID string) (*DashboardResponse, error)
+	DeleteUserData(c context.Context, userID string) error
+}
+
+// StudentDirectory resolves students joining a class to their accounts.
+type StudentDirectory interface {
+	FindByID(ctx context.Context, userID primitive.ObjectID) (*user.User, error)
+}
+
+type classService struct {
+	classRepository  ClassRepository
+	topicService     topics.TopicService
+	wordService      words.WordService
+	studentDirectory StudentDirectory
+}
+
+func NewClassService(classRepository ClassRepository, topicService topics.TopicService, wordService words.WordService, studentDirectory StudentDirectory) ClassService {
+	return &classService{
+		classRepository:  classRepository,
+		topicService:     topicService,
+		wordService:      wordService,
+		studentDirectory: studentDirectory,
+	}
+}
+
+func (s *classService) CreateClass(c context.Context, req *CreateClassRequest, userID string) (*ClassResponse, error) {
+
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("class name is required")
+	}
+
+	teacherID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	joinCode, err := s.newJoinCode(c)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	class := &Class{
+		ID:          primitive.NewObjectID(),
+		TeacherID:   teacherID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		JoinCode:    joinCode,
+		Students:    []ClassStudent{},
+		Assignments: []Assignment{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.classRepository.CreateClass(c, class); err != nil {
+		return nil, err
+	}
+
+	return toClassResponse(class, teacherID), nil
+}
+
+func (s *classService) GetClasses(c context.Context, userID string) ([]*ClassResponse, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	classes, err := s.classRepository.GetClassesByUserID(c, objectUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ClassResponse, 0, len(classes))
+	for _, class := range classes {
+		result = append(result, toClassResponse(class, objectUserID))
+	}
+
+	return result, nil
+}
+
+func (s *classService) GetClass(c context.Context, id string, userID string) (*ClassResponse, error) {
+
+	class, objectUserID, err := s.getClass(c, id, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return toClassResponse(class, objectUserID), nil
+}
+
+func (s *classService) UpdateClass(c context.Context, id string, userID string, req *UpdateClassRequest) error {
+
+	class, _, err := s.getClass(c, id, userID, true)
+	if err != nil {
+		return err
+	}
+
+	fields := bson.M{}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return fmt.Errorf("class name is required")
+		}
+		fields["name"] = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+
+	return s.classRepository.UpdateClass(c, class.ID, fields)
+}
+
+func (s *classService) DeleteClass(c context.Context, id string, userID string) error {
+
+	class, _, err := s.getClass(c, id, userID, true)
+	if err != nil {
+		return err
+	}
+
+	return s.classRepository.DeleteClass(c, class.ID)
+}
+
+func (s *classService) RegenerateJoinCode(c context.Context, id string, userID string) (*ClassResponse, error) {
+
+	class, teacherID, err := s.getClass(c, id, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	joinCode, err := s.newJoinCode(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.classRepository.UpdateClass(c, class.ID, bson.M{"join_code": joinCode}); err != nil {
+		return nil, err
+	}
+
+	class.JoinCode = joinCode
+	return toClassResponse(class, teacherID), nil
+}
+
+func (s *classService) JoinClass(c context.Context, userID string, req *JoinClassRequest) (*ClassResponse, error) {
+
+	joinCode := strings.ToUpper(strings.TrimSpace(req.JoinCode))
+	if joinCode == "" {
+		return nil, fmt.Errorf("join code is required")
+	}
+
+	studentID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := s.classRepository.GetClassByJoinCode(c, joinCode)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: invalid join code", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if class.TeacherID == studentID {
+		return nil, fmt.Errorf("teachers cannot join their own class")
+	}
+
+	student, err := s.studentDirectory.FindByID(c, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if student == nil {
+		return nil, fmt.Errorf("%w: user not found", helper.ErrResourceNotFound)
+	}
+
+	member := &ClassStudent{
+		UserID:   studentID,
+		Email:    student.Email,
+		JoinedAt: time.Now(),
+	}
+
+	if err := s.classRepository.AddStudent(c, class.ID, member); err != nil {
+		return nil, err
+	}
+	class.Students = append(class.Students, *member)
+
+	for i := range class.Assignments {
+		assignmentCopy, err := s.copyAssignment(c, class, &class.Assignments[i], studentID)
+		if err != nil {
+			return nil, err
+		}
+		if assignmentCopy != nil {
+			class.Assignments[i].Copies = append(class.Assignments[i].Copies, *assignmentCopy)
+		}
+	}
+
+	return toClassResponse(class, studentID), nil
+}
+
+// RemoveStudent lets the teacher remove a student and lets a student leave
+// the class. Students keep their copies of the assigned topics.
+func (s *classService) RemoveStudent(c context.Context, id string, userID string, studentID string) error {
+
+	class, _, err := s.getClass(c, id, userID, false)
+	if err != nil {
+		return err
+	}
+
+	if class.TeacherID.Hex() != userID && studentID != userID {
+		return fmt.Errorf("%w: only the teacher can remove other students", helper.ErrPermissionDenied)
+	}
+
+	objectStudentID, err := primitive.ObjectIDFromHex(studentID)
+	if err != nil {
+		return err
+	}
+
+	if findStudent(class, objectStudentID) == nil {
+		return fmt.Errorf("%w: student not found", helper.ErrResourceNotFound)
+	}
+
+	return s.classRepository.RemoveStudent(c, class.ID, objectStudentID)
+}
+
+func (s *classService) AssignTopic(c context.Context, id string, userID string, req *AssignTopicRequest) (*AssignmentResponse, error) {
+
+	class, teacherID, err := s.getClass(c, id, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TopicID == "" {
+		return nil, fmt.Errorf("topic id is required")
+	}
+
+	topic, err := s.topicService.GetTopicByID(c, req.TopicID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if topic.Role != topics.RoleOwner {
+		return nil, fmt.Errorf("%w: only topics you own can be assigned", helper.ErrPermissionDenied)
+	}
+
+	for _, existing := range class.Assignments {
+		if existing.TopicID == topic.ID {
+			return nil, fmt.Errorf("topic is already assigned to this class")
+		}
+	}
+
+	assignment := &Assignment{
+		ID:         primitive.NewObjectID(),
+		TopicID:    topic.ID,
+		TopicName:  topic.TopicName,
+		DueDate:    req.DueDate,
+		AssignedAt: time.Now(),
+		Copies:     []AssignmentCopy{},
+	}
+
+	if err := s.classRepository.AddAssignment(c, class.ID, assignment); err != nil {
+		return nil, err
+	}
+
+	for _, student := range class.Students {
+		if _, err := s.copyAssignment(c, class, assignment, student.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return toAssignmentResponse(assignment, teacherID, true), nil
+}
+
+func (s *classService) RemoveAssignment(c context.Context, id string, userID string, assignmentID string) error {
+
+	class, _, err := s.getClass(c, id, userID, true)
+	if err != nil {
+		return err
+	}
+
+	objectAssignmentID, err := primitive.ObjectIDFromHex(assignmentID)
+	if err != nil {
+		return err
+	}
+
+	return s.classRepository.RemoveAssignment(c, class.ID, objectAssignmentID)
+}
+
+func (s *classService) GetDashboard(c context.Context, id string, userID string) (*DashboardResponse, error) {
+
+	class, _, err := s.getClass(c, id, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dashboard := &DashboardResponse{
+		ClassID:     class.ID,
+		Name:        class.Name,
+		Assignments: make([]*AssignmentProgressResponse, 0, len(class.Assignments)),
+	}
+
+	for _, assignment := range class.Assignments {
+		progress := &AssignmentProgressResponse{
+			AssignmentID: assignment.ID,
+			TopicID:      assignment.TopicID,
+			TopicName:    assignment.TopicName,
+			DueDate:      assignment.DueDate,
+			Students:     make([]*StudentProgressResponse, 0, len(class.Students)),
+		}
+
+		for _, student := range class.Students {
+			studentProgress := &StudentProgressResponse{
+				StudentID: student.UserID,
+				Email:     student.Email,
+			}
+
+			if assignmentCopy := findCopy(&assignment, student.UserID); assignmentCopy != nil {
+				topicID := assignmentCopy.TopicID
+				studentProgress.TopicID = &topicID
+
+				topicProgress, err := s.wordService.GetTopicProgress(c, topicID.Hex(), student.UserID.Hex())
+				if err != nil {
+					return nil, err
+				}
+				studentProgress.WordCount = topicProgress.WordCount
+				studentProgress.CompletedCount = topicProgress.CompletedCount
+				studentProgress.CompletionPercent = topicProgress.CompletionPercent
+				studentProgress.RetentionPercent = topicProgress.RetentionPercent
+				studentProgress.LastStudiedAt = topicProgress.LastStudiedAt
+			}
+
+			studentProgress.Overdue = assignment.DueDate != nil &&
+				now.After(*assignment.DueDate) &&
+				studentProgress.CompletionPercent < 100
+
+			progress.Students = append(progress.Students, studentProgress)
+		}
+
+		dashboard.Assignments = append(dashboard.Assignments, progress)
+	}
+
+	return dashboard, nil
+}
+
+func (s *classService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.classRepository.RemoveStudentFromAllClasses(c, objectID); err != nil {
+		return err
+	}
+
+	return s.classRepository.DeleteClassesByTeacherID(c, objectID)
+}
+
+// copyAssignment gives a student their own clone of an assigned topic.
+// Assignments whose topic has since been deleted are skipped.
+func (s *classService) copyAssignment(c context.Context, class *Class, assignment *Assignment, studentID primitive.ObjectID) (*AssignmentCopy, error) {
+
+	if findCopy(assignment, studentID) != nil {
+		return nil, nil
+	}
+
+	clone, err := s.topicService.CloneTopicForUser(c, assignment.TopicID.Hex(), class.TeacherID.Hex(), studentID.Hex())
+	if errors.Is(err, helper.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	assignmentCopy := &AssignmentCopy{
+		StudentID: studentID,
+		TopicID:   clone.ID,
+	}
+
+	if err := s.classRepository.AddAssignmentCopy(c, class.ID, assignment.ID, assignmentCopy); err != nil {
+		return nil, err
+	}
+
+	return assignmentCopy, nil
+}
+
+func (s *classService) getClass(c context.Context, id string, userID string, teacherOnly bool) (*Class, primitive.ObjectID, error) {
+
+	if id == "" {
+		return nil, primitive.NilObjectID, fmt.Errorf("class id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	class, err := s.classRepository.GetClassByID(c, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, primitive.NilObjectID, fmt.Errorf("%w: class not found", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	if class.TeacherID == objectUserID {
+		return class, objectUserID, nil
+	}
+
+	if findStudent(class, objectUserID) == nil {
+		return nil, primitive.NilObjectID, fmt.Errorf("%w: class not found", helper.ErrResourceNotFound)
+	}
+
+	if teacherOnly {
+		return nil, primitive.NilObjectID, fmt.Errorf("%w: only the teacher can do this", helper.ErrPermissionDenied)
+	}
+
+	return class, objectUserID, nil
+}
+
+func (s *classService) newJoinCode(c context.Context) (string, error) {
+
+	for i := 0; i < joinCodeAttempts; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		code := base32.StdEncoding.EncodeToString(buf)
+
+		_, err := s.classRepository.GetClassByJoinCode(c, code)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return code, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate a unique join code")
+}
+
+func toClassResponse(class *Class, userID primitive.ObjectID) *ClassResponse {
+
+	isTeacher := class.TeacherID == userID
+
+	res := &ClassResponse{
+		ID:           class.ID,
+		TeacherID:    class.TeacherID,
+		Name:         class.Name,
+		Description:  class.Description,
+		Role:         RoleStudent,
+		StudentCount: len(class.Students),
+		Assignments:  make([]*AssignmentResponse, 0, len(class.Assignments)),
+		CreatedAt:    class.CreatedAt,
+		UpdatedAt:    class.UpdatedAt,
+	}
+
+	if isTeacher {
+		res.Role = RoleTeacher
+		res.JoinCode = class.JoinCode
+		res.Students = class.Students
+	}
+
+	for i := range class.Assignments {
+		res.Assignments = append(res.Assignments, toAssignmentResponse(&class.Assignments[i], userID, isTeacher))
+	}
+
+	return res
+}
+
+func toAssignmentResponse(assignment *Assignment, userID primitive.ObjectID, isTeacher bool) *AssignmentResponse {
+
+	res := &AssignmentResponse{
+		ID:         assignment.ID,
+		TopicID:    assignment.TopicID,
+		TopicName:  assignment.TopicName,
+		DueDate:    assignment.DueDate,
+		AssignedAt: assignment.AssignedAt,
+	}
+
+	if !isTeacher {
+		if assignmentCopy := findCopy(assignment, userID); assignmentCopy != nil {
+			topicID := assignmentCopy.TopicID
+			res.StudentTopicID = &topicID
+		}
+	}
+
+	return res
+}
+
+func findStudent(class *Class, userID primitive.ObjectID) *ClassStudent {
+	for i := range class.Students {
+		if class.Students[i].UserID == userID {
+			return &class.Students[i]
+		}
+	}
+	return nil
+}
+
+func findCopy(assignment *Assignment, studentID primitive.ObjectID) *AssignmentCopy {
+	for i := range assignment.Copies {
+		if assignment.Copies[i].StudentID == studentID {
+			return &assignment.Copies[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package clientsync
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SyncHandler struct {
+	SyncService SyncService
+}
+
+func NewSyncHandler(syncService SyncService) *SyncHandler {
+	return &SyncHandler{SyncService: syncService}
+}
+
+func (h *SyncHandler) Sync(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.SyncService.Sync(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", res)
+}
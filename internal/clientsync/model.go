@@ -0,0 +1,34 @@
+package clientsync
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	KindTopic  = "topic"
+	KindWord   = "word"
+	KindReview = "review"
+)
+
+// Tombstone marks a deleted document. Topic tombstones are addressed to the
+// users who lost the topic, word tombstones to everyone who can still see
+// the word's topic.
+type Tombstone struct {
+	ID         primitive.ObjectID   `json:"-" bson:"_id"`
+	Kind       string               `json:"kind" bson:"kind"`
+	DocumentID primitive.ObjectID   `json:"id" bson:"document_id"`
+	TopicID    primitive.ObjectID   `json:"topic_id" bson:"topic_id"`
+	UserIDs    []primitive.ObjectID `json:"-" bson:"user_ids,omitempty"`
+	DeletedAt  time.Time            `json:"deleted_at" bson:"deleted_at"`
+}
+
+// ReviewReceipt remembers a review uploaded by a client, keyed by the user
+// and the client's review ID, so a retried upload is not counted twice.
+type ReviewReceipt struct {
+	ID         string             `bson:"_id"`
+	UserID     primitive.ObjectID `bson:"user_id"`
+	WordID     primitive.ObjectID `bson:"word_id"`
+	ReceivedAt time.Time          `bson:"received_at"`
+}
@@ -0,0 +1,151 @@
+package clientsync
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TombstoneRepository also serves as the deletion log of the topics and
+// words services.
+type TombstoneRepository interface {
+	RecordTopicDeletion(c context.Context, topicID primitive.ObjectID, userIDs []primitive.ObjectID) error
+	RecordWordDeletions(c context.Context, topicID primitive.ObjectID, wordIDs ...primitive.ObjectID) error
+	GetTombstones(c context.Context, topicIDs []primitive.ObjectID, userID primitive.ObjectID, since time.Time) ([]*Tombstone, error)
+	RemoveUserFromTombstones(c context.Context, userID primitive.ObjectID) error
+}
+
+type tombstoneRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTombstoneRepository(collection *mongo.Collection) TombstoneRepository {
+	return &tombstoneRepository{collection: collection}
+}
+
+func (r *tombstoneRepository) RecordTopicDeletion(c context.Context, topicID primitive.ObjectID, userIDs []primitive.ObjectID) error {
+
+	tombstone := &Tombstone{
+		ID:         primitive.NewObjectID(),
+		Kind:       KindTopic,
+		DocumentID: topicID,
+		TopicID:    topicID,
+		UserIDs:    userIDs,
+		DeletedAt:  time.Now(),
+	}
+
+	_, err := r.collection.InsertOne(c, tombstone)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *tombstoneRepository) RecordWordDeletions(c context.Context, topicID primitive.ObjectID, wordIDs ...primitive.ObjectID) error {
+
+	if len(wordIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, 0, len(wordIDs))
+	for _, wordID := range wordIDs {
+		documents = append(documents, &Tombstone{
+			ID:         primitive.NewObjectID(),
+			Kind:       KindWord,
+			DocumentID: wordID,
+			TopicID:    topicID,
+			DeletedAt:  now,
+		})
+	}
+
+	_, err := r.collection.InsertMany(c, documents)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *tombstoneRepository) GetTombstones(c context.Context, topicIDs []primitive.ObjectID, userID primitive.ObjectID, since time.Time) ([]*Tombstone, error) {
+
+	filter := bson.M{
+		"deleted_at": bson.M{"$gt": since},
+		"$or": bson.A{
+			bson.M{"kind": KindWord, "topic_id": bson.M{"$in": topicIDs}},
+			bson.M{"kind": KindTopic, "user_ids": userID},
+		},
+	}
+
+	var tombstones []*Tombstone
+
+	cursor, err := r.collection.Find(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var tombstone Tombstone
+		if err := cursor.Decode(&tombstone); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, &tombstone)
+	}
+
+	return tombstones, nil
+}
+
+func (r *tombstoneRepository) RemoveUserFromTombstones(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(c, bson.M{"user_ids": userID}, bson.M{"$pull": bson.M{"user_ids": userID}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type ReceiptRepository interface {
+	RecordReceipt(c context.Context, receipt *ReviewReceipt) (bool, error)
+	DeleteReceipt(c context.Context, id string) error
+	DeleteReceiptsByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type receiptRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReceiptRepository(collection *mongo.Collection) ReceiptRepository {
+	return &receiptRepository{collection: collection}
+}
+
+// RecordReceipt reports false when the receipt already exists.
+func (r *receiptRepository) RecordReceipt(c context.Context, receipt *ReviewReceipt) (bool, error) {
+
+	_, err := r.collection.InsertOne(c, receipt)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *receiptRepository) DeleteReceipt(c context.Context, id string) error {
+	_, err := r.collection.DeleteOne(c, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *receiptRepository) DeleteReceiptsByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package clientsync
+
+import "time"
+
+// SyncRequest uploads the changes a client made since SyncToken. New topics
+// and words carry a client-generated ObjectID; BaseVersion is the version
+// the client last saw (0 for new documents) and ModifiedAt when the change
+// was made on the device.
+type SyncRequest struct {
+	SyncToken string         `json:"sync_token" bson:"sync_token"`
+	Topics    []TopicChange  `json:"topics" bson:"topics"`
+	Words     []WordChange   `json:"words" bson:"words"`
+	Reviews   []ReviewChange `json:"reviews" bson:"reviews"`
+}
+
+type TopicChange struct {
+	ID          string    `json:"id" bson:"id"`
+	BaseVersion int64     `json:"base_version" bson:"base_version"`
+	ModifiedAt  time.Time `json:"modified_at" bson:"modified_at"`
+	Deleted     bool      `json:"deleted" bson:"deleted"`
+	Name        *string   `json:"name" bson:"name"`
+	Description *string   `json:"description" bson:"description"`
+	Color       *string   `json:"color" bson:"color"`
+	Language    *string   `json:"language" bson:"language"`
+	Tags        *[]string `json:"tags" bson:"tags"`
+}
+
+type WordChange struct {
+	ID            string    `json:"id" bson:"id"`
+	TopicID       string    `json:"topic_id" bson:"topic_id"`
+	BaseVersion   int64     `json:"base_version" bson:"base_version"`
+	ModifiedAt    time.Time `json:"modified_at" bson:"modified_at"`
+	Deleted       bool      `json:"deleted" bson:"deleted"`
+	Word          *string   `json:"word" bson:"word"`
+	Definition    *string   `json:"definition" bson:"definition"`
+	Example       *string   `json:"example" bson:"example"`
+	WordType      *string   `json:"word_type" bson:"word_type"`
+	Pronunciation *string   `json:"pronunciation" bson:"pronunciation"`
+	Tags          *[]string `json:"tags" bson:"tags"`
+}
+
+// ReviewChange is an answer given offline. ID is generated by the client and
+// makes retried uploads idempotent.
+type ReviewChange struct {
+	ID      string `json:"id" bson:"id"`
+	WordID  string `json:"word_id" bson:"word_id"`
+	Correct bool   `json:"correct" bson:"correct"`
+}
@@ -0,0 +1,45 @@
+package clientsync
+
+import (
+	"flashcard/internal/topics"
+	"flashcard/internal/words"
+)
+
+const (
+	StatusApplied   = "applied"
+	StatusConflict  = "conflict"
+	StatusRejected  = "rejected"
+	StatusDuplicate = "duplicate"
+
+	ResolutionClient = "client"
+	ResolutionServer = "server"
+)
+
+// SyncResponse returns the server changes since the request's token and a
+// new token to send next time.
+type SyncResponse struct {
+	SyncToken  string                  `json:"sync_token"`
+	Topics     []*topics.TopicResponse `json:"topics"`
+	Words      []*words.Word           `json:"words"`
+	Tombstones []*Tombstone            `json:"tombstones"`
+	Results    []*ChangeResult         `json:"results"`
+}
+
+// ChangeResult reports what happened to one uploaded change. A conflict
+// means the document was also edited elsewhere since BaseVersion; the most
+// recent edit wins, as shown by Resolution, and Conflicts lists the fields
+// whose values differed.
+type ChangeResult struct {
+	Kind       string          `json:"kind"`
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	Resolution string          `json:"resolution,omitempty"`
+	Conflicts  []FieldConflict `json:"conflicts,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type FieldConflict struct {
+	Field       string      `json:"field"`
+	ClientValue interface{} `json:"client_value"`
+	ServerValue interface{} `json:"server_value"`
+}
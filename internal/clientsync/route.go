@@ -0,0 +1,16 @@
+package clientsync
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *SyncHandler) {
+
+	syncGroup := r.Group("/api/v1/sync")
+	{
+		syncGroup.POST("", middleware.JWTAuthMiddleware(), handler.Sync)
+	}
+
+}
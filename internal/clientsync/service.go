@@ -0,0 +1,409 @@
+package clientsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"flashcard/helper"
+	"flashcard/internal/topics"
+	"flashcard/internal/words"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SyncService interface {
+	Sync(c context.Context, userID string, req *SyncRequest) (*SyncResponse, error)
+	DeleteUserData(c context.Context, userID string) error
+}
+
+type syncService struct {
+	topicService        topics.TopicService
+	wordService         words.WordService
+	tombstoneRepository TombstoneRepository
+	receiptRepository   ReceiptRepository
+}
+
+func NewSyncService(topicService topics.TopicService, wordService words.WordService, tombstoneRepository TombstoneRepository, receiptRepository ReceiptRepository) SyncService {
+	return &syncService{
+		topicService:        topicService,
+		wordService:         wordService,
+		tombstoneRepository: tombstoneRepository,
+		receiptRepository:   receiptRepository,
+	}
+}
+
+// Sync applies the client's changes in order (topics, then words, then
+// reviews) and returns everything that changed on the server since the
+// client's token, including the client's own accepted changes. A change that
+// fails is reported in its result and does not stop the others.
+func (s *syncService) Sync(c context.Context, userID string, req *SyncRequest) (*SyncResponse, error) {
+
+	since, err := decodeToken(req.SyncToken)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ChangeResult, 0, len(req.Topics)+len(req.Words)+len(req.Reviews))
+	for i := range req.Topics {
+		results = append(results, s.applyTopicChange(c, userID, &req.Topics[i]))
+	}
+	for i := range req.Words {
+		results = append(results, s.applyWordChange(c, userID, &req.Words[i]))
+	}
+	for i := range req.Reviews {
+		results = append(results, s.applyReview(c, userID, objectUserID, &req.Reviews[i]))
+	}
+
+	issuedAt := time.Now()
+
+	accessibleTopics, err := s.topicService.GetAccessibleTopics(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topicIDs := make([]primitive.ObjectID, 0, len(accessibleTopics))
+	changedTopics := make([]*topics.TopicResponse, 0)
+	var joinedTopicIDs, knownTopicIDs []primitive.ObjectID
+	for _, topic := range accessibleTopics {
+		topicIDs = append(topicIDs, topic.ID)
+		if topic.UpdatedAt.After(since) {
+			changedTopics = append(changedTopics, topic)
+		}
+		if topic.JoinedAt != nil && topic.JoinedAt.After(since) {
+			joinedTopicIDs = append(joinedTopicIDs, topic.ID)
+		} else {
+			knownTopicIDs = append(knownTopicIDs, topic.ID)
+		}
+	}
+
+	changedWords, err := s.wordService.GetWordsUpdatedSince(c, objectUserID, knownTopicIDs, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client has none of the words of a topic shared with the user since
+	// its token, however long ago they changed.
+	joinedWords, err := s.wordService.GetWordsUpdatedSince(c, objectUserID, joinedTopicIDs, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	changedWords = append(changedWords, joinedWords...)
+	if changedWords == nil {
+		changedWords = []*words.Word{}
+	}
+
+	tombstones, err := s.tombstoneRepository.GetTombstones(c, topicIDs, objectUserID, since)
+	if err != nil {
+		return nil, err
+	}
+	tombstones = dropLiveTombstones(tombstones, topicIDs, changedWords)
+
+	return &SyncResponse{
+		SyncToken:  encodeToken(issuedAt),
+		Topics:     changedTopics,
+		Words:      changedWords,
+		Tombstones: tombstones,
+		Results:    results,
+	}, nil
+}
+
+func (s *syncService) applyTopicChange(c context.Context, userID string, change *TopicChange) *ChangeResult {
+
+	result := &ChangeResult{Kind: KindTopic, ID: change.ID}
+
+	current, err := s.topicService.GetTopicByID(c, change.ID, userID)
+	if errors.Is(err, helper.ErrResourceNotFound) {
+		if change.Deleted {
+			result.Status = StatusApplied
+			return result
+		}
+		err = s.topicService.CreateTopic(c, &topics.CreateTopicRequest{
+			ID:               change.ID,
+			TopicName:        stringValue(change.Name),
+			TopicDescription: stringValue(change.Description),
+			Color:            stringValue(change.Color),
+			Language:         stringValue(change.Language),
+			Tags:             tagsValue(change.Tags),
+		}, userID)
+		return finish(result, err)
+	}
+	if err != nil {
+		return finish(result, err)
+	}
+
+	differing := topicConflicts(current, change)
+	if !change.Deleted && len(differing) == 0 {
+		return finish(result, nil)
+	}
+
+	if change.BaseVersion != current.Version {
+		result.Conflicts = differing
+		if serverWins(current.UpdatedAt, change.ModifiedAt) {
+			result.Status = StatusConflict
+			result.Resolution = ResolutionServer
+			return result
+		}
+		result.Resolution = ResolutionClient
+	}
+
+	if change.Deleted {
+		return finish(result, s.topicService.DeleteTopic(c, change.ID, userID))
+	}
+
+	_, err = s.topicService.UpdateTopic(c, change.ID, userID, &topics.UpdateTopicRequest{
+		TopicName:        change.Name,
+		TopicDescription: change.Description,
+		Color:            change.Color,
+		Language:         change.Language,
+		Tags:             change.Tags,
+		ExpectedVersion:  &current.Version,
+	})
+	return finish(result, err)
+}
+
+func (s *syncService) applyWordChange(c context.Context, userID string, change *WordChange) *ChangeResult {
+
+	result := &ChangeResult{Kind: KindWord, ID: change.ID}
+
+	current, err := s.wordService.GetWordByID(c, change.ID, userID)
+	if errors.Is(err, helper.ErrResourceNotFound) {
+		if change.Deleted {
+			result.Status = StatusApplied
+			return result
+		}
+		err = s.wordService.CreateWord(c, &words.CreateWordRequest{
+			ID:            change.ID,
+			TopicID:       change.TopicID,
+			Word:          stringValue(change.Word),
+			Definition:    stringValue(change.Definition),
+			Example:       change.Example,
+			WordType:      stringValue(change.WordType),
+			Pronunciation: stringValue(change.Pronunciation),
+			Tags:          tagsValue(change.Tags),
+		}, userID)
+		return finish(result, err)
+	}
+	if err != nil {
+		return finish(result, err)
+	}
+
+	differing := wordConflicts(current, change)
+	if !change.Deleted && len(differing) == 0 {
+		return finish(result, nil)
+	}
+
+	if change.BaseVersion != current.Version {
+		result.Conflicts = differing
+		if serverWins(current.UpdatedAt, change.ModifiedAt) {
+			result.Status = StatusConflict
+			result.Resolution = ResolutionServer
+			return result
+		}
+		result.Resolution = ResolutionClient
+	}
+
+	if change.Deleted {
+		return finish(result, s.wordService.DeleteWord(c, change.ID, userID))
+	}
+
+	_, err = s.wordService.UpdateWord(c, change.ID, userID, &words.UpdateWordRequest{
+		Word:            change.Word,
+		Definition:      change.Definition,
+		Example:         change.Example,
+		WordType:        change.WordType,
+		Pronunciation:   change.Pronunciation,
+		Tags:            change.Tags,
+		ExpectedVersion: &current.Version,
+	})
+	return finish(result, err)
+}
+
+func (s *syncService) applyReview(c context.Context, userID string, objectUserID primitive.ObjectID, review *ReviewChange) *ChangeResult {
+
+	result := &ChangeResult{Kind: KindReview, ID: review.ID}
+
+	if review.ID == "" {
+		return finish(result, fmt.Errorf("review id is required"))
+	}
+
+	wordID, err := primitive.ObjectIDFromHex(review.WordID)
+	if err != nil {
+		return finish(result, err)
+	}
+
+	receiptID := userID + ":" + review.ID
+	recorded, err := s.receiptRepository.RecordReceipt(c, &ReviewReceipt{
+		ID:         receiptID,
+		UserID:     objectUserID,
+		WordID:     wordID,
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		return finish(result, err)
+	}
+	if !recorded {
+		result.Status = StatusDuplicate
+		return result
+	}
+
+	if err := s.wordService.ReviewWord(c, review.WordID, userID, &words.ReviewWordRequest{Correct: review.Correct}); err != nil {
+		if deleteErr := s.receiptRepository.DeleteReceipt(c, receiptID); deleteErr != nil {
+			return finish(result, deleteErr)
+		}
+		return finish(result, err)
+	}
+
+	result.Status = StatusApplied
+	return result
+}
+
+func (s *syncService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.receiptRepository.DeleteReceiptsByUserID(c, objectID); err != nil {
+		return err
+	}
+
+	return s.tombstoneRepository.RemoveUserFromTombstones(c, objectID)
+}
+
+// dropLiveTombstones removes word tombstones for words that are still
+// returned, such as a word moved out of one topic into another the client
+// can see, and topic tombstones for topics the user can see again, such as
+// one they left and rejoined.
+func dropLiveTombstones(tombstones []*Tombstone, topicIDs []primitive.ObjectID, changedWords []*words.Word) []*Tombstone {
+
+	live := make(map[primitive.ObjectID]bool, len(changedWords))
+	for _, word := range changedWords {
+		live[word.ID] = true
+	}
+
+	liveTopics := make(map[primitive.ObjectID]bool, len(topicIDs))
+	for _, topicID := range topicIDs {
+		liveTopics[topicID] = true
+	}
+
+	kept := make([]*Tombstone, 0, len(tombstones))
+	for _, tombstone := range tombstones {
+		if tombstone.Kind == KindWord && live[tombstone.DocumentID] {
+			continue
+		}
+		if tombstone.Kind == KindTopic && liveTopics[tombstone.DocumentID] {
+			continue
+		}
+		kept = append(kept, tombstone)
+	}
+
+	return kept
+}
+
+// finish marks a result applied, or rejected with the error. A result that
+// already carries a resolution keeps the conflict status.
+func finish(result *ChangeResult, err error) *ChangeResult {
+
+	if err != nil {
+		result.Status = StatusRejected
+		result.Resolution = ""
+		result.Conflicts = nil
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = StatusApplied
+	if result.Resolution != "" {
+		result.Status = StatusConflict
+	}
+
+	return result
+}
+
+// serverWins applies last-writer-wins. Changes without a device timestamp
+// count as made now.
+func serverWins(serverUpdatedAt, clientModifiedAt time.Time) bool {
+	if clientModifiedAt.IsZero() {
+		return false
+	}
+	return serverUpdatedAt.After(clientModifiedAt)
+}
+
+func topicConflicts(current *topics.TopicResponse, change *TopicChange) []FieldConflict {
+
+	var conflicts []FieldConflict
+	conflicts = appendStringConflict(conflicts, "name", change.Name, current.TopicName)
+	conflicts = appendStringConflict(conflicts, "description", change.Description, stringValue(current.TopicDescription))
+	conflicts = appendStringConflict(conflicts, "color", change.Color, current.Color)
+	conflicts = appendStringConflict(conflicts, "language", change.Language, current.Language)
+	conflicts = appendTagsConflict(conflicts, change.Tags, current.Tags)
+	return conflicts
+}
+
+func wordConflicts(current *words.Word, change *WordChange) []FieldConflict {
+
+	var conflicts []FieldConflict
+	conflicts = appendStringConflict(conflicts, "word", change.Word, current.Word)
+	conflicts = appendStringConflict(conflicts, "definition", change.Definition, current.Definition)
+	conflicts = appendStringConflict(conflicts, "example", change.Example, stringValue(current.Example))
+	conflicts = appendStringConflict(conflicts, "word_type", change.WordType, current.WordType)
+	conflicts = appendStringConflict(conflicts, "pronunciation", change.Pronunciation, current.Pronunciation)
+	conflicts = appendTagsConflict(conflicts, change.Tags, current.Tags)
+	return conflicts
+}
+
+func appendStringConflict(conflicts []FieldConflict, field string, client *string, server string) []FieldConflict {
+	if client == nil || *client == server {
+		return conflicts
+	}
+	return append(conflicts, FieldConflict{Field: field, ClientValue: *client, ServerValue: server})
+}
+
+func appendTagsConflict(conflicts []FieldConflict, client *[]string, server []string) []FieldConflict {
+
+	if client == nil {
+		return conflicts
+	}
+
+	normalized := helper.NormalizeTags(*client)
+	if len(normalized) == len(server) {
+		equal := true
+		for i := range normalized {
+			if normalized[i] != server[i] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return conflicts
+		}
+	}
+
+	return append(conflicts, FieldConflict{Field: "tags", ClientValue: normalized, ServerValue: server})
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func tagsValue(tags *[]string) []string {
+	if tags == nil {
+		return nil
+	}
+	return *tags
+}
@@ -0,0 +1,149 @@
+package clientsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flashcard/internal/topics"
+	"flashcard/internal/words"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryTopicService serves the topics a user can see. Sync only lists them
+// when the request carries no topic changes.
+type memoryTopicService struct {
+	topics.TopicService
+	accessible []*topics.TopicResponse
+}
+
+func (s *memoryTopicService) GetAccessibleTopics(c context.Context, userID string) ([]*topics.TopicResponse, error) {
+	return s.accessible, nil
+}
+
+type memoryWordService struct {
+	words.WordService
+	words []*words.Word
+}
+
+func (s *memoryWordService) GetWordsUpdatedSince(c context.Context, userID primitive.ObjectID, topicIDs []primitive.ObjectID, since time.Time) ([]*words.Word, error) {
+
+	inTopics := make(map[primitive.ObjectID]bool, len(topicIDs))
+	for _, topicID := range topicIDs {
+		inTopics[topicID] = true
+	}
+
+	var changed []*words.Word
+	for _, word := range s.words {
+		if inTopics[word.TopicID] && word.UpdatedAt.After(since) {
+			changed = append(changed, word)
+		}
+	}
+	return changed, nil
+}
+
+type memoryTombstoneRepository struct {
+	TombstoneRepository
+	tombstones []*Tombstone
+}
+
+func (r *memoryTombstoneRepository) GetTombstones(c context.Context, topicIDs []primitive.ObjectID, userID primitive.ObjectID, since time.Time) ([]*Tombstone, error) {
+
+	inTopics := make(map[primitive.ObjectID]bool, len(topicIDs))
+	for _, topicID := range topicIDs {
+		inTopics[topicID] = true
+	}
+
+	var found []*Tombstone
+	for _, tombstone := range r.tombstones {
+		if !tombstone.DeletedAt.After(since) {
+			continue
+		}
+		switch tombstone.Kind {
+		case KindWord:
+			if inTopics[tombstone.TopicID] {
+				found = append(found, tombstone)
+			}
+		case KindTopic:
+			for _, id := range tombstone.UserIDs {
+				if id == userID {
+					found = append(found, tombstone)
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+func TestSyncPullsSharedTopics(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	topicID := primitive.NewObjectID()
+	wordID := primitive.NewObjectID()
+
+	lastSync := time.Now().Add(-time.Hour)
+	before := lastSync.Add(-24 * time.Hour)
+	after := lastSync.Add(30 * time.Minute)
+
+	word := &words.Word{ID: wordID, TopicID: topicID, UpdatedAt: before}
+	removal := &Tombstone{Kind: KindTopic, DocumentID: topicID, TopicID: topicID, UserIDs: []primitive.ObjectID{userID}, DeletedAt: after}
+
+	cases := []struct {
+		name           string
+		topic          *topics.TopicResponse
+		tombstones     []*Tombstone
+		wantWords      int
+		wantTombstones int
+	}{
+		{
+			name:  "unchanged words of a topic the client already has are not resent",
+			topic: &topics.TopicResponse{ID: topicID, UpdatedAt: before, JoinedAt: &before},
+		},
+		{
+			name:      "a topic shared since the token brings all of its words",
+			topic:     &topics.TopicResponse{ID: topicID, UpdatedAt: after, JoinedAt: &after},
+			wantWords: 1,
+		},
+		{
+			name:           "a topic the user was removed from is tombstoned",
+			tombstones:     []*Tombstone{removal},
+			wantTombstones: 1,
+		},
+		{
+			name:       "a topic left and rejoined since the token is resent, not tombstoned",
+			topic:      &topics.TopicResponse{ID: topicID, UpdatedAt: after, JoinedAt: &after},
+			tombstones: []*Tombstone{removal},
+			wantWords:  1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			topicService := &memoryTopicService{}
+			if tc.topic != nil {
+				topicService.accessible = []*topics.TopicResponse{tc.topic}
+			}
+
+			service := NewSyncService(
+				topicService,
+				&memoryWordService{words: []*words.Word{word}},
+				&memoryTombstoneRepository{tombstones: tc.tombstones},
+				nil,
+			)
+
+			resp, err := service.Sync(context.Background(), userID.Hex(), &SyncRequest{SyncToken: encodeToken(lastSync)})
+			if err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+
+			if len(resp.Words) != tc.wantWords {
+				t.Errorf("got %d words, want %d", len(resp.Words), tc.wantWords)
+			}
+			if len(resp.Tombstones) != tc.wantTombstones {
+				t.Errorf("got %d tombstones, want %d", len(resp.Tombstones), tc.wantTombstones)
+			}
+		})
+	}
+}
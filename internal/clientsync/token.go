@@ -0,0 +1,47 @@
+package clientsync
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tokenPrefix = "v1:"
+
+// syncOverlap widens every pull a little into the past, so that writes that
+// were in flight when the previous token was issued are not missed. Clients
+// may therefore receive a document they already have and should compare
+// versions.
+const syncOverlap = 5 * time.Second
+
+func encodeToken(issuedAt time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(tokenPrefix + strconv.FormatInt(issuedAt.UnixNano(), 10)))
+}
+
+// decodeToken returns the zero time for an empty token, which makes the
+// first sync a full download.
+func decodeToken(token string) (time.Time, error) {
+
+	if token == "" {
+		return time.Time{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid sync token")
+	}
+
+	value, ok := strings.CutPrefix(string(raw), tokenPrefix)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid sync token")
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid sync token")
+	}
+
+	return time.Unix(0, nanos).Add(-syncOverlap), nil
+}
@@ -0,0 +1,34 @@
+package dictionary
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DictionaryHandler struct {
+	DictionaryService DictionaryService
+}
+
+func NewDictionaryHandler(dictionaryService DictionaryService) *DictionaryHandler {
+	return &DictionaryHandler{DictionaryService: dictionaryService}
+}
+
+func (h *DictionaryHandler) Lookup(c *gin.Context) {
+
+	var req LookupRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	entry, err := h.DictionaryService.Lookup(c, req.Word, req.Language)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", entry)
+
+}
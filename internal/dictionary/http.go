@@ -0,0 +1,136 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type httpProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPProvider queries a dictionary API with the response format of
+// dictionaryapi.dev, at <baseURL>/<language>/<word>.
+func NewHTTPProvider(baseURL string, timeout time.Duration) DictionaryProvider {
+	return &httpProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type apiEntry struct {
+	Word      string `json:"word"`
+	Phonetic  string `json:"phonetic"`
+	Phonetics []struct {
+		Text string `json:"text"`
+	} `json:"phonetics"`
+	Meanings []struct {
+		PartOfSpeech string `json:"partOfSpeech"`
+		Definitions  []struct {
+			Definition string   `json:"definition"`
+			Example    string   `json:"example"`
+			Synonyms   []string `json:"synonyms"`
+			Antonyms   []string `json:"antonyms"`
+		} `json:"definitions"`
+		Synonyms []string `json:"synonyms"`
+		Antonyms []string `json:"antonyms"`
+	} `json:"meanings"`
+}
+
+func (p *httpProvider) Lookup(c context.Context, word string, language string) (*Entry, error) {
+
+	endpoint := p.baseURL + "/" + url.PathEscape(language) + "/" + url.PathEscape(lookupKey(word))
+
+	req, err := http.NewRequestWithContext(c, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("dictionary lookup returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var results []apiEntry
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode dictionary response: %w", err)
+	}
+
+	return mergeAPIEntries(results, language), nil
+}
+
+// mergeAPIEntries folds the homographs the API returns into one entry.
+func mergeAPIEntries(results []apiEntry, language string) *Entry {
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	entry := &Entry{
+		Word:      results[0].Word,
+		Key:       lookupKey(results[0].Word),
+		Language:  language,
+		Senses:    []Sense{},
+		Synonyms:  []string{},
+		Antonyms:  []string{},
+		Source:    SourceHTTP,
+		UpdatedAt: time.Now(),
+	}
+
+	for _, result := range results {
+		if entry.Pronunciation == "" {
+			entry.Pronunciation = result.Phonetic
+		}
+		for _, phonetic := range result.Phonetics {
+			if entry.Pronunciation == "" && phonetic.Text != "" {
+				entry.Pronunciation = phonetic.Text
+			}
+		}
+
+		for _, meaning := range result.Meanings {
+			entry.Synonyms = append(entry.Synonyms, meaning.Synonyms...)
+			entry.Antonyms = append(entry.Antonyms, meaning.Antonyms...)
+			for _, definition := range meaning.Definitions {
+				sense := Sense{
+					PartOfSpeech: strings.ToLower(meaning.PartOfSpeech),
+					Definition:   strings.TrimSpace(definition.Definition),
+					Examples:     []string{},
+				}
+				if example := strings.TrimSpace(definition.Example); example != "" {
+					sense.Examples = append(sense.Examples, example)
+				}
+				entry.Senses = append(entry.Senses, sense)
+				entry.Synonyms = append(entry.Synonyms, definition.Synonyms...)
+				entry.Antonyms = append(entry.Antonyms, definition.Antonyms...)
+			}
+		}
+	}
+
+	if len(entry.Senses) == 0 {
+		return nil
+	}
+
+	return entry
+}
@@ -0,0 +1,49 @@
+package dictionary
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SourceOffline = "offline"
+	SourceHTTP    = "http"
+)
+
+// Entry is everything a provider knows about one headword in one language.
+// Senses keep the provider's part-of-speech labels as they are.
+type Entry struct {
+	ID            primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Word          string             `json:"word" bson:"word"`
+	Key           string             `json:"-" bson:"key"`
+	Language      string             `json:"language" bson:"language"`
+	Pronunciation string             `json:"pronunciation" bson:"pronunciation"`
+	Senses        []Sense            `json:"senses" bson:"senses"`
+	Synonyms      []string           `json:"synonyms" bson:"synonyms"`
+	Antonyms      []string           `json:"antonyms" bson:"antonyms"`
+	Source        string             `json:"source" bson:"source"`
+	UpdatedAt     time.Time          `json:"-" bson:"updated_at"`
+}
+
+type Sense struct {
+	PartOfSpeech string   `json:"part_of_speech" bson:"part_of_speech"`
+	Definition   string   `json:"definition" bson:"definition"`
+	Examples     []string `json:"examples" bson:"examples"`
+}
+
+// ImportRecord remembers which dictionary file was last imported so that a
+// restart does not load the same dump again.
+type ImportRecord struct {
+	Path       string    `json:"path" bson:"_id"`
+	Size       int64     `json:"size" bson:"size"`
+	ModTime    time.Time `json:"mod_time" bson:"mod_time"`
+	Entries    int       `json:"entries" bson:"entries"`
+	ImportedAt time.Time `json:"imported_at" bson:"imported_at"`
+}
+
+// lookupKey is the form headwords are stored and looked up under.
+func lookupKey(word string) string {
+	return strings.ToLower(strings.Join(strings.Fields(word), " "))
+}
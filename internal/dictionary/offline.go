@@ -0,0 +1,146 @@
+package dictionary
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const importBatchSize = 500
+
+type offlineProvider struct {
+	entryRepository EntryRepository
+}
+
+// NewOfflineProvider looks words up in the entries imported into Mongo.
+func NewOfflineProvider(entryRepository EntryRepository) DictionaryProvider {
+	return &offlineProvider{entryRepository: entryRepository}
+}
+
+func (p *offlineProvider) Lookup(c context.Context, word string, language string) (*Entry, error) {
+	return p.entryRepository.GetEntry(c, lookupKey(word), language)
+}
+
+// wiktextractLine is one line of a Wiktextract JSON Lines dump (as published
+// on kaikki.org): a headword in one language with one part of speech.
+type wiktextractLine struct {
+	Word     string `json:"word"`
+	LangCode string `json:"lang_code"`
+	Pos      string `json:"pos"`
+	Senses   []struct {
+		Glosses  []string `json:"glosses"`
+		Examples []struct {
+			Text string `json:"text"`
+		} `json:"examples"`
+	} `json:"senses"`
+	Sounds []struct {
+		IPA string `json:"ipa"`
+	} `json:"sounds"`
+	Synonyms []wiktextractLink `json:"synonyms"`
+	Antonyms []wiktextractLink `json:"antonyms"`
+}
+
+type wiktextractLink struct {
+	Word string `json:"word"`
+}
+
+// importWiktextract reads a Wiktextract dump and upserts its entries in
+// batches. Lines without a headword, language or gloss are skipped.
+func importWiktextract(c context.Context, entryRepository EntryRepository, r io.Reader) (int, error) {
+
+	reader := bufio.NewReaderSize(r, 1<<20)
+	batch := make([]*Entry, 0, importBatchSize)
+	imported := 0
+	now := time.Now()
+
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var parsed wiktextractLine
+			if err := json.Unmarshal(line, &parsed); err != nil {
+				return imported, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			if entry := parsed.entry(now); entry != nil {
+				batch = append(batch, entry)
+			}
+		}
+
+		if len(batch) == importBatchSize || (err != nil && len(batch) > 0) {
+			if err := entryRepository.UpsertEntries(c, batch); err != nil {
+				return imported, err
+			}
+			imported += len(batch)
+			batch = batch[:0]
+		}
+
+		if err == io.EOF {
+			return imported, nil
+		}
+		if err != nil {
+			return imported, err
+		}
+	}
+}
+
+func (l *wiktextractLine) entry(now time.Time) *Entry {
+
+	word := strings.TrimSpace(l.Word)
+	language := strings.ToLower(strings.TrimSpace(l.LangCode))
+	if word == "" || language == "" {
+		return nil
+	}
+
+	senses := make([]Sense, 0, len(l.Senses))
+	for _, sense := range l.Senses {
+		if len(sense.Glosses) == 0 {
+			continue
+		}
+		examples := make([]string, 0, len(sense.Examples))
+		for _, example := range sense.Examples {
+			if text := strings.TrimSpace(example.Text); text != "" {
+				examples = append(examples, text)
+			}
+		}
+		senses = append(senses, Sense{
+			PartOfSpeech: strings.ToLower(strings.TrimSpace(l.Pos)),
+			Definition:   strings.TrimSpace(sense.Glosses[len(sense.Glosses)-1]),
+			Examples:     examples,
+		})
+	}
+	if len(senses) == 0 {
+		return nil
+	}
+
+	entry := &Entry{
+		Word:      word,
+		Key:       lookupKey(word),
+		Language:  language,
+		Senses:    senses,
+		Synonyms:  linkWords(l.Synonyms),
+		Antonyms:  linkWords(l.Antonyms),
+		Source:    SourceOffline,
+		UpdatedAt: now,
+	}
+	for _, sound := range l.Sounds {
+		if sound.IPA != "" {
+			entry.Pronunciation = sound.IPA
+			break
+		}
+	}
+
+	return entry
+}
+
+func linkWords(links []wiktextractLink) []string {
+	words := make([]string, 0, len(links))
+	for _, link := range links {
+		if word := strings.TrimSpace(link.Word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
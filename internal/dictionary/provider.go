@@ -0,0 +1,39 @@
+package dictionary
+
+import (
+	"context"
+)
+
+// DictionaryProvider looks up a headword in a language. A word the provider
+// does not know is not an error: it returns a nil entry.
+type DictionaryProvider interface {
+	Lookup(c context.Context, word string, language string) (*Entry, error)
+}
+
+type chainProvider struct {
+	providers []DictionaryProvider
+}
+
+// NewChainProvider asks each provider in turn and returns the first entry
+// found. A failing provider does not hide the ones after it; its error is
+// only returned when none of them has the word.
+func NewChainProvider(providers ...DictionaryProvider) DictionaryProvider {
+	return &chainProvider{providers: providers}
+}
+
+func (p *chainProvider) Lookup(c context.Context, word string, language string) (*Entry, error) {
+
+	var lastErr error
+	for _, provider := range p.providers {
+		entry, err := provider.Lookup(c, word, language)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if entry != nil {
+			return entry, nil
+		}
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,130 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EntryRepository interface {
+	GetEntry(c context.Context, key string, language string) (*Entry, error)
+	UpsertEntries(c context.Context, entries []*Entry) error
+}
+
+type entryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewEntryRepository(collection *mongo.Collection) EntryRepository {
+	return &entryRepository{collection: collection}
+}
+
+func (r *entryRepository) GetEntry(c context.Context, key string, language string) (*Entry, error) {
+
+	var entry Entry
+
+	err := r.collection.FindOne(c, bson.M{"key": key, "language": language}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// UpsertEntries merges entries into the stored ones by key and language.
+// Senses and related words are added as sets, so importing the same dump
+// twice does not duplicate them.
+func (r *entryRepository) UpsertEntries(c context.Context, entries []*Entry) error {
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(entries))
+	for _, entry := range entries {
+		set := bson.M{"word": entry.Word, "source": entry.Source, "updated_at": entry.UpdatedAt}
+		if entry.Pronunciation != "" {
+			set["pronunciation"] = entry.Pronunciation
+		}
+
+		update := bson.M{
+			"$set": set,
+			"$addToSet": bson.M{
+				"senses":   bson.M{"$each": nonNilSenses(entry.Senses)},
+				"synonyms": bson.M{"$each": nonNilStrings(entry.Synonyms)},
+				"antonyms": bson.M{"$each": nonNilStrings(entry.Antonyms)},
+			},
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"key": entry.Key, "language": entry.Language}).
+			SetUpdate(update).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(c, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type ImportRepository interface {
+	GetImport(c context.Context, path string) (*ImportRecord, error)
+	SaveImport(c context.Context, record *ImportRecord) error
+}
+
+type importRepository struct {
+	collection *mongo.Collection
+}
+
+func NewImportRepository(collection *mongo.Collection) ImportRepository {
+	return &importRepository{collection: collection}
+}
+
+func (r *importRepository) GetImport(c context.Context, path string) (*ImportRecord, error) {
+
+	var record ImportRecord
+
+	err := r.collection.FindOne(c, bson.M{"_id": path}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (r *importRepository) SaveImport(c context.Context, record *ImportRecord) error {
+
+	record.ImportedAt = time.Now()
+
+	_, err := r.collection.ReplaceOne(c, bson.M{"_id": record.Path}, record, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func nonNilSenses(senses []Sense) []Sense {
+	if senses == nil {
+		return []Sense{}
+	}
+	return senses
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,6 @@
+package dictionary
+
+type LookupRequest struct {
+	Word     string `form:"word" json:"word"`
+	Language string `form:"language" json:"language"`
+}
@@ -0,0 +1,16 @@
+package dictionary
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *DictionaryHandler) {
+
+	dictionaryGroup := r.Group("/api/v1/dictionary")
+	{
+		dictionaryGroup.GET("/lookup", middleware.JWTAuthMiddleware(), handler.Lookup)
+	}
+
+}
@@ -0,0 +1,101 @@
+package dictionary
+
+import (
+	"context"
+	"flashcard/helper"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxLookupLength = 100
+
+type DictionaryService interface {
+	Lookup(c context.Context, word string, language string) (*Entry, error)
+	ImportFile(c context.Context, path string) (int, error)
+}
+
+type dictionaryService struct {
+	provider         DictionaryProvider
+	entryRepository  EntryRepository
+	importRepository ImportRepository
+	defaultLanguage  string
+}
+
+func NewDictionaryService(provider DictionaryProvider, entryRepository EntryRepository, importRepository ImportRepository, defaultLanguage string) DictionaryService {
+	return &dictionaryService{
+		provider:         provider,
+		entryRepository:  entryRepository,
+		importRepository: importRepository,
+		defaultLanguage:  defaultLanguage,
+	}
+}
+
+// Lookup finds a word in the configured providers. An empty language falls
+// back to the default dictionary language.
+func (s *dictionaryService) Lookup(c context.Context, word string, language string) (*Entry, error) {
+
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil, fmt.Errorf("word is required")
+	}
+
+	if utf8.RuneCountInString(word) > maxLookupLength {
+		return nil, fmt.Errorf("word must be at most %d characters", maxLookupLength)
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		language = s.defaultLanguage
+	}
+
+	entry, err := s.provider.Lookup(c, word, language)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, fmt.Errorf("%w: no dictionary entry for %q", helper.ErrResourceNotFound, word)
+	}
+
+	return entry, nil
+}
+
+// ImportFile loads a Wiktextract dump into the offline dictionary. A file
+// that was already imported unchanged is skipped and reports 0 entries.
+func (s *dictionaryService) ImportFile(c context.Context, path string) (int, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	previous, err := s.importRepository.GetImport(c, path)
+	if err != nil {
+		return 0, err
+	}
+
+	if previous != nil && previous.Size == info.Size() && previous.ModTime.Equal(info.ModTime().UTC().Truncate(time.Millisecond)) {
+		return 0, nil
+	}
+
+	imported, err := importWiktextract(c, s.entryRepository, file)
+	if err != nil {
+		return imported, fmt.Errorf("failed to import %s: %w", path, err)
+	}
+
+	return imported, s.importRepository.SaveImport(c, &ImportRecord{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UTC().Truncate(time.Millisecond),
+		Entries: imported,
+	})
+}
@@ -0,0 +1,255 @@
+package extraction
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	// maxEPUBEntryBytes bounds how much of a single file in the archive is
+	// decompressed.
+	maxEPUBEntryBytes = 16 << 20
+
+	// maxEPUBBookBytes bounds how much is decompressed for the whole book.
+	maxEPUBBookBytes = 64 << 20
+)
+
+// Book is the readable text of an EPUB, one segment per chapter.
+type Book struct {
+	Title    string
+	Segments []Segment
+}
+
+// epubArchive reads files of an EPUB within a decompression budget shared by
+// the whole book.
+type epubArchive struct {
+	files     map[string]*zip.File
+	remaining int
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Title    []string `xml:"metadata>title"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// ParseEPUB reads the chapters of an EPUB in reading order. Each chapter is
+// referenced as "Chapter N", followed by its first heading when it has one.
+func ParseEPUB(data []byte) (*Book, error) {
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an EPUB file: %w", err)
+	}
+
+	epub := &epubArchive{files: make(map[string]*zip.File, len(archive.File)), remaining: maxEPUBBookBytes}
+	for _, file := range archive.File {
+		epub.files[file.Name] = file
+	}
+
+	var container epubContainer
+	if err := epub.decode("META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("EPUB has no package document")
+	}
+
+	packagePath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := epub.decode(packagePath, &pkg); err != nil {
+		return nil, err
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		if strings.Contains(item.MediaType, "html") {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	book := &Book{}
+	if len(pkg.Title) > 0 {
+		book.Title = strings.TrimSpace(pkg.Title[0])
+	}
+
+	// A spine may list a document more than once; it is read only the first
+	// time.
+	read := make(map[string]bool, len(pkg.Spine))
+	chapter := 0
+	for _, itemRef := range pkg.Spine {
+		href, ok := hrefs[itemRef.IDRef]
+		if !ok {
+			continue
+		}
+
+		name, err := url.PathUnescape(href)
+		if err != nil {
+			name = href
+		}
+		name = path.Join(path.Dir(packagePath), name)
+		if read[name] {
+			continue
+		}
+		read[name] = true
+
+		content, err := epub.read(name)
+		if err != nil {
+			return nil, err
+		}
+
+		text, heading := htmlText(content)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		chapter++
+		reference := fmt.Sprintf("Chapter %d", chapter)
+		if heading != "" {
+			reference += ": " + heading
+		}
+		book.Segments = append(book.Segments, Segment{Text: text, Reference: reference})
+	}
+
+	if len(book.Segments) == 0 {
+		return nil, fmt.Errorf("EPUB has no readable chapters")
+	}
+
+	return book, nil
+}
+
+func (a *epubArchive) decode(name string, target interface{}) error {
+
+	content, err := a.read(name)
+	if err != nil {
+		return err
+	}
+
+	if err := xml.Unmarshal(content, target); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (a *epubArchive) read(name string) ([]byte, error) {
+
+	file, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("EPUB is missing %s", name)
+	}
+
+	limit := min(maxEPUBEntryBytes, a.remaining)
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) > limit {
+		if limit < maxEPUBEntryBytes {
+			return nil, fmt.Errorf("EPUB is larger than %d bytes uncompressed", maxEPUBBookBytes)
+		}
+		return nil, fmt.Errorf("%s is larger than %d bytes", name, maxEPUBEntryBytes)
+	}
+
+	a.remaining -= len(content)
+	return content, nil
+}
+
+var (
+	blockElements = map[string]bool{
+		"address": true, "article": true, "aside": true, "blockquote": true,
+		"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "footer": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+		"hr": true, "li": true, "ol": true, "p": true, "pre": true, "section": true,
+		"table": true, "td": true, "th": true, "tr": true, "ul": true,
+	}
+	skippedElements = map[string]bool{"head": true, "script": true, "style": true}
+	headingElements = map[string]bool{"h1": true, "h2": true, "h3": true}
+)
+
+// htmlText returns the visible text of an XHTML document with blocks
+// separated by blank lines, and the text of its first heading.
+func htmlText(content []byte) (string, string) {
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var text, heading strings.Builder
+	skipping, inHeading := 0, 0
+	headingDone := false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skippedElements[name] {
+				skipping++
+			}
+			if headingElements[name] && !headingDone {
+				inHeading++
+			}
+			if blockElements[name] {
+				text.WriteString("\n\n")
+			} else if name == "br" {
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if skippedElements[name] && skipping > 0 {
+				skipping--
+			}
+			if headingElements[name] && inHeading > 0 {
+				inHeading--
+				if inHeading == 0 && strings.TrimSpace(heading.String()) != "" {
+					headingDone = true
+				}
+			}
+			if blockElements[name] {
+				text.WriteString("\n\n")
+			}
+		case xml.CharData:
+			if skipping > 0 {
+				continue
+			}
+			text.Write(t)
+			if inHeading > 0 {
+				heading.Write(t)
+			}
+		}
+	}
+
+	return text.String(), strings.Join(strings.Fields(heading.String()), " ")
+}
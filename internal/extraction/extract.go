@@ -0,0 +1,223 @@
+package extraction
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultMinLength   = 3
+	maxSentenceLength  = 300
+	sentenceTerminator = ".!?…。！？"
+)
+
+var blankLine = regexp.MustCompile(`\r?\n[ \t]*\r?\n`)
+
+// Segment is a piece of source text, such as a subtitle cue or a chapter,
+// with a reference back to where it came from.
+type Segment struct {
+	Text      string
+	Reference string
+}
+
+// Candidate is a word worth learning from the source, with the first
+// sentence it appeared in.
+type Candidate struct {
+	Word      string
+	Frequency int
+	Sentence  string
+	Reference string
+}
+
+type Options struct {
+	// Language selects the stopword list; an unknown language filters none.
+	Language string
+	// Known words are skipped; keys must be passed through Normalize.
+	Known map[string]bool
+	// MinLength is the shortest word, in characters, that is suggested.
+	MinLength int
+	// Limit caps the number of candidates; 0 returns all of them.
+	Limit int
+}
+
+type tally struct {
+	candidate   *Candidate
+	order       int
+	lowercase   bool
+	capitalized bool
+}
+
+// Extract tokenizes the segments and returns the words that are neither
+// stopwords nor known, most frequent first, along with the total number of
+// tokens read. Words that only ever appear capitalized in the middle of a
+// sentence are taken to be names and skipped, except in languages that
+// capitalize nouns.
+func Extract(segments []Segment, opts Options) ([]*Candidate, int) {
+
+	if opts.MinLength < 1 {
+		opts.MinLength = defaultMinLength
+	}
+
+	language := strings.ToLower(opts.Language)
+	stopwords := stopwordsByLanguage[baseLanguage(language)]
+	detectNames := !capitalizesNouns[baseLanguage(language)]
+
+	tallies := make(map[string]*tally)
+	tokens := 0
+	for _, segment := range segments {
+		for _, sentence := range SplitSentences(segment.Text) {
+			for i, token := range Tokenize(sentence) {
+				tokens++
+
+				word := Normalize(token)
+				if utf8.RuneCountInString(word) < opts.MinLength || stopwords[word] || opts.Known[word] {
+					continue
+				}
+
+				t, ok := tallies[word]
+				if !ok {
+					t = &tally{
+						candidate: &Candidate{Word: word, Sentence: clip(sentence), Reference: segment.Reference},
+						order:     len(tallies),
+					}
+					tallies[word] = t
+				}
+				t.candidate.Frequency++
+
+				first, _ := utf8.DecodeRuneInString(token)
+				if !unicode.IsUpper(first) {
+					t.lowercase = true
+				} else if i > 0 {
+					t.capitalized = true
+				}
+			}
+		}
+	}
+
+	ranked := make([]*tally, 0, len(tallies))
+	for _, t := range tallies {
+		if detectNames && t.capitalized && !t.lowercase {
+			continue
+		}
+		ranked = append(ranked, t)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].candidate.Frequency != ranked[j].candidate.Frequency {
+			return ranked[i].candidate.Frequency > ranked[j].candidate.Frequency
+		}
+		return ranked[i].order < ranked[j].order
+	})
+
+	if opts.Limit > 0 && len(ranked) > opts.Limit {
+		ranked = ranked[:opts.Limit]
+	}
+
+	candidates := make([]*Candidate, 0, len(ranked))
+	for _, t := range ranked {
+		candidates = append(candidates, t.candidate)
+	}
+
+	return candidates, tokens
+}
+
+// SplitSentences breaks text at sentence punctuation and blank lines. Single
+// line breaks are treated as spaces, since wrapped paragraphs and subtitle
+// cues often continue a sentence on the next line.
+func SplitSentences(text string) []string {
+
+	var sentences []string
+	var current strings.Builder
+
+	flush := func() {
+		if sentence := strings.Join(strings.Fields(current.String()), " "); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range blankLine.Split(text, -1) {
+		for _, r := range paragraph {
+			current.WriteRune(r)
+			if strings.ContainsRune(sentenceTerminator, r) {
+				flush()
+			}
+		}
+		flush()
+	}
+
+	return sentences
+}
+
+// Tokenize returns the words of a sentence in their original case. Words are
+// runs of letters and may contain apostrophes or hyphens between letters.
+func Tokenize(sentence string) []string {
+
+	var tokens []string
+	runes := []rune(sentence)
+	start := -1
+
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && isJoiner(r) && i+1 < len(runes) && unicode.IsLetter(runes[i+1]) {
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, string(runes[start:i]))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, string(runes[start:]))
+	}
+
+	return tokens
+}
+
+// Normalize lowercases a token and strips elided articles ("l'homme") and
+// the English possessive ("teacher's"), so it can be compared with the words
+// a user already has.
+func Normalize(token string) string {
+
+	word := strings.ToLower(strings.TrimSpace(token))
+	word = strings.ReplaceAll(word, "’", "'")
+
+	if strings.HasSuffix(word, "'s") {
+		word = strings.TrimSuffix(word, "'s")
+	}
+
+	if index := strings.IndexRune(word, '\''); index > 0 && utf8.RuneCountInString(word[:index]) <= 2 {
+		word = word[index+1:]
+	}
+
+	return word
+}
+
+func isJoiner(r rune) bool {
+	return r == '\'' || r == '’' || r == '-'
+}
+
+func baseLanguage(language string) string {
+	if index := strings.IndexAny(language, "-_"); index > 0 {
+		return language[:index]
+	}
+	return language
+}
+
+func clip(sentence string) string {
+
+	if utf8.RuneCountInString(sentence) <= maxSentenceLength {
+		return sentence
+	}
+
+	runes := []rune(sentence)
+	return strings.TrimSpace(string(runes[:maxSentenceLength])) + "…"
+}
@@ -0,0 +1,67 @@
+package extraction
+
+import (
+	"strings"
+)
+
+// capitalizesNouns lists languages where a capitalized word in the middle of
+// a sentence is not a hint that it is a name.
+var capitalizesNouns = map[string]bool{
+	"de": true,
+}
+
+var stopwordsByLanguage = map[string]map[string]bool{
+	"en": wordSet(`
+		a about above after again against all also am an and any are aren't as at
+		be because been before being below between both but by can can't cannot
+		could couldn't did didn't do does doesn't doing don't down during each few
+		for from further get got had hadn't has hasn't have haven't having he he'd
+		he'll her here here's hers herself him himself his how how's i i'd i'll
+		i'm i've if in into is isn't it it's its itself just let's like me more most
+		much mustn't my myself no nor not now of off on once one only or other ought
+		our ours ourselves out over own same shan't she she'd she'll she's should
+		shouldn't so some such than that that's the their theirs them themselves
+		then there there's these they they'd they'll they're they've this those
+		through to too under until up upon us very was wasn't we we'd we'll we're
+		we've were weren't what what's when when's where where's which while who
+		who's whom why why's will with won't would wouldn't yes yet you you'd
+		you'll you're you've your yours yourself yourselves`),
+	"es": wordSet(`
+		a al algo algunos ante antes como con contra cual cuando de del desde donde
+		durante e el ella ellas ellos en entre era eres es esa ese eso esta estaba
+		estas este esto estos fue ha hay hasta la las le les lo los mas me mi mis
+		mucho muy más mí ni no nos nosotros o os otra otro para pero poco por porque
+		que quien qué se sea ser si sin sobre son su sus también te tiene tu tus tú
+		un una uno unos vosotros y ya yo él`),
+	"fr": wordSet(`
+		a au aux avec avait avoir c ce ces cet cette comme d dans de des du elle
+		elles en est et était être eu il ils je la le les leur leurs lui ma mais me
+		mes moi mon même n ne nos notre nous on ont ou où par pas plus pour qu que
+		qui s sa sans se ses si son sont sur ta te tes toi ton tu un une vos votre
+		vous y à été`),
+	"de": wordSet(`
+		aber alle als also am an auch auf aus bei bin bis bist da damit das dass
+		dein deine dem den der des dich die dir doch du durch ein eine einem einen
+		einer eines er es für hab habe haben hat hatte ich ihm ihn ihr ihre im in
+		ist ja jetzt kann kein keine man mein meine mich mir mit nach nicht noch nun
+		nur ob oder ohne schon sein seine sich sie sind so über um und uns unser von
+		vor war waren was wenn wer wie wir wird wo zu zum zur`),
+	"it": wordSet(`
+		a ad al alla alle anche che chi ci come con da dal dalla dei del della delle
+		di e ed era essere gli ha hanno ho i il in io la le lei lo loro lui ma mi mia
+		mio ne nei nel nella noi non o per perché più quando quella quello questa
+		questo se si sono su sua suo tra tu un una uno voi è`),
+	"pt": wordSet(`
+		a ao aos as com como da das de dele dela do dos e ela elas ele eles em entre
+		era essa esse esta este eu foi há isso isto já lhe mais mas me meu minha muito
+		na nas nem no nos nós o os ou para pela pelo por que quando se sem ser seu
+		sua são também te tem um uma você à é`),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,190 @@
+package extraction
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxCuesPerSentence bounds how many subtitle cues are joined while looking
+// for the end of a sentence.
+const maxCuesPerSentence = 6
+
+var (
+	markupTag    = regexp.MustCompile(`<[^>]*>`)
+	assOverride  = regexp.MustCompile(`\{\\[^}]*\}`)
+	cueTimestamp = regexp.MustCompile(`^(?:(\d+):)?(\d{1,2}):(\d{2})[,.](\d{1,3})$`)
+)
+
+// ParseSRT reads SubRip subtitles. Cues are joined into whole sentences and
+// each segment is referenced by the start time of its first cue.
+func ParseSRT(r io.Reader) ([]Segment, error) {
+	return parseSubtitles(r, false)
+}
+
+// ParseVTT reads WebVTT subtitles, skipping NOTE, STYLE and REGION blocks.
+func ParseVTT(r io.Reader) ([]Segment, error) {
+	return parseSubtitles(r, true)
+}
+
+func parseSubtitles(r io.Reader, webVTT bool) ([]Segment, error) {
+
+	blocks, err := readBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if webVTT {
+		if len(blocks) == 0 || !strings.HasPrefix(blocks[0][0], "WEBVTT") {
+			return nil, fmt.Errorf("not a WebVTT file")
+		}
+		blocks = blocks[1:]
+	}
+
+	var cues []Segment
+	for _, block := range blocks {
+		if webVTT && isVTTMetadata(block[0]) {
+			continue
+		}
+
+		timing := -1
+		for i, line := range block {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue
+		}
+
+		start, err := parseCueTimestamp(strings.TrimSpace(strings.SplitN(block[timing], "-->", 2)[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		text := cleanCueText(block[timing+1:])
+		if text != "" {
+			cues = append(cues, Segment{Text: text, Reference: start})
+		}
+	}
+
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no subtitle cues found")
+	}
+
+	return joinCues(cues), nil
+}
+
+// readBlocks splits the input into groups of non-empty lines.
+func readBlocks(r io.Reader) ([][]string, error) {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var blocks [][]string
+	var current []string
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+
+	return blocks, scanner.Err()
+}
+
+func isVTTMetadata(line string) bool {
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if line == keyword || strings.HasPrefix(line, keyword+" ") || strings.HasPrefix(line, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCueTimestamp turns "01:02:03,450" or "02:03.450" into "01:02:03".
+func parseCueTimestamp(value string) (string, error) {
+
+	match := cueTimestamp.FindStringSubmatch(value)
+	if match == nil {
+		return "", fmt.Errorf("invalid cue timestamp %q", value)
+	}
+
+	hours := 0
+	if match[1] != "" {
+		hours, _ = strconv.Atoi(match[1])
+	}
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds), nil
+}
+
+// cleanCueText drops formatting tags and joins the cue's lines.
+func cleanCueText(lines []string) string {
+
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = markupTag.ReplaceAllString(line, "")
+		line = assOverride.ReplaceAllString(line, "")
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-"))
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// joinCues merges consecutive cues until one ends a sentence, so that a line
+// of dialogue split across cues is kept together.
+func joinCues(cues []Segment) []Segment {
+
+	var segments []Segment
+	var pending []string
+	reference := ""
+
+	for _, cue := range cues {
+		if len(pending) == 0 {
+			reference = cue.Reference
+		}
+		pending = append(pending, cue.Text)
+
+		last, _ := lastRune(cue.Text)
+		if strings.ContainsRune(sentenceTerminator, last) || len(pending) >= maxCuesPerSentence {
+			segments = append(segments, Segment{Text: strings.Join(pending, " "), Reference: reference})
+			pending = nil
+		}
+	}
+	if len(pending) > 0 {
+		segments = append(segments, Segment{Text: strings.Join(pending, " "), Reference: reference})
+	}
+
+	return segments
+}
+
+func lastRune(text string) (rune, bool) {
+	text = strings.TrimRight(text, ` "')]»”’`)
+	if text == "" {
+		return 0, false
+	}
+	runes := []rune(text)
+	return runes[len(runes)-1], true
+}
@@ -0,0 +1,203 @@
+package grading
+
+const (
+	GradeCorrect   = "correct"
+	GradeClose     = "close"
+	GradeIncorrect = "incorrect"
+
+	DiffEqual      = "equal"
+	DiffInsert     = "insert"
+	DiffDelete     = "delete"
+	DiffSubstitute = "substitute"
+
+	// DefaultTolerance is the share of the expected answer's characters that
+	// may be wrong in an answer that is still accepted.
+	DefaultTolerance = 0.2
+
+	// MaxTolerance caps a client-supplied tolerance, so that an answer
+	// mostly unlike the expected one is never accepted.
+	MaxTolerance = 0.4
+
+	// maxDiffCells bounds the edit-distance matrix, which is about a
+	// thousand characters on each side. Longer answers must match exactly.
+	maxDiffCells = 1 << 20
+)
+
+type Options struct {
+	IgnoreAccents bool    `json:"ignore_accents" bson:"ignore_accents"`
+	StripArticles bool    `json:"strip_articles" bson:"strip_articles"`
+	Language      string  `json:"language" bson:"language"`
+	Tolerance     float64 `json:"tolerance" bson:"tolerance"`
+}
+
+// Result grades an answer. Diff walks the normalized answer and tells the
+// client which characters to keep, add, remove or replace to reach the
+// expected answer.
+type Result struct {
+	Grade              string        `json:"grade"`
+	Correct            bool          `json:"correct"`
+	Distance           int           `json:"distance"`
+	Similarity         float64       `json:"similarity"`
+	Answer             string        `json:"answer"`
+	Expected           string        `json:"expected"`
+	NormalizedAnswer   string        `json:"normalized_answer"`
+	NormalizedExpected string        `json:"normalized_expected"`
+	Diff               []DiffSegment `json:"diff"`
+}
+
+// DiffSegment is a run of one edit operation. Text holds the learner's
+// characters and Expected the correct ones; insertions only have Expected
+// and deletions only Text.
+type DiffSegment struct {
+	Op       string `json:"op"`
+	Text     string `json:"text,omitempty"`
+	Expected string `json:"expected,omitempty"`
+}
+
+// Grade compares a typed answer with the expected one using the normalized
+// Levenshtein distance. An exact match after normalization is correct, a
+// match within the tolerance is close and still counts as correct.
+func Grade(answer, expected string, opts Options) *Result {
+
+	if opts.Tolerance <= 0 || opts.Tolerance >= 1 {
+		opts.Tolerance = DefaultTolerance
+	}
+	opts.Tolerance = min(opts.Tolerance, MaxTolerance)
+
+	normalizedAnswer := normalize(answer, &opts)
+	normalizedExpected := normalize(expected, &opts)
+
+	answerRunes, expectedRunes := []rune(normalizedAnswer), []rune(normalizedExpected)
+	allowed := int(float64(len(expectedRunes)) * opts.Tolerance)
+
+	var diff []DiffSegment
+	var distance int
+	switch {
+	case (len(answerRunes)+1)*(len(expectedRunes)+1) <= maxDiffCells:
+		diff, distance = editScript(answerRunes, expectedRunes)
+	case normalizedAnswer == normalizedExpected:
+		diff = []DiffSegment{{Op: DiffEqual, Text: normalizedAnswer}}
+	default:
+		// Too long to align: the answer is graded incorrect without
+		// building the matrix.
+		diff, distance = replaceScript(answerRunes, expectedRunes)
+	}
+
+	result := &Result{
+		Answer:             answer,
+		Expected:           expected,
+		NormalizedAnswer:   normalizedAnswer,
+		NormalizedExpected: normalizedExpected,
+		Distance:           distance,
+		Diff:               diff,
+	}
+
+	length := max(len(answerRunes), len(expectedRunes))
+	result.Similarity = 1
+	if length > 0 {
+		result.Similarity = 1 - float64(distance)/float64(length)
+	}
+
+	switch {
+	case normalizedAnswer == "":
+		result.Grade = GradeIncorrect
+	case distance == 0:
+		result.Grade = GradeCorrect
+	case distance <= allowed:
+		result.Grade = GradeClose
+	default:
+		result.Grade = GradeIncorrect
+	}
+	result.Correct = result.Grade != GradeIncorrect
+
+	return result
+}
+
+// editScript computes the Levenshtein distance between answer and expected
+// and traces back one cheapest edit script, merging runs of the same
+// operation into segments.
+func editScript(answer, expected []rune) ([]DiffSegment, int) {
+
+	rows, cols := len(answer)+1, len(expected)+1
+	dist := make([][]int, rows)
+	for i := range dist {
+		dist[i] = make([]int, cols)
+		dist[i][0] = i
+	}
+	for j := 0; j < cols; j++ {
+		dist[0][j] = j
+	}
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			cost := 1
+			if answer[i-1] == expected[j-1] {
+				cost = 0
+			}
+			dist[i][j] = min(dist[i-1][j]+1, dist[i][j-1]+1, dist[i-1][j-1]+cost)
+		}
+	}
+
+	var reversed []DiffSegment
+	push := func(op string, text, want rune) {
+		var textPart, wantPart string
+		if text != 0 {
+			textPart = string(text)
+		}
+		if want != 0 {
+			wantPart = string(want)
+		}
+		if n := len(reversed); n > 0 && reversed[n-1].Op == op {
+			reversed[n-1].Text = textPart + reversed[n-1].Text
+			reversed[n-1].Expected = wantPart + reversed[n-1].Expected
+			return
+		}
+		reversed = append(reversed, DiffSegment{Op: op, Text: textPart, Expected: wantPart})
+	}
+
+	i, j := len(answer), len(expected)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && answer[i-1] == expected[j-1] && dist[i][j] == dist[i-1][j-1]:
+			push(DiffEqual, answer[i-1], 0)
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && dist[i][j] == dist[i-1][j-1]+1:
+			push(DiffSubstitute, answer[i-1], expected[j-1])
+			i, j = i-1, j-1
+		case i > 0 && dist[i][j] == dist[i-1][j]+1:
+			push(DiffDelete, answer[i-1], 0)
+			i--
+		default:
+			push(DiffInsert, 0, expected[j-1])
+			j--
+		}
+	}
+
+	diff := make([]DiffSegment, 0, len(reversed))
+	for k := len(reversed) - 1; k >= 0; k-- {
+		diff = append(diff, reversed[k])
+	}
+
+	return diff, dist[len(answer)][len(expected)]
+}
+
+// replaceScript is the edit script that substitutes the expected answer over
+// the start of the answer and inserts or deletes the rest. It is not the
+// cheapest in general but needs no matrix.
+func replaceScript(answer, expected []rune) ([]DiffSegment, int) {
+
+	shared := min(len(answer), len(expected))
+
+	var diff []DiffSegment
+	if shared > 0 {
+		diff = append(diff, DiffSegment{Op: DiffSubstitute, Text: string(answer[:shared]), Expected: string(expected[:shared])})
+	}
+	if len(answer) > shared {
+		diff = append(diff, DiffSegment{Op: DiffDelete, Text: string(answer[shared:])})
+	}
+	if len(expected) > shared {
+		diff = append(diff, DiffSegment{Op: DiffInsert, Expected: string(expected[shared:])})
+	}
+
+	return diff, max(len(answer), len(expected))
+}
@@ -0,0 +1,125 @@
+package grading
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGrade(t *testing.T) {
+
+	long := strings.Repeat("word ", 300)
+
+	cases := []struct {
+		name     string
+		answer   string
+		expected string
+		opts     Options
+		want     string
+	}{
+		{name: "exact", answer: "receive", expected: "receive", want: GradeCorrect},
+		{name: "case and spacing are ignored", answer: "  Give   UP ", expected: "give up", want: GradeCorrect},
+		{name: "one typo within tolerance", answer: "receve", expected: "receive", want: GradeClose},
+		{name: "too many typos", answer: "recieve", expected: "receive", want: GradeIncorrect},
+		{name: "empty answer", answer: "  ", expected: "receive", want: GradeIncorrect},
+		{name: "accents count by default", answer: "cafe", expected: "café", want: GradeIncorrect},
+		{name: "accents ignored on request", answer: "cafe", expected: "café", opts: Options{IgnoreAccents: true}, want: GradeCorrect},
+		{name: "composed and decomposed forms are equal", answer: "café", expected: "café", want: GradeCorrect},
+		{name: "articles count by default", answer: "the cat", expected: "cat", want: GradeIncorrect},
+		{name: "english article stripped", answer: "The cat", expected: "cat", opts: Options{StripArticles: true, Language: "en"}, want: GradeCorrect},
+		{name: "german article stripped", answer: "die Katze", expected: "Katze", opts: Options{StripArticles: true, Language: "de"}, want: GradeCorrect},
+		{name: "elided article with a typographic apostrophe", answer: "l’homme", expected: "homme", opts: Options{StripArticles: true, Language: "fr"}, want: GradeCorrect},
+		{name: "another language's article is kept", answer: "el gato", expected: "gato", opts: Options{StripArticles: true, Language: "en"}, want: GradeIncorrect},
+		{name: "unknown language tries every list", answer: "una casa", expected: "casa", opts: Options{StripArticles: true}, want: GradeCorrect},
+		{name: "an article alone is not stripped", answer: "the", expected: "the", opts: Options{StripArticles: true, Language: "en"}, want: GradeCorrect},
+		{name: "tolerance is capped", answer: "hxxxo", expected: "hello", opts: Options{Tolerance: 0.9}, want: GradeIncorrect},
+		{name: "a higher tolerance accepts more", answer: "hxxlo", expected: "hello", opts: Options{Tolerance: 0.4}, want: GradeClose},
+		{name: "long answers that match", answer: long, expected: long, want: GradeCorrect},
+		{name: "long answers that differ", answer: long + "x", expected: long + "y", want: GradeIncorrect},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Grade(tc.answer, tc.expected, tc.opts)
+			if result.Grade != tc.want {
+				t.Errorf("grade = %q (distance %d, %q vs %q), want %q", result.Grade, result.Distance, result.NormalizedAnswer, result.NormalizedExpected, tc.want)
+			}
+			if result.Correct != (tc.want != GradeIncorrect) {
+				t.Errorf("correct = %v for grade %q", result.Correct, result.Grade)
+			}
+		})
+	}
+}
+
+func TestStripArticleIsDeterministic(t *testing.T) {
+
+	cases := []struct {
+		text     string
+		language string
+		want     string
+	}{
+		{"les chats", "fr", "chats"},
+		{"l'eau", "", "eau"},
+		{"unos gatos", "", "gatos"},
+		{"eines tages", "", "tages"},
+		{"de kat", "nl", "kat"},
+		{"theater", "en", "theater"},
+	}
+
+	for _, tc := range cases {
+		for i := 0; i < 20; i++ {
+			if got := stripArticle(tc.text, tc.language); got != tc.want {
+				t.Fatalf("stripArticle(%q, %q) = %q, want %q", tc.text, tc.language, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestGradeDiff(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		answer   string
+		expected string
+		want     []DiffSegment
+	}{
+		{
+			name:     "missing letter",
+			answer:   "receve",
+			expected: "receive",
+			want: []DiffSegment{
+				{Op: DiffEqual, Text: "rece"},
+				{Op: DiffInsert, Expected: "i"},
+				{Op: DiffEqual, Text: "ve"},
+			},
+		},
+		{
+			name:     "extra letter",
+			answer:   "recceive",
+			expected: "receive",
+			want: []DiffSegment{
+				{Op: DiffEqual, Text: "re"},
+				{Op: DiffDelete, Text: "c"},
+				{Op: DiffEqual, Text: "ceive"},
+			},
+		},
+		{
+			name:     "wrong letter",
+			answer:   "recieve",
+			expected: "receive",
+			want: []DiffSegment{
+				{Op: DiffEqual, Text: "rec"},
+				{Op: DiffSubstitute, Text: "ie", Expected: "ei"},
+				{Op: DiffEqual, Text: "ve"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Grade(tc.answer, tc.expected, Options{}).Diff; !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("diff = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package grading
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GradingHandler struct {
+	GradingService GradingService
+}
+
+func NewGradingHandler(gradingService GradingService) *GradingHandler {
+	return &GradingHandler{GradingService: gradingService}
+}
+
+func (h *GradingHandler) GradeWord(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req GradeAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.GradingService.GradeWord(c, c.Param("word_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+}
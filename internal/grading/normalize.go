@@ -0,0 +1,115 @@
+package grading
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// languageArticles lists the leading articles stripped per language, in a
+// fixed order so that grading does not depend on map iteration. Answers for
+// words without a known language are checked against every list.
+var languageArticles = []struct {
+	language string
+	articles []string
+}{
+	{"en", []string{"the", "a", "an"}},
+	{"fr", []string{"le", "la", "les", "l'", "un", "une", "des"}},
+	{"de", []string{"der", "die", "das", "den", "dem", "des", "ein", "eine", "einen", "einem", "einer", "eines"}},
+	{"es", []string{"el", "la", "los", "las", "un", "una", "unos", "unas"}},
+	{"it", []string{"il", "lo", "la", "i", "gli", "le", "l'", "un", "uno", "una"}},
+	{"pt", []string{"o", "a", "os", "as", "um", "uma"}},
+	{"nl", []string{"de", "het", "een"}},
+}
+
+// articlesByLanguage and allArticles hold the lists above longest first, so
+// that the longest article an answer starts with is the one removed.
+var articlesByLanguage, allArticles = indexArticles()
+
+func indexArticles() (map[string][]string, []string) {
+
+	byLanguage := make(map[string][]string, len(languageArticles))
+	seen := make(map[string]bool)
+	var all []string
+	for _, list := range languageArticles {
+		byLanguage[list.language] = longestFirst(list.articles)
+		for _, article := range list.articles {
+			if !seen[article] {
+				seen[article] = true
+				all = append(all, article)
+			}
+		}
+	}
+
+	return byLanguage, longestFirst(all)
+}
+
+// longestFirst returns a copy of articles sorted by length, longest first,
+// keeping the given order between articles of the same length.
+func longestFirst(articles []string) []string {
+	sorted := slices.Clone(articles)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		return utf8.RuneCountInString(b) - utf8.RuneCountInString(a)
+	})
+	return sorted
+}
+
+// normalize puts text in canonical composed form, folds case and collapses
+// whitespace, then applies the optional accent and article rules.
+func normalize(text string, opts *Options) string {
+
+	text = norm.NFC.String(text)
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	text = strings.ReplaceAll(text, "’", "'")
+
+	if opts.StripArticles {
+		text = stripArticle(text, opts.Language)
+	}
+
+	if opts.IgnoreAccents {
+		text = stripAccents(text)
+	}
+
+	return text
+}
+
+// stripAccents decomposes the text and drops combining marks, so "é" and
+// "e" compare equal. Letters without a decomposition, such as "ß" or "ø",
+// are left as they are.
+func stripAccents(text string) string {
+
+	var out strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		out.WriteRune(r)
+	}
+
+	return norm.NFC.String(out.String())
+}
+
+func stripArticle(text, language string) string {
+
+	candidates, ok := articlesByLanguage[language]
+	if !ok {
+		candidates = allArticles
+	}
+
+	for _, article := range candidates {
+		if strings.HasSuffix(article, "'") {
+			if rest, ok := strings.CutPrefix(text, article); ok && rest != "" {
+				return strings.TrimSpace(rest)
+			}
+			continue
+		}
+		if rest, ok := strings.CutPrefix(text, article+" "); ok && rest != "" {
+			return rest
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,9 @@
+package grading
+
+type GradeAnswerRequest struct {
+	Answer        string  `json:"answer" bson:"answer"`
+	IgnoreAccents bool    `json:"ignore_accents" bson:"ignore_accents"`
+	StripArticles bool    `json:"strip_articles" bson:"strip_articles"`
+	Tolerance     float64 `json:"tolerance" bson:"tolerance"`
+	Record        bool    `json:"record" bson:"record"`
+}
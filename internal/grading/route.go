@@ -0,0 +1,16 @@
+package grading
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *GradingHandler) {
+
+	wordGradingGroup := r.Group("/api/v1/word/:word_id")
+	{
+		wordGradingGroup.POST("/grade", middleware.JWTAuthMiddleware(), handler.GradeWord)
+	}
+
+}
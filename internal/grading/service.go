@@ -0,0 +1,46 @@
+package grading
+
+import (
+	"context"
+
+	"flashcard/internal/words"
+)
+
+type GradingService interface {
+	GradeWord(c context.Context, wordID string, userID string, req *GradeAnswerRequest) (*Result, error)
+}
+
+type gradingService struct {
+	wordService words.WordService
+}
+
+func NewGradingService(wordService words.WordService) GradingService {
+	return &gradingService{wordService: wordService}
+}
+
+// GradeWord checks a typed answer against the word itself. Articles are
+// matched in the word's source language, and with Record set the result
+// counts as a review of the word.
+func (s *gradingService) GradeWord(c context.Context, wordID string, userID string, req *GradeAnswerRequest) (*Result, error) {
+
+	word, err := s.wordService.GetWordByID(c, wordID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Grade(req.Answer, word.Word, Options{
+		IgnoreAccents: req.IgnoreAccents,
+		StripArticles: req.StripArticles,
+		Language:      word.SourceLanguage,
+		Tolerance:     req.Tolerance,
+	})
+
+	if req.Record {
+		review := &words.ReviewWordRequest{Correct: result.Correct}
+		if err := s.wordService.ReviewWord(c, wordID, userID, review); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
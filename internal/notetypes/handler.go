@@ -0,0 +1,135 @@
+package notetypes
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NoteTypeHandler struct {
+	NoteTypeService NoteTypeService
+}
+
+func NewNoteTypeHandler(noteTypeService NoteTypeService) *NoteTypeHandler {
+	return &NoteTypeHandler{NoteTypeService: noteTypeService}
+}
+
+func (h *NoteTypeHandler) CreateNoteType(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req CreateNoteTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	noteType, err := h.NoteTypeService.CreateNoteType(c, &req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", noteType)
+}
+
+func (h *NoteTypeHandler) GetNoteTypes(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	noteTypes, err := h.NoteTypeService.GetNoteTypes(c, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", noteTypes)
+}
+
+func (h *NoteTypeHandler) GetNoteType(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	noteType, err := h.NoteTypeService.GetNoteType(c, c.Param("note_type_id"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", noteType)
+}
+
+func (h *NoteTypeHandler) UpdateNoteType(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req UpdateNoteTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	noteType, err := h.NoteTypeService.UpdateNoteType(c, c.Param("note_type_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", noteType)
+}
+
+func (h *NoteTypeHandler) DeleteNoteType(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	if err := h.NoteTypeService.DeleteNoteType(c, c.Param("note_type_id"), userID.(string)); err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *NoteTypeHandler) PreviewNoteType(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req PreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	cards, err := h.NoteTypeService.PreviewNoteType(c, c.Param("note_type_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", cards)
+}
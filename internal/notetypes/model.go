@@ -0,0 +1,56 @@
+package notetypes
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NoteType describes the fields a word stores and how its cards are laid
+// out. The first field is the sort field: it is always required and is
+// mirrored into the word's "word" column, the second into "definition".
+type NoteType struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name      string             `json:"name" bson:"name"`
+	Fields    []NoteField        `json:"fields" bson:"fields"`
+	Templates []CardTemplate     `json:"templates" bson:"templates"`
+	Builtin   bool               `json:"builtin" bson:"-"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type NoteField struct {
+	Name     string `json:"name" bson:"name"`
+	Required bool   `json:"required" bson:"required"`
+}
+
+// CardTemplate renders one card face pair with html/template. Fields are
+// referenced as {{.FieldName}}; the back template may also use
+// {{.FrontSide}} to repeat the rendered front.
+type CardTemplate struct {
+	Name  string `json:"name" bson:"name"`
+	Front string `json:"front" bson:"front"`
+	Back  string `json:"back" bson:"back"`
+}
+
+// DefaultNoteType lays out words that have no note type, using the fixed
+// word columns as its fields.
+func DefaultNoteType() *NoteType {
+	return &NoteType{
+		Name: "Vocabulary",
+		Fields: []NoteField{
+			{Name: "Word", Required: true},
+			{Name: "Definition", Required: true},
+			{Name: "Example"},
+			{Name: "Pronunciation"},
+			{Name: "PartOfSpeech"},
+		},
+		Templates: []CardTemplate{{
+			Name:  "Forward",
+			Front: `{{.Word}}{{if .Pronunciation}} <span class="pronunciation">/{{.Pronunciation}}/</span>{{end}}`,
+			Back:  `{{.FrontSide}}<hr>{{if .PartOfSpeech}}<i>{{.PartOfSpeech}}</i> {{end}}{{.Definition}}{{if .Example}}<br><q>{{.Example}}</q>{{end}}`,
+		}},
+		Builtin: true,
+	}
+}
@@ -0,0 +1,93 @@
+package notetypes
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type NoteTypeRepository interface {
+	CreateNoteType(c context.Context, noteType *NoteType) error
+	GetNoteTypeByID(c context.Context, id primitive.ObjectID) (*NoteType, error)
+	GetNoteTypesByUserID(c context.Context, userID primitive.ObjectID) ([]*NoteType, error)
+	UpdateNoteType(c context.Context, id primitive.ObjectID, fields bson.M) error
+	DeleteNoteType(c context.Context, id primitive.ObjectID) error
+	DeleteNoteTypesByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type noteTypeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNoteTypeRepository(collection *mongo.Collection) NoteTypeRepository {
+	return &noteTypeRepository{collection: collection}
+}
+
+func (r *noteTypeRepository) CreateNoteType(c context.Context, noteType *NoteType) error {
+	_, err := r.collection.InsertOne(c, noteType)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *noteTypeRepository) GetNoteTypeByID(c context.Context, id primitive.ObjectID) (*NoteType, error) {
+
+	var noteType NoteType
+
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&noteType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &noteType, nil
+
+}
+
+func (r *noteTypeRepository) GetNoteTypesByUserID(c context.Context, userID primitive.ObjectID) ([]*NoteType, error) {
+
+	var noteTypes []*NoteType
+
+	cursor, err := r.collection.Find(c, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var noteType NoteType
+		if err := cursor.Decode(&noteType); err != nil {
+			return nil, err
+		}
+		noteTypes = append(noteTypes, &noteType)
+	}
+
+	return noteTypes, nil
+
+}
+
+func (r *noteTypeRepository) UpdateNoteType(c context.Context, id primitive.ObjectID, fields bson.M) error {
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *noteTypeRepository) DeleteNoteType(c context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(c, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *noteTypeRepository) DeleteNoteTypesByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
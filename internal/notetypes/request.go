@@ -0,0 +1,17 @@
+package notetypes
+
+type CreateNoteTypeRequest struct {
+	Name      string         `json:"name" bson:"name"`
+	Fields    []NoteField    `json:"fields" bson:"fields"`
+	Templates []CardTemplate `json:"templates" bson:"templates"`
+}
+
+type UpdateNoteTypeRequest struct {
+	Name      *string         `json:"name" bson:"name"`
+	Fields    *[]NoteField    `json:"fields" bson:"fields"`
+	Templates *[]CardTemplate `json:"templates" bson:"templates"`
+}
+
+type PreviewRequest struct {
+	Fields map[string]string `json:"fields" bson:"fields"`
+}
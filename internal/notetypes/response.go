@@ -0,0 +1,7 @@
+package notetypes
+
+type RenderedCard struct {
+	Template string `json:"template"`
+	Front    string `json:"front"`
+	Back     string `json:"back"`
+}
@@ -0,0 +1,21 @@
+package notetypes
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *NoteTypeHandler) {
+
+	noteTypeGroup := r.Group("/api/v1/note-type")
+	{
+		noteTypeGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateNoteType)
+		noteTypeGroup.GET("", middleware.JWTAuthMiddleware(), handler.GetNoteTypes)
+		noteTypeGroup.GET("/:note_type_id", middleware.JWTAuthMiddleware(), handler.GetNoteType)
+		noteTypeGroup.PUT("/:note_type_id", middleware.JWTAuthMiddleware(), handler.UpdateNoteType)
+		noteTypeGroup.DELETE("/:note_type_id", middleware.JWTAuthMiddleware(), handler.DeleteNoteType)
+		noteTypeGroup.POST("/:note_type_id/preview", middleware.JWTAuthMiddleware(), handler.PreviewNoteType)
+	}
+
+}
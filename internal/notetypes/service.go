@@ -0,0 +1,260 @@
+package notetypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"flashcard/helper"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultNoteTypeID is the path id clients use for the built-in note type.
+const DefaultNoteTypeID = "default"
+
+type NoteTypeService interface {
+	CreateNoteType(c context.Context, req *CreateNoteTypeRequest, userID string) (*NoteType, error)
+	GetNoteTypes(c context.Context, userID string) ([]*NoteType, error)
+	GetNoteType(c context.Context, id string, userID string) (*NoteType, error)
+	UpdateNoteType(c context.Context, id string, userID string, req *UpdateNoteTypeRequest) (*NoteType, error)
+	DeleteNoteType(c context.Context, id string, userID string) error
+	PreviewNoteType(c context.Context, id string, userID string, req *PreviewRequest) ([]*RenderedCard, error)
+	ResolveNoteType(c context.Context, id *primitive.ObjectID) (*NoteType, error)
+	DeleteUserData(c context.Context, userID string) error
+}
+
+// NoteTypeUsage reports how many words still use a note type, so one that
+// is in use is not deleted from under them.
+type NoteTypeUsage interface {
+	CountWordsByNoteType(c context.Context, noteTypeID primitive.ObjectID) (int64, error)
+}
+
+type noteTypeService struct {
+	noteTypeRepository NoteTypeRepository
+	usage              NoteTypeUsage
+}
+
+func NewNoteTypeService(noteTypeRepository NoteTypeRepository, usage NoteTypeUsage) NoteTypeService {
+	return &noteTypeService{
+		noteTypeRepository: noteTypeRepository,
+		usage:              usage,
+	}
+}
+
+func (s *noteTypeService) CreateNoteType(c context.Context, req *CreateNoteTypeRequest, userID string) (*NoteType, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	noteType := &NoteType{
+		ID:        primitive.NewObjectID(),
+		UserID:    objectUserID,
+		Name:      strings.TrimSpace(req.Name),
+		Fields:    cleanFields(req.Fields),
+		Templates: req.Templates,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := noteType.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.noteTypeRepository.CreateNoteType(c, noteType); err != nil {
+		return nil, err
+	}
+
+	return noteType, nil
+}
+
+func (s *noteTypeService) GetNoteTypes(c context.Context, userID string) ([]*NoteType, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypes, err := s.noteTypeRepository.GetNoteTypesByUserID(c, objectUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*NoteType{DefaultNoteType()}, noteTypes...), nil
+}
+
+func (s *noteTypeService) GetNoteType(c context.Context, id string, userID string) (*NoteType, error) {
+
+	if id == DefaultNoteTypeID {
+		return DefaultNoteType(), nil
+	}
+
+	return s.getOwnedNoteType(c, id, userID)
+}
+
+func (s *noteTypeService) UpdateNoteType(c context.Context, id string, userID string, req *UpdateNoteTypeRequest) (*NoteType, error) {
+
+	if id == DefaultNoteTypeID {
+		return nil, fmt.Errorf("%w: the default note type cannot be changed", helper.ErrPermissionDenied)
+	}
+
+	noteType, err := s.getOwnedNoteType(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bson.M{}
+	if req.Name != nil {
+		noteType.Name = strings.TrimSpace(*req.Name)
+		fields["name"] = noteType.Name
+	}
+
+	if req.Fields != nil {
+		noteType.Fields = cleanFields(*req.Fields)
+		fields["fields"] = noteType.Fields
+	}
+
+	if req.Templates != nil {
+		noteType.Templates = *req.Templates
+		fields["templates"] = noteType.Templates
+	}
+
+	if len(fields) == 0 {
+		return noteType, nil
+	}
+
+	if err := noteType.Validate(); err != nil {
+		return nil, err
+	}
+
+	noteType.UpdatedAt = time.Now()
+	fields["updated_at"] = noteType.UpdatedAt
+
+	if err := s.noteTypeRepository.UpdateNoteType(c, noteType.ID, fields); err != nil {
+		return nil, err
+	}
+
+	return noteType, nil
+}
+
+func (s *noteTypeService) DeleteNoteType(c context.Context, id string, userID string) error {
+
+	if id == DefaultNoteTypeID {
+		return fmt.Errorf("%w: the default note type cannot be deleted", helper.ErrPermissionDenied)
+	}
+
+	noteType, err := s.getOwnedNoteType(c, id, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.usage.CountWordsByNoteType(c, noteType.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("note type is used by %d words", count)
+	}
+
+	return s.noteTypeRepository.DeleteNoteType(c, noteType.ID)
+}
+
+func (s *noteTypeService) PreviewNoteType(c context.Context, id string, userID string, req *PreviewRequest) ([]*RenderedCard, error) {
+
+	noteType, err := s.GetNoteType(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := noteType.ValidateFields(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return noteType.Render(values)
+}
+
+// ResolveNoteType loads the note type a word refers to, falling back to the
+// default for words without one. It does not check ownership: words in a
+// shared topic are rendered with their author's note type.
+func (s *noteTypeService) ResolveNoteType(c context.Context, id *primitive.ObjectID) (*NoteType, error) {
+
+	if id == nil {
+		return DefaultNoteType(), nil
+	}
+
+	noteType, err := s.noteTypeRepository.GetNoteTypeByID(c, *id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: note type not found", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return noteType, nil
+}
+
+func (s *noteTypeService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.noteTypeRepository.DeleteNoteTypesByUserID(c, objectID)
+}
+
+func (s *noteTypeService) getOwnedNoteType(c context.Context, id string, userID string) (*NoteType, error) {
+
+	if id == "" {
+		return nil, fmt.Errorf("note type id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteType, err := s.ResolveNoteType(c, &objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if noteType.UserID != objectUserID {
+		return nil, fmt.Errorf("%w: note type not found", helper.ErrResourceNotFound)
+	}
+
+	return noteType, nil
+}
+
+// cleanFields trims field names and marks the sort field as required.
+func cleanFields(fields []NoteField) []NoteField {
+
+	cleaned := make([]NoteField, 0, len(fields))
+	for _, field := range fields {
+		field.Name = strings.TrimSpace(field.Name)
+		cleaned = append(cleaned, field)
+	}
+
+	if len(cleaned) > 0 {
+		cleaned[0].Required = true
+	}
+
+	return cleaned
+}
@@ -0,0 +1,223 @@
+package notetypes
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"text/template/parse"
+)
+
+const (
+	frontSideField    = "FrontSide"
+	maxFields         = 20
+	maxTemplates      = 10
+	maxTemplateLength = 10000
+)
+
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Validate checks the field list and parses every template, rejecting
+// references to fields the note type does not define.
+func (n *NoteType) Validate() error {
+
+	if strings.TrimSpace(n.Name) == "" {
+		return fmt.Errorf("note type name is required")
+	}
+
+	if len(n.Fields) == 0 || len(n.Fields) > maxFields {
+		return fmt.Errorf("a note type needs between 1 and %d fields", maxFields)
+	}
+
+	fields := make(map[string]struct{}, len(n.Fields))
+	for _, field := range n.Fields {
+		if !fieldNamePattern.MatchString(field.Name) {
+			return fmt.Errorf("invalid field name %q: use letters, digits and underscores, starting with a letter", field.Name)
+		}
+		if field.Name == frontSideField {
+			return fmt.Errorf("%q is reserved", frontSideField)
+		}
+		if _, ok := fields[field.Name]; ok {
+			return fmt.Errorf("duplicate field %q", field.Name)
+		}
+		fields[field.Name] = struct{}{}
+	}
+
+	if len(n.Templates) == 0 || len(n.Templates) > maxTemplates {
+		return fmt.Errorf("a note type needs between 1 and %d templates", maxTemplates)
+	}
+
+	names := make(map[string]struct{}, len(n.Templates))
+	for _, card := range n.Templates {
+		if strings.TrimSpace(card.Name) == "" {
+			return fmt.Errorf("template name is required")
+		}
+		if _, ok := names[card.Name]; ok {
+			return fmt.Errorf("duplicate template %q", card.Name)
+		}
+		names[card.Name] = struct{}{}
+
+		if err := validateTemplate(card.Name+" front", card.Front, fields, false); err != nil {
+			return err
+		}
+		if err := validateTemplate(card.Name+" back", card.Back, fields, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateFields checks a word's field values against the note type and
+// returns them trimmed, with every defined field present.
+func (n *NoteType) ValidateFields(values map[string]string) (map[string]string, error) {
+
+	known := make(map[string]struct{}, len(n.Fields))
+	for _, field := range n.Fields {
+		known[field.Name] = struct{}{}
+	}
+
+	for name := range values {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q for note type %q", name, n.Name)
+		}
+	}
+
+	cleaned := make(map[string]string, len(n.Fields))
+	for i, field := range n.Fields {
+		value := strings.TrimSpace(values[field.Name])
+		if value == "" && (field.Required || i == 0) {
+			return nil, fmt.Errorf("field %q is required", field.Name)
+		}
+		cleaned[field.Name] = value
+	}
+
+	return cleaned, nil
+}
+
+// Render produces the front and back of every card template.
+func (n *NoteType) Render(values map[string]string) ([]*RenderedCard, error) {
+
+	data := make(map[string]interface{}, len(n.Fields)+1)
+	for _, field := range n.Fields {
+		data[field.Name] = values[field.Name]
+	}
+
+	cards := make([]*RenderedCard, 0, len(n.Templates))
+	for _, card := range n.Templates {
+		front, err := execute(card.Name+" front", card.Front, data)
+		if err != nil {
+			return nil, err
+		}
+
+		data[frontSideField] = template.HTML(front)
+		back, err := execute(card.Name+" back", card.Back, data)
+		delete(data, frontSideField)
+		if err != nil {
+			return nil, err
+		}
+
+		cards = append(cards, &RenderedCard{
+			Template: card.Name,
+			Front:    front,
+			Back:     back,
+		})
+	}
+
+	return cards, nil
+}
+
+func execute(name, text string, data map[string]interface{}) (string, error) {
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	return out.String(), nil
+}
+
+func validateTemplate(name, text string, fields map[string]struct{}, allowFrontSide bool) error {
+
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("template %s is empty", name)
+	}
+
+	if len(text) > maxTemplateLength {
+		return fmt.Errorf("template %s is longer than %d characters", name, maxTemplateLength)
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template %s: %w", name, err)
+	}
+
+	if len(tmpl.Templates()) > 1 {
+		return fmt.Errorf("template %s must not define nested templates", name)
+	}
+
+	return checkNode(name, tmpl.Tree.Root, fields, allowFrontSide)
+}
+
+// checkNode walks the parsed template. range and with are rejected because
+// they move the dot away from the field map, which would make field
+// references impossible to check.
+func checkNode(name string, node parse.Node, fields map[string]struct{}, allowFrontSide bool) error {
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(name, child, fields, allowFrontSide); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkNode(name, n.Pipe, fields, allowFrontSide)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkNode(name, arg, fields, allowFrontSide); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.IfNode:
+		if err := checkNode(name, n.Pipe, fields, allowFrontSide); err != nil {
+			return err
+		}
+		if err := checkNode(name, n.List, fields, allowFrontSide); err != nil {
+			return err
+		}
+		return checkNode(name, n.ElseList, fields, allowFrontSide)
+	case *parse.RangeNode, *parse.WithNode:
+		return fmt.Errorf("template %s: range and with are not supported", name)
+	case *parse.TemplateNode:
+		return fmt.Errorf("template %s: nested templates are not supported", name)
+	case *parse.ChainNode:
+		return fmt.Errorf("template %s: field chains are not supported", name)
+	case *parse.FieldNode:
+		if len(n.Ident) != 1 {
+			return fmt.Errorf("template %s: invalid field reference %s", name, n.String())
+		}
+		field := n.Ident[0]
+		if field == frontSideField && allowFrontSide {
+			return nil
+		}
+		if _, ok := fields[field]; !ok {
+			return fmt.Errorf("template %s references unknown field %q", name, field)
+		}
+	}
+
+	return nil
+}
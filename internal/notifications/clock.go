@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the scheduler what time it is, so reminders can be tested
+// without waiting for the time of day.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock that only moves when told to.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
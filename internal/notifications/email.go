@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type emailNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewEmailNotifier sends plain-text mail through an SMTP relay. Credentials
+// are optional; without them the relay must accept unauthenticated mail.
+func NewEmailNotifier(host string, port int, username string, password string, from string) Notifier {
+	return &emailNotifier{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (n *emailNotifier) Channel() string {
+	return ChannelEmail
+}
+
+func (n *emailNotifier) Notify(c context.Context, recipient *Recipient, message *Message) error {
+
+	to := stripLineBreaks(recipient.Email)
+	if to == "" {
+		return fmt.Errorf("user has no email address")
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", stripLineBreaks(n.from))
+	fmt.Fprintf(&body, "To: %s\r\n", to)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", stripLineBreaks(message.Title)))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(message.Body)
+	if message.URL != "" {
+		body.WriteString("\r\n\r\n" + message.URL)
+	}
+	body.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+
+	return smtp.SendMail(n.addr, auth, n.from, []string{to}, body.Bytes())
+}
@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Webhook and push URLs come from users, so requests to them must not reach
+// the server's own network. URLs are checked when they are saved, and every
+// connection is checked again when it is dialed, which also covers DNS names
+// that later resolve to an internal address.
+
+var errBlockedAddress = errors.New("address is not publicly routable")
+
+// blockedNetworks are ranges that net.IP's predicates do not cover.
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, including broadcast
+	"64:ff9b::/96",   // NAT64, which embeds IPv4 addresses
+	"64:ff9b:1::/48", // local-use NAT64
+	"2002::/16",      // 6to4, which embeds IPv4 addresses
+	"2001:db8::/32",  // documentation
+	"fec0::/10",      // deprecated site-local
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+func isPublicIP(ip net.IP) bool {
+
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validatePublicURL accepts https URLs whose host is, or resolves only to,
+// public addresses.
+func validatePublicURL(c context.Context, value string) error {
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("an https url is required")
+	}
+
+	if parsed.User != nil {
+		return fmt.Errorf("credentials in the url are not allowed")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errBlockedAddress
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return errBlockedAddress
+		}
+		return nil
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(c, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+
+	for _, address := range addresses {
+		if !isPublicIP(address.IP) {
+			return errBlockedAddress
+		}
+	}
+
+	return nil
+}
+
+// newPublicHTTPClient returns a client that only connects to public
+// addresses, uses no proxy and does not follow redirects.
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicIP(net.ParseIP(host)) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package notifications
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	NotificationService NotificationService
+}
+
+func NewNotificationHandler(notificationService NotificationService) *NotificationHandler {
+	return &NotificationHandler{NotificationService: notificationService}
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	preferences, err := h.NotificationService.GetPreferences(c, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", preferences)
+
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	preferences, err := h.NotificationService.UpdatePreferences(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", preferences)
+
+}
+
+func (h *NotificationHandler) GetPushKey(c *gin.Context) {
+
+	key, err := h.NotificationService.GetPushKey(c)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", key)
+
+}
+
+func (h *NotificationHandler) AddPushSubscription(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	preferences, err := h.NotificationService.AddPushSubscription(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", preferences)
+
+}
+
+func (h *NotificationHandler) RemovePushSubscription(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req RemovePushSubscriptionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.NotificationService.RemovePushSubscription(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (h *NotificationHandler) SendTestNotification(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	report, err := h.NotificationService.SendTestNotification(c, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", report)
+
+}
@@ -0,0 +1,121 @@
+package notifications
+
+import (
+	"time"
+	// Reminders are scheduled in the user's timezone, which must resolve
+	// even on hosts without a zoneinfo database.
+	_ "time/tzdata"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebPush = "webpush"
+	ChannelWebhook = "webhook"
+)
+
+const (
+	defaultReminderTime = "19:00"
+	defaultTimezone     = "UTC"
+)
+
+// weekdays are the accepted day names, indexed like time.Weekday.
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ReminderPreferences decide when a user is reminded to study and through
+// which channels. LastSentOn is the local date of the last reminder, so at
+// most one is sent per day.
+type ReminderPreferences struct {
+	UserID            primitive.ObjectID `json:"-" bson:"_id"`
+	Enabled           bool               `json:"enabled" bson:"enabled"`
+	TimeOfDay         string             `json:"time_of_day" bson:"time_of_day"`
+	Timezone          string             `json:"timezone" bson:"timezone"`
+	Days              []string           `json:"days" bson:"days"`
+	Channels          []string           `json:"channels" bson:"channels"`
+	WebhookURL        string             `json:"webhook_url,omitempty" bson:"webhook_url,omitempty"`
+	PushSubscriptions []PushSubscription `json:"push_subscriptions" bson:"push_subscriptions"`
+	LastSentOn        string             `json:"last_sent_on,omitempty" bson:"last_sent_on,omitempty"`
+	UpdatedAt         time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// PushSubscription is a browser's subscription as returned by
+// PushSubscription.toJSON(). The keys are base64url encoded.
+type PushSubscription struct {
+	Endpoint  string    `json:"endpoint" bson:"endpoint"`
+	P256dh    string    `json:"p256dh" bson:"p256dh"`
+	Auth      string    `json:"-" bson:"auth"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Message is what a reminder says, independent of the channel.
+type Message struct {
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	URL      string `json:"url,omitempty"`
+	DueCards int64  `json:"due_cards"`
+}
+
+// Recipient is the user a message is delivered to, with the addresses their
+// preferences hold.
+type Recipient struct {
+	UserID      primitive.ObjectID
+	Email       string
+	Preferences *ReminderPreferences
+}
+
+func defaultPreferences(userID primitive.ObjectID) *ReminderPreferences {
+	return &ReminderPreferences{
+		UserID:            userID,
+		TimeOfDay:         defaultReminderTime,
+		Timezone:          defaultTimezone,
+		Days:              append([]string(nil), weekdays...),
+		Channels:          []string{ChannelEmail},
+		PushSubscriptions: []PushSubscription{},
+	}
+}
+
+// dueOn reports whether the reminder should go out at now and returns the
+// local date it is for. A reminder is due from its time of day until the
+// end of that day, on the selected days only, and once per day.
+func (p *ReminderPreferences) dueOn(now time.Time) (string, bool, error) {
+
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return "", false, err
+	}
+
+	local := now.In(location)
+	today := local.Format(time.DateOnly)
+	if !p.Enabled || p.LastSentOn == today || !containsString(p.Days, weekdays[local.Weekday()]) {
+		return today, false, nil
+	}
+
+	at, err := time.ParseInLocation(time.DateOnly+" 15:04", today+" "+p.TimeOfDay, location)
+	if err != nil {
+		return "", false, err
+	}
+
+	return today, !local.Before(at), nil
+}
+
+// sameLocalDay reports whether t falls on the given local date in the
+// preferences' timezone.
+func (p *ReminderPreferences) sameLocalDay(t time.Time, date string) bool {
+
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return false
+	}
+
+	return t.In(location).Format(time.DateOnly) == date
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
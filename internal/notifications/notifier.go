@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Notifier delivers a message over one channel.
+type Notifier interface {
+	Channel() string
+	Notify(c context.Context, recipient *Recipient, message *Message) error
+}
+
+// ExpiredSubscriptionsError is returned by the web push notifier when push
+// services report subscriptions as gone; they should be forgotten.
+type ExpiredSubscriptionsError struct {
+	Endpoints []string
+	Err       error
+}
+
+func (e *ExpiredSubscriptionsError) Error() string {
+	message := fmt.Sprintf("%d push subscriptions have expired", len(e.Endpoints))
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *ExpiredSubscriptionsError) Unwrap() error {
+	return e.Err
+}
+
+// Delivery is a message that a RecordingNotifier received.
+type Delivery struct {
+	Recipient *Recipient
+	Message   *Message
+}
+
+// RecordingNotifier is a local sink that keeps every message instead of
+// sending it, for tests and development setups without mail or push
+// services.
+type RecordingNotifier struct {
+	channel    string
+	mu         sync.Mutex
+	deliveries []Delivery
+}
+
+func NewRecordingNotifier(channel string) *RecordingNotifier {
+	return &RecordingNotifier{channel: channel}
+}
+
+func (n *RecordingNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *RecordingNotifier) Notify(c context.Context, recipient *Recipient, message *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.deliveries = append(n.deliveries, Delivery{Recipient: recipient, Message: message})
+	return nil
+}
+
+func (n *RecordingNotifier) Deliveries() []Delivery {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Delivery(nil), n.deliveries...)
+}
+
+// stripLineBreaks keeps values from injecting extra mail headers.
+func stripLineBreaks(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
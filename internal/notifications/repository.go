@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PreferenceRepository interface {
+	GetPreferences(c context.Context, userID primitive.ObjectID) (*ReminderPreferences, error)
+	GetEnabledPreferences(c context.Context) ([]*ReminderPreferences, error)
+	SavePreferences(c context.Context, preferences *ReminderPreferences) error
+	MarkSent(c context.Context, userID primitive.ObjectID, date string) error
+	RemovePushSubscriptions(c context.Context, userID primitive.ObjectID, endpoints []string) error
+	DeletePreferences(c context.Context, userID primitive.ObjectID) error
+}
+
+type preferenceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPreferenceRepository(collection *mongo.Collection) PreferenceRepository {
+	return &preferenceRepository{collection: collection}
+}
+
+func (r *preferenceRepository) GetPreferences(c context.Context, userID primitive.ObjectID) (*ReminderPreferences, error) {
+
+	var preferences ReminderPreferences
+
+	err := r.collection.FindOne(c, bson.M{"_id": userID}).Decode(&preferences)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &preferences, nil
+}
+
+func (r *preferenceRepository) GetEnabledPreferences(c context.Context) ([]*ReminderPreferences, error) {
+
+	cursor, err := r.collection.Find(c, bson.M{"enabled": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	var preferences []*ReminderPreferences
+	if err := cursor.All(c, &preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (r *preferenceRepository) SavePreferences(c context.Context, preferences *ReminderPreferences) error {
+
+	_, err := r.collection.ReplaceOne(c, bson.M{"_id": preferences.UserID}, preferences, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *preferenceRepository) MarkSent(c context.Context, userID primitive.ObjectID, date string) error {
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": userID}, bson.M{"$set": bson.M{"last_sent_on": date}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *preferenceRepository) RemovePushSubscriptions(c context.Context, userID primitive.ObjectID, endpoints []string) error {
+
+	update := bson.M{
+		"$pull": bson.M{"push_subscriptions": bson.M{"endpoint": bson.M{"$in": endpoints}}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": userID}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *preferenceRepository) DeletePreferences(c context.Context, userID primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteOne(c, bson.M{"_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
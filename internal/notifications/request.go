@@ -0,0 +1,23 @@
+package notifications
+
+type UpdatePreferencesRequest struct {
+	Enabled    *bool     `json:"enabled"`
+	TimeOfDay  *string   `json:"time_of_day"`
+	Timezone   *string   `json:"timezone"`
+	Days       *[]string `json:"days"`
+	Channels   *[]string `json:"channels"`
+	WebhookURL *string   `json:"webhook_url"`
+}
+
+// PushSubscriptionRequest matches the JSON of a browser PushSubscription.
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+type RemovePushSubscriptionRequest struct {
+	Endpoint string `form:"endpoint" json:"endpoint"`
+}
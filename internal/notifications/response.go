@@ -0,0 +1,11 @@
+package notifications
+
+type PushKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// DeliveryReport lists, per channel, whether a message went out.
+type DeliveryReport struct {
+	Sent   []string          `json:"sent"`
+	Failed map[string]string `json:"failed,omitempty"`
+}
@@ -0,0 +1,21 @@
+package notifications
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *NotificationHandler) {
+
+	notificationGroup := r.Group("/api/v1/notifications")
+	{
+		notificationGroup.GET("/preferences", middleware.JWTAuthMiddleware(), handler.GetPreferences)
+		notificationGroup.PUT("/preferences", middleware.JWTAuthMiddleware(), handler.UpdatePreferences)
+		notificationGroup.GET("/push/key", handler.GetPushKey)
+		notificationGroup.POST("/push/subscriptions", middleware.JWTAuthMiddleware(), handler.AddPushSubscription)
+		notificationGroup.DELETE("/push/subscriptions", middleware.JWTAuthMiddleware(), handler.RemovePushSubscription)
+		notificationGroup.POST("/test", middleware.JWTAuthMiddleware(), handler.SendTestNotification)
+	}
+
+}
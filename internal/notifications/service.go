@@ -0,0 +1,465 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"flashcard/helper"
+	"flashcard/internal/user"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxPushSubscriptions = 10
+
+type NotificationService interface {
+	GetPreferences(c context.Context, userID string) (*ReminderPreferences, error)
+	UpdatePreferences(c context.Context, userID string, req *UpdatePreferencesRequest) (*ReminderPreferences, error)
+	AddPushSubscription(c context.Context, userID string, req *PushSubscriptionRequest) (*ReminderPreferences, error)
+	RemovePushSubscription(c context.Context, userID string, req *RemovePushSubscriptionRequest) error
+	GetPushKey(c context.Context) (*PushKeyResponse, error)
+	SendTestNotification(c context.Context, userID string) (*DeliveryReport, error)
+	SendDueReminders(c context.Context) (int, error)
+	DeleteUserData(c context.Context, userID string) error
+}
+
+// StudyTracker reports a user's review workload. It is implemented by the
+// cards package.
+type StudyTracker interface {
+	CountDueCards(c context.Context, userID primitive.ObjectID, now time.Time) (int64, error)
+	LastStudiedAt(c context.Context, userID primitive.ObjectID) (*time.Time, error)
+}
+
+// UserDirectory resolves reminder recipients to their accounts.
+type UserDirectory interface {
+	FindByID(ctx context.Context, userID primitive.ObjectID) (*user.User, error)
+}
+
+type notificationService struct {
+	preferenceRepository PreferenceRepository
+	studyTracker         StudyTracker
+	users                UserDirectory
+	clock                Clock
+	appURL               string
+	notifiers            map[string]Notifier
+}
+
+// NewNotificationService delivers reminders through the given notifiers, at
+// most one per channel. Channels without a notifier cannot be selected.
+func NewNotificationService(preferenceRepository PreferenceRepository, studyTracker StudyTracker, users UserDirectory, clock Clock, appURL string, notifiers ...Notifier) NotificationService {
+
+	byChannel := make(map[string]Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		byChannel[notifier.Channel()] = notifier
+	}
+
+	return &notificationService{
+		preferenceRepository: preferenceRepository,
+		studyTracker:         studyTracker,
+		users:                users,
+		clock:                clock,
+		appURL:               appURL,
+		notifiers:            byChannel,
+	}
+}
+
+// GetPreferences returns the user's reminder settings, or the defaults with
+// reminders switched off if they never saved any.
+func (s *notificationService) GetPreferences(c context.Context, userID string) (*ReminderPreferences, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadPreferences(c, objectUserID)
+}
+
+func (s *notificationService) UpdatePreferences(c context.Context, userID string, req *UpdatePreferencesRequest) (*ReminderPreferences, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.loadPreferences(c, objectUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TimeOfDay != nil {
+		timeOfDay := strings.TrimSpace(*req.TimeOfDay)
+		if _, err := time.Parse("15:04", timeOfDay); err != nil {
+			return nil, fmt.Errorf("time of day must be formatted as HH:MM")
+		}
+		preferences.TimeOfDay = timeOfDay
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if timezone == "" || strings.EqualFold(timezone, "local") {
+			return nil, fmt.Errorf("timezone must be an IANA name such as Europe/Berlin")
+		}
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", timezone)
+		}
+		preferences.Timezone = timezone
+	}
+
+	if req.Days != nil {
+		days, err := normalizeDays(*req.Days)
+		if err != nil {
+			return nil, err
+		}
+		preferences.Days = days
+	}
+
+	if req.WebhookURL != nil {
+		webhookURL := strings.TrimSpace(*req.WebhookURL)
+		if webhookURL != "" {
+			if err := validatePublicURL(c, webhookURL); err != nil {
+				return nil, fmt.Errorf("invalid webhook url: %w", err)
+			}
+		}
+		preferences.WebhookURL = webhookURL
+	}
+
+	if req.Channels != nil {
+		channels, err := s.normalizeChannels(*req.Channels)
+		if err != nil {
+			return nil, err
+		}
+		preferences.Channels = channels
+	}
+
+	if req.Enabled != nil {
+		preferences.Enabled = *req.Enabled
+	}
+
+	if containsString(preferences.Channels, ChannelWebhook) && preferences.WebhookURL == "" {
+		return nil, fmt.Errorf("a webhook url is required for the webhook channel")
+	}
+
+	if preferences.Enabled && len(preferences.Channels) == 0 {
+		return nil, fmt.Errorf("at least one channel is required to enable reminders")
+	}
+
+	preferences.UpdatedAt = time.Now()
+	if err := s.preferenceRepository.SavePreferences(c, preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// AddPushSubscription registers a browser for web push. Subscribing the same
+// endpoint again replaces its keys; the oldest subscription is dropped once
+// the user has too many.
+func (s *notificationService) AddPushSubscription(c context.Context, userID string, req *PushSubscriptionRequest) (*ReminderPreferences, error) {
+
+	if _, ok := s.notifiers[ChannelWebPush]; !ok {
+		return nil, fmt.Errorf("web push is not configured")
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePublicURL(c, req.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	if key, err := decodeBase64URL(req.Keys.P256dh); err != nil || len(key) != 65 {
+		return nil, fmt.Errorf("invalid p256dh key")
+	}
+
+	if secret, err := decodeBase64URL(req.Keys.Auth); err != nil || len(secret) != 16 {
+		return nil, fmt.Errorf("invalid auth secret")
+	}
+
+	preferences, err := s.loadPreferences(c, objectUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]PushSubscription, 0, len(preferences.PushSubscriptions)+1)
+	for _, subscription := range preferences.PushSubscriptions {
+		if subscription.Endpoint != req.Endpoint {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	subscriptions = append(subscriptions, PushSubscription{
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		CreatedAt: time.Now(),
+	})
+	if len(subscriptions) > maxPushSubscriptions {
+		subscriptions = subscriptions[len(subscriptions)-maxPushSubscriptions:]
+	}
+
+	preferences.PushSubscriptions = subscriptions
+	preferences.UpdatedAt = time.Now()
+	if err := s.preferenceRepository.SavePreferences(c, preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (s *notificationService) RemovePushSubscription(c context.Context, userID string, req *RemovePushSubscriptionRequest) error {
+
+	if req.Endpoint == "" {
+		return fmt.Errorf("endpoint is required")
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.preferenceRepository.RemovePushSubscriptions(c, objectUserID, []string{req.Endpoint})
+}
+
+func (s *notificationService) GetPushKey(c context.Context) (*PushKeyResponse, error) {
+
+	notifier, ok := s.notifiers[ChannelWebPush].(interface{ PublicKey() string })
+	if !ok {
+		return nil, fmt.Errorf("%w: web push is not configured", helper.ErrResourceNotFound)
+	}
+
+	return &PushKeyResponse{PublicKey: notifier.PublicKey()}, nil
+}
+
+// SendTestNotification sends a sample reminder right away through every
+// channel the user selected, regardless of schedule.
+func (s *notificationService) SendTestNotification(c context.Context, userID string) (*DeliveryReport, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.loadPreferences(c, objectUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(preferences.Channels) == 0 {
+		return nil, fmt.Errorf("no notification channels are selected")
+	}
+
+	due, err := s.studyTracker.CountDueCards(c, objectUserID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deliver(c, preferences, s.reminderMessage(due))
+}
+
+// SendDueReminders is run periodically by the scheduler. It reminds every
+// user whose reminder time has passed today, who has cards due and who has
+// not reviewed anything yet today, and returns how many were reminded.
+func (s *notificationService) SendDueReminders(c context.Context) (int, error) {
+
+	now := s.clock.Now()
+
+	enabled, err := s.preferenceRepository.GetEnabledPreferences(c)
+	if err != nil {
+		return 0, err
+	}
+
+	reminded := 0
+	for _, preferences := range enabled {
+		sent, err := s.remind(c, preferences, now)
+		if err != nil {
+			log.Printf("Failed to send study reminder to user %s: %v", preferences.UserID.Hex(), err)
+			continue
+		}
+		if sent {
+			reminded++
+		}
+	}
+
+	return reminded, nil
+}
+
+func (s *notificationService) remind(c context.Context, preferences *ReminderPreferences, now time.Time) (bool, error) {
+
+	today, due, err := preferences.dueOn(now)
+	if err != nil || !due {
+		return false, err
+	}
+
+	lastStudied, err := s.studyTracker.LastStudiedAt(c, preferences.UserID)
+	if err != nil {
+		return false, err
+	}
+	if lastStudied != nil && preferences.sameLocalDay(*lastStudied, today) {
+		return false, nil
+	}
+
+	dueCards, err := s.studyTracker.CountDueCards(c, preferences.UserID, now)
+	if err != nil {
+		return false, err
+	}
+	if dueCards == 0 {
+		return false, nil
+	}
+
+	// Mark first so a failing channel is not retried on every tick.
+	if err := s.preferenceRepository.MarkSent(c, preferences.UserID, today); err != nil {
+		return false, err
+	}
+
+	report, err := s.deliver(c, preferences, s.reminderMessage(dueCards))
+	if err != nil {
+		return false, err
+	}
+
+	for channel, failure := range report.Failed {
+		log.Printf("Failed to deliver study reminder to user %s over %s: %s", preferences.UserID.Hex(), channel, failure)
+	}
+
+	return len(report.Sent) > 0, nil
+}
+
+// deliver sends the message over each selected channel and forgets push
+// subscriptions the push service reports as gone.
+func (s *notificationService) deliver(c context.Context, preferences *ReminderPreferences, message *Message) (*DeliveryReport, error) {
+
+	account, err := s.users.FindByID(c, preferences.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("%w: user not found", helper.ErrResourceNotFound)
+	}
+	if account.DeletionScheduledAt != nil {
+		return nil, fmt.Errorf("account is scheduled for deletion")
+	}
+
+	recipient := &Recipient{UserID: preferences.UserID, Email: account.Email, Preferences: preferences}
+	report := &DeliveryReport{Sent: []string{}, Failed: map[string]string{}}
+
+	for _, channel := range preferences.Channels {
+		notifier, ok := s.notifiers[channel]
+		if !ok {
+			report.Failed[channel] = "channel is not configured"
+			continue
+		}
+
+		err := notifier.Notify(c, recipient, message)
+
+		var expired *ExpiredSubscriptionsError
+		if errors.As(err, &expired) {
+			if removeErr := s.preferenceRepository.RemovePushSubscriptions(c, preferences.UserID, expired.Endpoints); removeErr != nil {
+				return nil, removeErr
+			}
+			err = expired.Err
+		}
+
+		if err != nil {
+			report.Failed[channel] = err.Error()
+			continue
+		}
+		report.Sent = append(report.Sent, channel)
+	}
+
+	return report, nil
+}
+
+func (s *notificationService) reminderMessage(dueCards int64) *Message {
+
+	body := fmt.Sprintf("You have %d cards waiting for review.", dueCards)
+	if dueCards == 1 {
+		body = "You have 1 card waiting for review."
+	}
+
+	return &Message{
+		Title:    "Time to study",
+		Body:     body,
+		URL:      s.appURL,
+		DueCards: dueCards,
+	}
+}
+
+func (s *notificationService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.preferenceRepository.DeletePreferences(c, objectID)
+}
+
+func (s *notificationService) loadPreferences(c context.Context, userID primitive.ObjectID) (*ReminderPreferences, error) {
+
+	preferences, err := s.preferenceRepository.GetPreferences(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if preferences == nil {
+		return defaultPreferences(userID), nil
+	}
+
+	if preferences.PushSubscriptions == nil {
+		preferences.PushSubscriptions = []PushSubscription{}
+	}
+
+	return preferences, nil
+}
+
+func (s *notificationService) normalizeChannels(channels []string) ([]string, error) {
+
+	normalized := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if _, ok := s.notifiers[channel]; !ok {
+			return nil, fmt.Errorf("notification channel %q is not available", channel)
+		}
+		if !containsString(normalized, channel) {
+			normalized = append(normalized, channel)
+		}
+	}
+
+	return normalized, nil
+}
+
+// normalizeDays accepts day names such as "mon" or "Monday" and returns
+// them abbreviated, in week order.
+func normalizeDays(days []string) ([]string, error) {
+
+	selected := make(map[string]bool, len(days))
+	for _, day := range days {
+		name := strings.ToLower(strings.TrimSpace(day))
+		for i, weekday := range weekdays {
+			if name == strings.ToLower(time.Weekday(i).String()) {
+				name = weekday
+			}
+		}
+		if !containsString(weekdays, name) {
+			return nil, fmt.Errorf("unknown day %q", day)
+		}
+		selected[name] = true
+	}
+
+	normalized := make([]string, 0, len(selected))
+	for _, day := range weekdays {
+		if selected[day] {
+			normalized = append(normalized, day)
+		}
+	}
+
+	return normalized, nil
+}
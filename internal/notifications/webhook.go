@@ -0,0 +1,91 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const webhookSignatureHeader = "X-Flashcard-Signature"
+
+type webhookNotifier struct {
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier posts reminders as JSON to the URL in the user's
+// preferences. With a secret, each request carries an HMAC-SHA256 of the body
+// in the X-Flashcard-Signature header so receivers can verify it.
+func NewWebhookNotifier(secret string, timeout time.Duration) Notifier {
+	return &webhookNotifier{secret: secret, client: newPublicHTTPClient(timeout)}
+}
+
+type webhookPayload struct {
+	Event    string    `json:"event"`
+	UserID   string    `json:"user_id"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+	URL      string    `json:"url,omitempty"`
+	DueCards int64     `json:"due_cards"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+func (n *webhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *webhookNotifier) Notify(c context.Context, recipient *Recipient, message *Message) error {
+
+	if recipient.Preferences == nil || recipient.Preferences.WebhookURL == "" {
+		return fmt.Errorf("no webhook url is configured")
+	}
+
+	if !strings.HasPrefix(recipient.Preferences.WebhookURL, "https://") {
+		return fmt.Errorf("webhook url must use https")
+	}
+
+	payload, err := json.Marshal(&webhookPayload{
+		Event:    "study_reminder",
+		UserID:   recipient.UserID.Hex(),
+		Title:    message.Title,
+		Body:     message.Body,
+		URL:      message.URL,
+		DueCards: message.DueCards,
+		SentAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, recipient.Preferences.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(payload)
+		req.Header.Set(webhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	pushRecordSize = 4096
+	pushTTLSeconds = 24 * 60 * 60
+	vapidTokenTTL  = 12 * time.Hour
+)
+
+type webPushNotifier struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+	client     *http.Client
+}
+
+// NewWebPushNotifier sends encrypted Web Push messages (RFC 8291) signed with
+// VAPID (RFC 8292). The keys are the base64url encoded raw P-256 keys that
+// tools such as web-push generate-vapid-keys print; the public key may be
+// left empty and is then derived from the private key. The subject is a
+// mailto: or https: contact for push services.
+func NewWebPushNotifier(publicKey string, privateKey string, subject string, timeout time.Duration) (Notifier, error) {
+
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	ecdhKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	point := ecdhKey.PublicKey().Bytes()
+	derived := base64.RawURLEncoding.EncodeToString(point)
+	if publicKey != "" && strings.TrimRight(publicKey, "=") != derived {
+		return nil, fmt.Errorf("vapid public key does not match the private key")
+	}
+
+	if subject == "" {
+		return nil, fmt.Errorf("a vapid subject is required")
+	}
+
+	return &webPushNotifier{
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(point[1:33]),
+				Y:     new(big.Int).SetBytes(point[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		publicKey: derived,
+		subject:   subject,
+		client:    newPublicHTTPClient(timeout),
+	}, nil
+}
+
+func (n *webPushNotifier) Channel() string {
+	return ChannelWebPush
+}
+
+// PublicKey is the application server key browsers subscribe with.
+func (n *webPushNotifier) PublicKey() string {
+	return n.publicKey
+}
+
+// Notify pushes the message to every subscription of the user. Subscriptions
+// the push service no longer knows are reported in an
+// ExpiredSubscriptionsError.
+func (n *webPushNotifier) Notify(c context.Context, recipient *Recipient, message *Message) error {
+
+	if recipient.Preferences == nil || len(recipient.Preferences.PushSubscriptions) == 0 {
+		return fmt.Errorf("no push subscriptions are registered")
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var expired []string
+	var errs []error
+	for _, subscription := range recipient.Preferences.PushSubscriptions {
+		gone, err := n.push(c, &subscription, payload)
+		if gone {
+			expired = append(expired, subscription.Endpoint)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(expired) > 0 {
+		return &ExpiredSubscriptionsError{Endpoints: expired, Err: errors.Join(errs...)}
+	}
+
+	return errors.Join(errs...)
+}
+
+// push delivers one payload and reports whether the subscription is gone.
+func (n *webPushNotifier) push(c context.Context, subscription *PushSubscription, payload []byte) (bool, error) {
+
+	body, err := encryptPushPayload(subscription, payload)
+	if err != nil {
+		return false, err
+	}
+
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": n.subject,
+	}).SignedString(n.privateKey)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "vapid t="+token+", k="+n.publicKey)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(pushTTLSeconds))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return true, nil
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return false, fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+
+	return false, nil
+}
+
+// encryptPushPayload encrypts a payload for one subscription with the
+// aes128gcm content coding of RFC 8188, keyed as RFC 8291 describes.
+func encryptPushPayload(subscription *PushSubscription, payload []byte) ([]byte, error) {
+
+	userAgentKey, err := decodeBase64URL(subscription.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	authSecret, err := decodeBase64URL(subscription.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	userAgentPublic, err := ecdh.P256().NewPublicKey(userAgentKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	serverPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverPrivate.PublicKey().Bytes()
+
+	sharedSecret, err := serverPrivate.ECDH(userAgentPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(userAgentKey) + string(serverPublic)
+	inputKey, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	contentKey, err := hkdf.Key(sha256.New, inputKey, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hkdf.Key(sha256.New, inputKey, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single record: the payload followed by the last-record delimiter.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	if len(plaintext)+gcm.Overhead() > pushRecordSize {
+		return nil, fmt.Errorf("push payload is too large")
+	}
+
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, pushRecordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,64 @@
+package quiz
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AttemptRepository interface {
+	CreateAttempt(c context.Context, attempt *Attempt) error
+	GetAttempts(c context.Context, topicID, userID primitive.ObjectID, limit int64) ([]*Attempt, error)
+	DeleteAttemptsByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type attemptRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAttemptRepository(collection *mongo.Collection) AttemptRepository {
+	return &attemptRepository{collection: collection}
+}
+
+func (r *attemptRepository) CreateAttempt(c context.Context, attempt *Attempt) error {
+	_, err := r.collection.InsertOne(c, attempt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *attemptRepository) GetAttempts(c context.Context, topicID, userID primitive.ObjectID, limit int64) ([]*Attempt, error) {
+
+	filter := bson.M{"topic_id": topicID, "user_id": userID}
+	opts := options.Find().SetSort(bson.D{{Key: "submitted_at", Value: -1}}).SetLimit(limit)
+
+	var attempts []*Attempt
+
+	cursor, err := r.collection.Find(c, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var attempt Attempt
+		if err := cursor.Decode(&attempt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, &attempt)
+	}
+
+	return attempts, nil
+}
+
+func (r *attemptRepository) DeleteAttemptsByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package quiz
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"flashcard/internal/words"
+)
+
+const maxDistractors = 3
+
+// generateQuestions picks up to count words at random and asks one question
+// about each, choosing the type at random from types. Questions that need
+// distractors fall back to typed answers when the topic has only one
+// distinct definition.
+func generateQuestions(topicWords []*words.Word, count int, types []string) ([]Question, error) {
+
+	var candidates []*words.Word
+	for _, word := range topicWords {
+		if strings.TrimSpace(word.Word) != "" && strings.TrimSpace(word.Definition) != "" {
+			candidates = append(candidates, word)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("topic has no words with a definition to quiz on")
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	definitions := distinctDefinitions(candidates)
+
+	questions := make([]Question, 0, count)
+	for _, word := range candidates[:count] {
+		questionType := types[rand.IntN(len(types))]
+		if questionType != QuestionTyped && len(definitions) < 2 {
+			questionType = QuestionTyped
+		}
+
+		id, err := questionID()
+		if err != nil {
+			return nil, err
+		}
+
+		question := Question{
+			ID:     id,
+			Type:   questionType,
+			WordID: word.ID,
+		}
+
+		definition := strings.TrimSpace(word.Definition)
+		switch questionType {
+		case QuestionMultipleChoice:
+			question.Prompt = word.Word
+			question.Options = append(distractors(definitions, definition, maxDistractors), definition)
+			rand.Shuffle(len(question.Options), func(i, j int) {
+				question.Options[i], question.Options[j] = question.Options[j], question.Options[i]
+			})
+			question.Answer = definition
+		case QuestionTrueFalse:
+			question.Prompt = word.Word
+			question.Statement = definition
+			question.Answer = "true"
+			if rand.IntN(2) == 0 {
+				question.Statement = distractors(definitions, definition, 1)[0]
+				question.Answer = "false"
+			}
+		default:
+			question.Prompt = definition
+			question.Answer = word.Word
+		}
+
+		questions = append(questions, question)
+	}
+
+	return questions, nil
+}
+
+func distinctDefinitions(candidates []*words.Word) []string {
+
+	seen := make(map[string]struct{}, len(candidates))
+	var definitions []string
+	for _, word := range candidates {
+		definition := strings.TrimSpace(word.Definition)
+		if _, ok := seen[definition]; ok {
+			continue
+		}
+		seen[definition] = struct{}{}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions
+}
+
+// distractors draws up to n definitions other than the correct one.
+func distractors(definitions []string, correct string, n int) []string {
+
+	var others []string
+	for _, definition := range definitions {
+		if definition != correct {
+			others = append(others, definition)
+		}
+	}
+
+	rand.Shuffle(len(others), func(i, j int) {
+		others[i], others[j] = others[j], others[i]
+	})
+	if len(others) > n {
+		others = others[:n]
+	}
+
+	return others
+}
+
+// questionID is a random ID for one question of one quiz, so that answers
+// can be submitted without the client learning which word was asked.
+func questionID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := cryptorand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,23 @@
+package quiz
+
+import (
+	"strings"
+
+	"flashcard/internal/grading"
+)
+
+// gradeAnswer checks an answer against a question. Typed answers go through
+// the shared grader, which ignores case and accepts a few typos.
+func gradeAnswer(question *Question, answer string) bool {
+
+	answer = strings.TrimSpace(answer)
+
+	switch question.Type {
+	case QuestionMultipleChoice:
+		return answer == question.Answer
+	case QuestionTrueFalse:
+		return strings.EqualFold(answer, question.Answer)
+	default:
+		return grading.Grade(answer, question.Answer, grading.Options{StripArticles: true}).Correct
+	}
+}
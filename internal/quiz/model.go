@@ -0,0 +1,60 @@
+package quiz
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionTyped          = "typed"
+	QuestionTrueFalse      = "true_false"
+)
+
+// Quiz is a generated set of questions. The expected answers stay on the
+// server and are only revealed in the graded attempt.
+type Quiz struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	TopicID     primitive.ObjectID `json:"topic_id" bson:"topic_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Questions   []Question         `json:"questions" bson:"questions"`
+	SubmittedAt *time.Time         `json:"submitted_at,omitempty" bson:"submitted_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Question asks about one word. Multiple choice questions show the word and
+// list definitions, typed questions show the definition and expect the word,
+// and true/false questions pair the word with a definition in Statement.
+// Like the answer, the word asked about is only known to the server until
+// the quiz is graded; clients refer to questions by their random ID.
+type Question struct {
+	ID        string             `json:"id" bson:"id"`
+	Type      string             `json:"type" bson:"type"`
+	WordID    primitive.ObjectID `json:"-" bson:"word_id"`
+	Prompt    string             `json:"prompt" bson:"prompt"`
+	Statement string             `json:"statement,omitempty" bson:"statement,omitempty"`
+	Options   []string           `json:"options,omitempty" bson:"options,omitempty"`
+	Answer    string             `json:"-" bson:"answer"`
+}
+
+type Attempt struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	QuizID      primitive.ObjectID `json:"quiz_id" bson:"quiz_id"`
+	TopicID     primitive.ObjectID `json:"topic_id" bson:"topic_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Answers     []AttemptAnswer    `json:"answers" bson:"answers"`
+	Score       int                `json:"score" bson:"score"`
+	Total       int                `json:"total" bson:"total"`
+	Percent     float64            `json:"percent" bson:"percent"`
+	SubmittedAt time.Time          `json:"submitted_at" bson:"submitted_at"`
+}
+
+type AttemptAnswer struct {
+	QuestionID string             `json:"question_id" bson:"question_id"`
+	Type       string             `json:"type" bson:"type"`
+	WordID     primitive.ObjectID `json:"word_id" bson:"word_id"`
+	Answer     string             `json:"answer" bson:"answer"`
+	Expected   string             `json:"expected" bson:"expected"`
+	Correct    bool               `json:"correct" bson:"correct"`
+}
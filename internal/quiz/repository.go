@@ -0,0 +1,69 @@
+package quiz
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type QuizRepository interface {
+	CreateQuiz(c context.Context, quiz *Quiz) error
+	GetQuizByID(c context.Context, id primitive.ObjectID) (*Quiz, error)
+	MarkSubmitted(c context.Context, id primitive.ObjectID, submittedAt time.Time) (bool, error)
+	DeleteQuizzesByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type quizRepository struct {
+	collection *mongo.Collection
+}
+
+func NewQuizRepository(collection *mongo.Collection) QuizRepository {
+	return &quizRepository{collection: collection}
+}
+
+func (r *quizRepository) CreateQuiz(c context.Context, quiz *Quiz) error {
+	_, err := r.collection.InsertOne(c, quiz)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *quizRepository) GetQuizByID(c context.Context, id primitive.ObjectID) (*Quiz, error) {
+
+	var quiz Quiz
+
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&quiz)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quiz, nil
+
+}
+
+// MarkSubmitted reports false when the quiz had already been submitted, so
+// two concurrent submissions cannot both be scored.
+func (r *quizRepository) MarkSubmitted(c context.Context, id primitive.ObjectID, submittedAt time.Time) (bool, error) {
+
+	filter := bson.M{"_id": id, "submitted_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"submitted_at": submittedAt}}
+
+	result, err := r.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *quizRepository) DeleteQuizzesByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package quiz
+
+type CreateQuizRequest struct {
+	Count int      `json:"count" bson:"count"`
+	Types []string `json:"types" bson:"types"`
+}
+
+type SubmitQuizRequest struct {
+	Answers []SubmittedAnswer `json:"answers" bson:"answers"`
+}
+
+type SubmittedAnswer struct {
+	QuestionID string `json:"question_id" bson:"question_id"`
+	Answer     string `json:"answer" bson:"answer"`
+}
+
+type ListAttemptsRequest struct {
+	Limit int `form:"limit" json:"limit"`
+}
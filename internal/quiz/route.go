@@ -0,0 +1,23 @@
+package quiz
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *QuizHandler) {
+
+	topicQuizGroup := r.Group("/api/v1/topic/:topic_id/quiz")
+	{
+		topicQuizGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateQuiz)
+		topicQuizGroup.GET("/attempts", middleware.JWTAuthMiddleware(), handler.GetAttempts)
+	}
+
+	quizGroup := r.Group("/api/v1/quiz")
+	{
+		quizGroup.GET("/:quiz_id", middleware.JWTAuthMiddleware(), handler.GetQuiz)
+		quizGroup.POST("/:quiz_id/submit", middleware.JWTAuthMiddleware(), handler.SubmitQuiz)
+	}
+
+}
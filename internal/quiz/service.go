@@ -0,0 +1,233 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"flashcard/helper"
+	"flashcard/internal/words"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultQuestionCount = 10
+	maxQuestionCount     = 50
+)
+
+type QuizService interface {
+	CreateQuiz(c context.Context, topicID string, userID string, req *CreateQuizRequest) (*Quiz, error)
+	GetQuiz(c context.Context, id string, userID string) (*Quiz, error)
+	SubmitQuiz(c context.Context, id string, userID string, req *SubmitQuizRequest) (*Attempt, error)
+	GetAttempts(c context.Context, topicID string, userID string, req *ListAttemptsRequest) ([]*Attempt, error)
+	DeleteUserData(c context.Context, userID string) error
+}
+
+type quizService struct {
+	quizRepository    QuizRepository
+	attemptRepository AttemptRepository
+	wordService       words.WordService
+}
+
+func NewQuizService(quizRepository QuizRepository, attemptRepository AttemptRepository, wordService words.WordService) QuizService {
+	return &quizService{
+		quizRepository:    quizRepository,
+		attemptRepository: attemptRepository,
+		wordService:       wordService,
+	}
+}
+
+func (s *quizService) CreateQuiz(c context.Context, topicID string, userID string, req *CreateQuizRequest) (*Quiz, error) {
+
+	if req.Count == 0 {
+		req.Count = defaultQuestionCount
+	}
+	if req.Count < 1 || req.Count > maxQuestionCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxQuestionCount)
+	}
+
+	types := req.Types
+	if len(types) == 0 {
+		types = []string{QuestionMultipleChoice, QuestionTyped, QuestionTrueFalse}
+	}
+	for _, questionType := range types {
+		if questionType != QuestionMultipleChoice && questionType != QuestionTyped && questionType != QuestionTrueFalse {
+			return nil, fmt.Errorf("invalid question type %q", questionType)
+		}
+	}
+
+	objectTopicID, err := primitive.ObjectIDFromHex(topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topicWords, err := s.wordService.GetTopicWords(c, topicID, userID, &words.SearchWordRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := generateQuestions(topicWords, req.Count, types)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := &Quiz{
+		ID:        primitive.NewObjectID(),
+		TopicID:   objectTopicID,
+		UserID:    objectUserID,
+		Questions: questions,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.quizRepository.CreateQuiz(c, quiz); err != nil {
+		return nil, err
+	}
+
+	return quiz, nil
+}
+
+func (s *quizService) GetQuiz(c context.Context, id string, userID string) (*Quiz, error) {
+	return s.getOwnedQuiz(c, id, userID)
+}
+
+// SubmitQuiz grades the answers and stores the attempt. A quiz can only be
+// submitted once; unanswered questions count as wrong.
+func (s *quizService) SubmitQuiz(c context.Context, id string, userID string, req *SubmitQuizRequest) (*Attempt, error) {
+
+	quiz, err := s.getOwnedQuiz(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz.SubmittedAt != nil {
+		return nil, fmt.Errorf("quiz has already been submitted")
+	}
+
+	answers := make(map[string]string, len(req.Answers))
+	for _, answer := range req.Answers {
+		answers[answer.QuestionID] = answer.Answer
+	}
+
+	now := time.Now()
+	attempt := &Attempt{
+		ID:          primitive.NewObjectID(),
+		QuizID:      quiz.ID,
+		TopicID:     quiz.TopicID,
+		UserID:      quiz.UserID,
+		Answers:     make([]AttemptAnswer, 0, len(quiz.Questions)),
+		Total:       len(quiz.Questions),
+		SubmittedAt: now,
+	}
+
+	for i := range quiz.Questions {
+		question := &quiz.Questions[i]
+		answer := answers[question.ID]
+		correct := gradeAnswer(question, answer)
+		if correct {
+			attempt.Score++
+		}
+
+		attempt.Answers = append(attempt.Answers, AttemptAnswer{
+			QuestionID: question.ID,
+			Type:       question.Type,
+			WordID:     question.WordID,
+			Answer:     answer,
+			Expected:   question.Answer,
+			Correct:    correct,
+		})
+	}
+
+	if attempt.Total > 0 {
+		attempt.Percent = float64(attempt.Score) / float64(attempt.Total) * 100
+	}
+
+	submitted, err := s.quizRepository.MarkSubmitted(c, quiz.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !submitted {
+		return nil, fmt.Errorf("quiz has already been submitted")
+	}
+
+	if err := s.attemptRepository.CreateAttempt(c, attempt); err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+func (s *quizService) GetAttempts(c context.Context, topicID string, userID string, req *ListAttemptsRequest) ([]*Attempt, error) {
+
+	objectTopicID, err := primitive.ObjectIDFromHex(topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	return s.attemptRepository.GetAttempts(c, objectTopicID, objectUserID, int64(req.Limit))
+}
+
+func (s *quizService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attemptRepository.DeleteAttemptsByUserID(c, objectID); err != nil {
+		return err
+	}
+
+	return s.quizRepository.DeleteQuizzesByUserID(c, objectID)
+}
+
+func (s *quizService) getOwnedQuiz(c context.Context, id string, userID string) (*Quiz, error) {
+
+	if id == "" {
+		return nil, fmt.Errorf("quiz id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz, err := s.quizRepository.GetQuizByID(c, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: quiz not found", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz.UserID != objectUserID {
+		return nil, fmt.Errorf("%w: quiz not found", helper.ErrResourceNotFound)
+	}
+
+	return quiz, nil
+}
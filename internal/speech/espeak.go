@@ -0,0 +1,68 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type espeakProvider struct {
+	binary  string
+	timeout time.Duration
+}
+
+// NewEspeakProvider synthesizes speech locally by running espeak-ng, so it
+// works without network access. The text is passed on stdin and the WAV
+// output is read from stdout.
+func NewEspeakProvider(binary string, timeout time.Duration) TTSProvider {
+
+	if binary == "" {
+		binary = "espeak-ng"
+	}
+
+	return &espeakProvider{binary: binary, timeout: timeout}
+}
+
+func (p *espeakProvider) Synthesize(c context.Context, text string, language string, voice string) ([]byte, string, error) {
+
+	if voice == "" {
+		voice = language
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, p.timeout)
+		defer cancel()
+	}
+
+	args := []string{"--stdout", "--stdin"}
+	if voice != "" {
+		args = append(args, "-v", voice)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(c, p.binary, args...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, "", fmt.Errorf("espeak-ng failed: %w: %s", err, message)
+		}
+		return nil, "", fmt.Errorf("espeak-ng failed: %w", err)
+	}
+
+	if stdout.Len() == 0 {
+		return nil, "", fmt.Errorf("espeak-ng produced no audio for voice %q", voice)
+	}
+
+	return stdout.Bytes(), "audio/wav", nil
+}
+
+func (p *espeakProvider) Name() string {
+	return ProviderEspeak
+}
@@ -0,0 +1,33 @@
+package speech
+
+import (
+	"flashcard/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SpeechHandler struct {
+	SpeechService SpeechService
+}
+
+func NewSpeechHandler(speechService SpeechService) *SpeechHandler {
+	return &SpeechHandler{SpeechService: speechService}
+}
+
+// GetClip streams a synthesized clip. Clips are content-addressed and never
+// change, so clients may cache them indefinitely.
+func (h *SpeechHandler) GetClip(c *gin.Context) {
+
+	reader, clip, err := h.SpeechService.OpenClip(c, c.Param("clip_id"))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, clip.Size, clip.ContentType, reader, map[string]string{
+		"Cache-Control": "private, max-age=31536000, immutable",
+	})
+
+}
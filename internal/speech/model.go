@@ -0,0 +1,29 @@
+package speech
+
+import (
+	"time"
+)
+
+// Clip is synthesized audio for one text, stored in the blob store under a
+// hash of the provider, text, language and voice so each combination is only
+// generated once and can be shared by any number of words.
+type Clip struct {
+	ID          string    `json:"id" bson:"_id"`
+	Text        string    `json:"text" bson:"text"`
+	Language    string    `json:"language" bson:"language"`
+	Voice       string    `json:"voice" bson:"voice"`
+	Provider    string    `json:"provider" bson:"provider"`
+	Key         string    `json:"-" bson:"key"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// URL is where clients fetch the clip's audio.
+func (c *Clip) URL() string {
+	return ClipURL(c.ID)
+}
+
+func ClipURL(id string) string {
+	return "/api/v1/speech/" + id
+}
@@ -0,0 +1,31 @@
+package speech
+
+import (
+	"context"
+	"flashcard/config"
+	"fmt"
+	"strings"
+)
+
+const ProviderEspeak = "espeak"
+
+// TTSProvider turns text into audio. An empty voice asks the provider for
+// its default voice of the language.
+type TTSProvider interface {
+	Synthesize(c context.Context, text string, language string, voice string) ([]byte, string, error)
+	Name() string
+}
+
+// NewTTSProvider builds the provider named by TTS_PROVIDER. It returns nil
+// when text-to-speech is not configured.
+func NewTTSProvider(cfg *config.Config) (TTSProvider, error) {
+
+	switch strings.ToLower(cfg.TTSProvider) {
+	case "":
+		return nil, nil
+	case ProviderEspeak:
+		return NewEspeakProvider(cfg.TTSBinary, cfg.TTSTimeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported text-to-speech provider %q", cfg.TTSProvider)
+	}
+}
@@ -0,0 +1,47 @@
+package speech
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ClipRepository interface {
+	GetClip(c context.Context, id string) (*Clip, error)
+	SaveClip(c context.Context, clip *Clip) error
+}
+
+type clipRepository struct {
+	collection *mongo.Collection
+}
+
+func NewClipRepository(collection *mongo.Collection) ClipRepository {
+	return &clipRepository{collection: collection}
+}
+
+func (r *clipRepository) GetClip(c context.Context, id string) (*Clip, error) {
+
+	var clip Clip
+
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&clip)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &clip, nil
+}
+
+func (r *clipRepository) SaveClip(c context.Context, clip *Clip) error {
+
+	_, err := r.collection.ReplaceOne(c, bson.M{"_id": clip.ID}, clip, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package speech
+
+import (
+	"flashcard/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, handler *SpeechHandler) {
+
+	speechGroup := r.Group("/api/v1/speech")
+	{
+		speechGroup.GET("/:clip_id", middleware.JWTAuthMiddleware(), handler.GetClip)
+	}
+
+}
@@ -0,0 +1,125 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flashcard/helper"
+	"flashcard/internal/storage"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxSpeechLength = 500
+
+type SpeechService interface {
+	Synthesize(c context.Context, text string, language string, voice string) (*Clip, error)
+	OpenClip(c context.Context, id string) (io.ReadCloser, *Clip, error)
+}
+
+type speechService struct {
+	provider       TTSProvider
+	clipRepository ClipRepository
+	blobStore      storage.BlobStore
+	defaultVoice   string
+}
+
+func NewSpeechService(provider TTSProvider, clipRepository ClipRepository, blobStore storage.BlobStore, defaultVoice string) SpeechService {
+	return &speechService{
+		provider:       provider,
+		clipRepository: clipRepository,
+		blobStore:      blobStore,
+		defaultVoice:   defaultVoice,
+	}
+}
+
+// Synthesize returns the clip for the text, generating and storing it on the
+// first request. An empty voice falls back to the configured default voice.
+func (s *speechService) Synthesize(c context.Context, text string, language string, voice string) (*Clip, error) {
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+
+	if utf8.RuneCountInString(text) > maxSpeechLength {
+		return nil, fmt.Errorf("text must be at most %d characters", maxSpeechLength)
+	}
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	voice = strings.TrimSpace(voice)
+	if voice == "" {
+		voice = s.defaultVoice
+	}
+
+	id := clipID(s.provider.Name(), text, language, voice)
+
+	clip, err := s.clipRepository.GetClip(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if clip != nil {
+		return clip, nil
+	}
+
+	audio, contentType, err := s.provider.Synthesize(c, text, language, voice)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "speech/" + id
+	size, err := s.blobStore.Put(c, key, bytes.NewReader(audio))
+	if err != nil {
+		return nil, err
+	}
+
+	clip = &Clip{
+		ID:          id,
+		Text:        text,
+		Language:    language,
+		Voice:       voice,
+		Provider:    s.provider.Name(),
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.clipRepository.SaveClip(c, clip); err != nil {
+		return nil, err
+	}
+
+	return clip, nil
+}
+
+func (s *speechService) OpenClip(c context.Context, id string) (io.ReadCloser, *Clip, error) {
+
+	clip, err := s.clipRepository.GetClip(c, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if clip == nil {
+		return nil, nil, fmt.Errorf("%w: speech clip not found", helper.ErrResourceNotFound)
+	}
+
+	reader, err := s.blobStore.Open(c, clip.Key)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, fmt.Errorf("%w: speech audio is missing", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reader, clip, nil
+}
+
+func clipID(provider, text, language, voice string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + language + "\x00" + voice + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps binary attachments such as audio recordings. Keys are
+// slash-separated paths chosen by the caller.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type localBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) (BlobStore, error) {
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	return &localBlobStore{root: root}, nil
+}
+
+func (s *localBlobStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	// Write to a temporary file first so readers never see a partial blob.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (s *localBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (s *localBlobStore) Delete(ctx context.Context, key string) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *localBlobStore) path(key string) (string, error) {
+
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.root, cleaned), nil
+}
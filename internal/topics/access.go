@@ -0,0 +1,173 @@
+package topics
+
+import (
+	"context"
+	"errors"
+	"flashcard/helper"
+	"flashcard/internal/words"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// roleRank orders roles so that a higher role has every permission of the
+// roles below it.
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+type topicAccess struct {
+	topicRepository    TopicRepository
+	activityRepository TopicActivityRepository
+}
+
+func NewTopicAccess(topicRepository TopicRepository, activityRepository TopicActivityRepository) words.TopicAccess {
+	return &topicAccess{
+		topicRepository:    topicRepository,
+		activityRepository: activityRepository,
+	}
+}
+
+func (a *topicAccess) CanViewTopic(c context.Context, topicID, userID primitive.ObjectID) error {
+	return a.authorize(c, topicID, userID, RoleViewer)
+}
+
+func (a *topicAccess) CanEditTopic(c context.Context, topicID, userID primitive.ObjectID) error {
+	return a.authorize(c, topicID, userID, RoleEditor)
+}
+
+func (a *topicAccess) CanOwnTopic(c context.Context, topicID, userID primitive.ObjectID) error {
+	return a.authorize(c, topicID, userID, RoleOwner)
+}
+
+// TopicOwnerID returns the owner of a topic regardless of the caller's role;
+// it is used for housekeeping, not to authorize requests.
+func (a *topicAccess) TopicOwnerID(c context.Context, topicID primitive.ObjectID) (primitive.ObjectID, error) {
+
+	topic, err := findTopic(c, a.topicRepository, topicID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return topic.UserID, nil
+}
+
+// AccessibleTopicIDs returns the topics the user owns or has joined.
+func (a *topicAccess) AccessibleTopicIDs(c context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+
+	owned, err := a.topicRepository.GetTopicsByUserID(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := a.topicRepository.GetTopicsByMember(c, userID, MemberStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(owned)+len(joined))
+	for _, topic := range append(owned, joined...) {
+		ids = append(ids, topic.ID)
+	}
+
+	return ids, nil
+}
+
+// GetTopicLanguages returns the language a topic's words are in and the
+// language they are studied into.
+func (a *topicAccess) GetTopicLanguages(c context.Context, topicID primitive.ObjectID) (string, string, error) {
+
+	topic, err := findTopic(c, a.topicRepository, topicID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return topic.Language, topic.TargetLanguage, nil
+}
+
+func (a *topicAccess) RecordWordActivity(c context.Context, topicID, userID primitive.ObjectID, action string, word *words.Word) error {
+
+	wordID := word.ID
+	activity := &TopicActivity{
+		ID:        primitive.NewObjectID(),
+		TopicID:   topicID,
+		UserID:    userID,
+		Action:    action,
+		WordID:    &wordID,
+		Word:      word.Word,
+		CreatedAt: time.Now(),
+	}
+
+	return a.activityRepository.CreateActivity(c, activity)
+}
+
+func (a *topicAccess) authorize(c context.Context, topicID, userID primitive.ObjectID, required string) error {
+
+	topic, err := findTopic(c, a.topicRepository, topicID)
+	if err != nil {
+		return err
+	}
+
+	return authorizeTopic(topic, userID, required)
+}
+
+func findTopic(c context.Context, topicRepository TopicRepository, id primitive.ObjectID) (*Topic, error) {
+
+	topic, err := topicRepository.GetTopicByID(c, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: topic not found", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return topic, nil
+}
+
+// authorizeTopic reports topics the user has no role on as not found, so
+// private topic IDs cannot be probed through the API.
+func authorizeTopic(topic *Topic, userID primitive.ObjectID, required string) error {
+
+	role := memberRole(topic, userID)
+	if role == "" {
+		return fmt.Errorf("%w: topic not found", helper.ErrResourceNotFound)
+	}
+
+	if roleRank[role] < roleRank[required] {
+		return fmt.Errorf("%w: %s role required", helper.ErrPermissionDenied, required)
+	}
+
+	return nil
+}
+
+func memberRole(topic *Topic, userID primitive.ObjectID) string {
+
+	if topic.UserID == userID {
+		return RoleOwner
+	}
+
+	for _, member := range topic.Members {
+		if member.UserID == userID && member.Status == MemberStatusAccepted {
+			return member.Role
+		}
+	}
+
+	return ""
+}
+
+func findMember(topic *Topic, userID primitive.ObjectID) *TopicMember {
+	for i := range topic.Members {
+		if topic.Members[i].UserID == userID {
+			return &topic.Members[i]
+		}
+	}
+	return nil
+}
+
+func isAssignableRole(role string) bool {
+	return role == RoleEditor || role == RoleViewer
+}
@@ -0,0 +1,78 @@
+package topics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TopicActivityRepository interface {
+	CreateActivity(c context.Context, activity *TopicActivity) error
+	GetActivitiesByTopicID(c context.Context, topicID primitive.ObjectID, limit int) ([]*TopicActivity, error)
+	DeleteActivitiesByTopicID(c context.Context, topicID primitive.ObjectID) error
+	DeleteActivitiesByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type topicActivityRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTopicActivityRepository(collection *mongo.Collection) TopicActivityRepository {
+	return &topicActivityRepository{collection: collection}
+}
+
+func (r *topicActivityRepository) CreateActivity(c context.Context, activity *TopicActivity) error {
+	_, err := r.collection.InsertOne(c, activity)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *topicActivityRepository) GetActivitiesByTopicID(c context.Context, topicID primitive.ObjectID, limit int) ([]*TopicActivity, error) {
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	var activities []*TopicActivity
+
+	cursor, err := r.collection.Find(c, bson.M{"topic_id": topicID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var activity TopicActivity
+		if err := cursor.Decode(&activity); err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+
+	return activities, nil
+}
+
+func (r *topicActivityRepository) DeleteActivitiesByTopicID(c context.Context, topicID primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteMany(c, bson.M{"topic_id": topicID})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *topicActivityRepository) DeleteActivitiesByUserID(c context.Context, userID primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
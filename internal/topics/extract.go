@@ -0,0 +1,104 @@
+package topics
+
+import (
+	"context"
+	"flashcard/internal/extraction"
+	"flashcard/internal/words"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxExtractTextBytes   = 512 << 10
+	defaultExtractLimit   = 50
+	maxExtractSuggestions = 500
+)
+
+// ExtractWords suggests new words for a topic from a block of text. Words
+// already in the topic or anywhere in the user's own collection are left out,
+// and each suggestion carries the sentence it was first seen in as its
+// example.
+func (s *topicService) ExtractWords(c context.Context, id string, userID string, req *ExtractWordsRequest) (*ExtractWordsResponse, error) {
+
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+
+	if len(req.Text) > maxExtractTextBytes {
+		return nil, fmt.Errorf("text must be at most %d bytes", maxExtractTextBytes)
+	}
+
+	if req.Limit < 1 {
+		req.Limit = defaultExtractLimit
+	}
+	if req.Limit > maxExtractSuggestions {
+		req.Limit = maxExtractSuggestions
+	}
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := s.knownWords(c, userID, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, tokenCount := extraction.Extract([]extraction.Segment{{Text: req.Text}}, extraction.Options{
+		Language:  topic.Language,
+		Known:     known,
+		MinLength: req.MinLength,
+		Limit:     req.Limit,
+	})
+
+	response := &ExtractWordsResponse{
+		TokenCount:  tokenCount,
+		Suggestions: make([]*WordSuggestion, 0, len(candidates)),
+	}
+	for _, candidate := range candidates {
+		response.Suggestions = append(response.Suggestions, &WordSuggestion{
+			CreateWordRequest: suggestWord(topic.ID.Hex(), candidate, req.Autofill),
+			Frequency:         candidate.Frequency,
+		})
+	}
+
+	return response, nil
+}
+
+// knownWords collects the normalized words of every topic the user owns and,
+// when given, of the topic being added to.
+func (s *topicService) knownWords(c context.Context, userID string, topic *Topic) (map[string]bool, error) {
+
+	existing, err := s.wordService.GetWordsByUserID(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if topic != nil {
+		topicWords, err := s.wordService.GetWordsByTopicID(c, topic.ID.Hex(), &words.SearchWordRequest{})
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, topicWords...)
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, word := range existing {
+		known[extraction.Normalize(word.Word)] = true
+	}
+
+	return known, nil
+}
+
+func suggestWord(topicID string, candidate *extraction.Candidate, autofill bool) *words.CreateWordRequest {
+
+	example := candidate.Sentence
+	return &words.CreateWordRequest{
+		TopicID:  topicID,
+		Word:     candidate.Word,
+		Example:  &example,
+		Tags:     []string{},
+		Autofill: autofill,
+	}
+}
@@ -2,6 +2,7 @@ package topics
 
 import (
 	"flashcard/helper"
+	"flashcard/internal/words"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -32,19 +33,26 @@ func (h *TopicHandler) CreateTopic(c *gin.Context) {
 	err := h.TopicService.CreateTopic(c, &req, userID.(string))
 
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
 	helper.SendSuccess(c, http.StatusCreated, "success", nil)
 }
 
+// GetAllTopics lists the topics the caller owns or is a member of. Public
+// topics are listed by SearchPublicTopics.
 func (h *TopicHandler) GetAllTopics(c *gin.Context) {
 
-	topics, err := h.TopicService.GetAllTopics(c)
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
 
+	topics, err := h.TopicService.GetAccessibleTopics(c, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -53,11 +61,21 @@ func (h *TopicHandler) GetAllTopics(c *gin.Context) {
 
 func (h *TopicHandler) GetTopicByID(c *gin.Context) {
 	
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	id := c.Param("topic_id")
 
-	topic, err := h.TopicService.GetTopicByID(c, id)
+	topic, err := h.TopicService.GetTopicByID(c, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	if helper.NotModified(c, topic.Version) {
 		return
 	}
 
@@ -73,9 +91,15 @@ func (h *TopicHandler) GetAllTopicsByUser(c *gin.Context) {
 		return
 	}
 
-	topics, err := h.TopicService.GetTopicsByUserID(c, userID.(string))
+	var req ListTopicsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	topics, err := h.TopicService.GetTopicsByUserID(c, userID.(string), &req)
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -84,6 +108,12 @@ func (h *TopicHandler) GetAllTopicsByUser(c *gin.Context) {
 }
 func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	id := c.Param("topic_id")
 
 	var req UpdateTopicRequest
@@ -92,25 +122,428 @@ func (h *TopicHandler) UpdateTopic(c *gin.Context) {
 		return
 	}
 
-	err := h.TopicService.UpdateTopic(c, id, &req)
+	expectedVersion, err := helper.ParseIfMatch(c)
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
 		return
 	}
+	req.ExpectedVersion = expectedVersion
 
-	helper.SendSuccess(c, http.StatusOK, "success", nil)
+	topic, err := h.TopicService.UpdateTopic(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SetETag(c, topic.Version)
+	helper.SendSuccess(c, http.StatusOK, "success", topic)
 }
 
 func (h *TopicHandler) DeleteTopic(c *gin.Context) {
 
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	err := h.TopicService.DeleteTopic(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+func (h *TopicHandler) ShareTopic(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	id := c.Param("topic_id")
 
-	err := h.TopicService.DeleteTopic(c, id)
+	link, err := h.TopicService.ShareTopic(c, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", link)
+}
+
+func (h *TopicHandler) RevokeShareLink(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	err := h.TopicService.RevokeShareLink(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
 		return
 	}
 
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *TopicHandler) CloneTopic(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	topic, err := h.TopicService.CloneTopic(c, id, userID.(string), c.Query("share_token"))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", topic)
+}
+
+func (h *TopicHandler) SearchPublicTopics(c *gin.Context) {
 
-}
\ No newline at end of file
+	var req SearchPublicTopicsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	topics, err := h.TopicService.SearchPublicTopics(c, &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", topics)
+}
+
+func (h *TopicHandler) GetPublicTopic(c *gin.Context) {
+
+	id := c.Param("topic_id")
+
+	topic, err := h.TopicService.GetPublicTopic(c, id)
+	if err != nil {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", topic)
+}
+
+func (h *TopicHandler) GetSharedTopic(c *gin.Context) {
+
+	token := c.Param("share_token")
+
+	topic, err := h.TopicService.GetSharedTopic(c, token)
+	if err != nil {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", topic)
+}
+
+func (h *TopicHandler) GetUpstreamChanges(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	changes, err := h.TopicService.GetUpstreamChanges(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", changes)
+}
+
+func (h *TopicHandler) MergeUpstream(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req words.MergeUpstreamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	applied, err := h.TopicService.MergeUpstream(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", applied)
+}
+
+func (h *TopicHandler) GetMembers(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	members, err := h.TopicService.GetMembers(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", members)
+}
+
+func (h *TopicHandler) InviteMember(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	member, err := h.TopicService.InviteMember(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", member)
+}
+
+func (h *TopicHandler) UpdateMemberRole(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("topic_id")
+	memberID := c.Param("user_id")
+
+	err := h.TopicService.UpdateMemberRole(c, id, userID.(string), memberID, &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *TopicHandler) RemoveMember(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+	memberID := c.Param("user_id")
+
+	err := h.TopicService.RemoveMember(c, id, userID.(string), memberID)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *TopicHandler) AcceptInvitation(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	err := h.TopicService.AcceptInvitation(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *TopicHandler) GetInvitations(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	invitations, err := h.TopicService.GetInvitations(c, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", invitations)
+}
+
+func (h *TopicHandler) GetActivity(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req ListActivityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	activity, err := h.TopicService.GetActivity(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", activity)
+}
+
+func (h *TopicHandler) MoveTopic(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req MoveTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	err := h.TopicService.MoveTopic(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *TopicHandler) GetFolderProgress(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	progress, err := h.TopicService.GetFolderProgress(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", progress)
+}
+
+func (h *TopicHandler) ExtractWords(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req ExtractWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("topic_id")
+
+	extracted, err := h.TopicService.ExtractWords(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", extracted)
+}
+
+// ImportMedia takes the subtitle or e-book file as the raw request body and
+// creates a topic with the words found in it.
+func (h *TopicHandler) ImportMedia(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req ImportMediaRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	imported, err := h.TopicService.ImportMedia(c, userID.(string), &req, c.Request.Body)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusCreated, "success", imported)
+}
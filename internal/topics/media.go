@@ -0,0 +1,149 @@
+package topics
+
+import (
+	"bytes"
+	"context"
+	"flashcard/internal/extraction"
+	"flashcard/internal/words"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxMediaImportBytes = 32 << 20
+	defaultImportLimit  = 200
+)
+
+const (
+	MediaFormatSRT  = "srt"
+	MediaFormatVTT  = "vtt"
+	MediaFormatEPUB = "epub"
+)
+
+// ImportMedia mines a subtitle file or e-book for words the user does not
+// have yet and creates a topic for them. Each word keeps the sentence it was
+// first seen in as its example, and the timestamp or chapter as its context.
+func (s *topicService) ImportMedia(c context.Context, userID string, req *ImportMediaRequest, body io.Reader) (*ImportMediaResponse, error) {
+
+	fileName := path.Base(strings.ReplaceAll(strings.TrimSpace(req.FileName), "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		fileName = ""
+	}
+
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(path.Ext(fileName)), ".")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxMediaImportBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 || len(data) > maxMediaImportBytes {
+		return nil, fmt.Errorf("file must be between 1 and %d bytes", maxMediaImportBytes)
+	}
+
+	segments, title, err := parseMedia(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	source := fileName
+	if source == "" {
+		source = title
+	}
+
+	topicName := strings.TrimSpace(req.TopicName)
+	if topicName == "" {
+		topicName = title
+	}
+	if topicName == "" {
+		topicName = strings.TrimSuffix(fileName, path.Ext(fileName))
+	}
+	if topicName == "" {
+		return nil, fmt.Errorf("topic name is required")
+	}
+
+	if req.Limit < 1 {
+		req.Limit = defaultImportLimit
+	}
+	if req.Limit > maxExtractSuggestions {
+		req.Limit = maxExtractSuggestions
+	}
+
+	known, err := s.knownWords(c, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, tokenCount := extraction.Extract(segments, extraction.Options{
+		Language:  req.Language,
+		Known:     known,
+		MinLength: req.MinLength,
+		Limit:     req.Limit,
+	})
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no new words found in %s", topicName)
+	}
+
+	topicID := primitive.NewObjectID().Hex()
+	err = s.CreateTopic(c, &CreateTopicRequest{
+		ID:               topicID,
+		TopicName:        topicName,
+		TopicDescription: fmt.Sprintf("Vocabulary from %s", topicName),
+		Language:         req.Language,
+		TargetLanguage:   req.TargetLanguage,
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := make([]words.BatchWordOperation, 0, len(candidates))
+	for _, candidate := range candidates {
+		wordReq := suggestWord(topicID, candidate, req.Autofill)
+		wordReq.Context = &words.WordContext{Source: source, Reference: candidate.Reference}
+		operations = append(operations, words.BatchWordOperation{Op: words.BatchOpCreate, Word: wordReq})
+	}
+
+	created, err := s.wordService.BatchWords(c, userID, &words.BatchWordRequest{Operations: operations})
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := s.GetTopicByID(c, topicID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportMediaResponse{Topic: topic, TokenCount: tokenCount, Words: created}, nil
+}
+
+// parseMedia splits a file into referenced segments and returns the title
+// it declares, if any.
+func parseMedia(format string, data []byte) ([]extraction.Segment, string, error) {
+
+	switch format {
+	case MediaFormatSRT:
+		segments, err := extraction.ParseSRT(bytes.NewReader(data))
+		return segments, "", err
+	case MediaFormatVTT:
+		segments, err := extraction.ParseVTT(bytes.NewReader(data))
+		return segments, "", err
+	case MediaFormatEPUB:
+		book, err := extraction.ParseEPUB(data)
+		if err != nil {
+			return nil, "", err
+		}
+		return book.Segments, book.Title, nil
+	case "":
+		return nil, "", fmt.Errorf("file format is required")
+	default:
+		return nil, "", fmt.Errorf("unsupported file format %q", format)
+	}
+}
@@ -7,11 +7,63 @@ import (
 )
 
 type Topic struct {
-	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	TopicName        string             `json:"name" bson:"name"`
+	ID               primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TopicName        string              `json:"name" bson:"name"`
 	TopicDescription *string             `json:"description" bson:"description"`
-	Color            string             `json:"color" bson:"color"`
-	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
-	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+	Color            string              `json:"color" bson:"color"`
+	UserID           primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	ParentID         *primitive.ObjectID `json:"parent_id" bson:"parent_id,omitempty"`
+	Visibility       string              `json:"visibility" bson:"visibility"`
+	ShareToken       string              `json:"share_token,omitempty" bson:"share_token,omitempty"`
+	Language         string              `json:"language" bson:"language"`
+	TargetLanguage   string              `json:"target_language,omitempty" bson:"target_language,omitempty"`
+	Tags             []string            `json:"tags" bson:"tags"`
+	SourceTopicID    *primitive.ObjectID `json:"source_topic_id,omitempty" bson:"source_topic_id,omitempty"`
+	ClonedAt         *time.Time          `json:"cloned_at,omitempty" bson:"cloned_at,omitempty"`
+	CloneCount       int                 `json:"clone_count" bson:"clone_count"`
+	Revision         int64               `json:"revision" bson:"revision"`
+	Version          int64               `json:"version" bson:"version"`
+	SourceRevision   int64               `json:"source_revision,omitempty" bson:"source_revision,omitempty"`
+	Members          []TopicMember       `json:"members,omitempty" bson:"members,omitempty"`
+	CreatedAt        time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+const (
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPublic   = "public"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+const (
+	MemberStatusPending  = "pending"
+	MemberStatusAccepted = "accepted"
+)
+
+// TopicMember grants another user access to a topic. The owner is never
+// stored as a member; ownership is always Topic.UserID.
+type TopicMember struct {
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Email     string             `json:"email" bson:"email"`
+	Role      string             `json:"role" bson:"role"`
+	Status    string             `json:"status" bson:"status"`
+	InvitedBy primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	InvitedAt time.Time          `json:"invited_at" bson:"invited_at"`
+	JoinedAt  *time.Time         `json:"joined_at,omitempty" bson:"joined_at,omitempty"`
+}
+
+type TopicActivity struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	TopicID   primitive.ObjectID  `json:"topic_id" bson:"topic_id"`
+	UserID    primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	Action    string              `json:"action" bson:"action"`
+	WordID    *primitive.ObjectID `json:"word_id,omitempty" bson:"word_id,omitempty"`
+	Word      string              `json:"word,omitempty" bson:"word,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
 }
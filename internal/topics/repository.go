@@ -2,19 +2,36 @@ package topics
 
 import (
 	"context"
+	"flashcard/helper"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TopicRepository interface {
 	CreateTopic(c context.Context, topic *Topic) error
-	GetAllTopics(c context.Context) ([]*Topic, error)
 	GetTopicByID(c context.Context, id primitive.ObjectID) (*Topic, error)
 	GetTopicsByUserID(c context.Context, userID primitive.ObjectID) ([]*Topic, error)
-	UpdateTopic(c context.Context, id primitive.ObjectID, topic *Topic) error
+	UpdateTopicFields(c context.Context, id primitive.ObjectID, expectedVersion int64, set bson.M, unset bson.M) (bool, error)
 	DeleteTopic(c context.Context, id primitive.ObjectID) error
+	DeleteTopicsByUserID(c context.Context, userID primitive.ObjectID) error
+	SearchPublicTopics(c context.Context, req *SearchPublicTopicsRequest) ([]*Topic, error)
+	GetTopicByShareToken(c context.Context, token string) (*Topic, error)
+	IncrementCloneCount(c context.Context, id primitive.ObjectID) error
+	IncrementRevision(c context.Context, id primitive.ObjectID) error
+	GetTopicsByMember(c context.Context, userID primitive.ObjectID, status string) ([]*Topic, error)
+	AddMember(c context.Context, id primitive.ObjectID, member *TopicMember) error
+	UpdateMember(c context.Context, id primitive.ObjectID, userID primitive.ObjectID, fields bson.M) error
+	RemoveMember(c context.Context, id primitive.ObjectID, userID primitive.ObjectID) error
+	RemoveMemberFromAllTopics(c context.Context, userID primitive.ObjectID) error
+	ReparentTopics(c context.Context, parentID primitive.ObjectID, newParentID *primitive.ObjectID) error
+	SetParent(c context.Context, id primitive.ObjectID, parentID *primitive.ObjectID) error
 }
 
 type topicRepository struct {
@@ -33,11 +50,24 @@ func (r *topicRepository) CreateTopic(c context.Context, topic *Topic) error {
 	return nil
 }
 
-func (r *topicRepository) GetAllTopics(c context.Context) ([]*Topic, error) {
+func (r *topicRepository) GetTopicByID(c context.Context, id primitive.ObjectID) (*Topic, error) {
+
+	var topic Topic
+	
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&topic)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &topic, nil
+
+}
+
+func (r *topicRepository) GetTopicsByUserID(c context.Context, userID primitive.ObjectID) ([]*Topic, error) {
 
 	var topics []*Topic
 	
-	cursor, err := r.collection.Find(c, bson.M{})
+	cursor, err := r.collection.Find(c, bson.M{"user_id": userID})
 	if err != nil {
 		return nil, err
 	}
@@ -52,32 +82,129 @@ func (r *topicRepository) GetAllTopics(c context.Context) ([]*Topic, error) {
 	}
 
 	return topics, nil
+}
+// UpdateTopicFields applies a field-level update only if the stored topic is
+// still at expectedVersion, and reports whether it was.
+func (r *topicRepository) UpdateTopicFields(c context.Context, id primitive.ObjectID, expectedVersion int64, set bson.M, unset bson.M) (bool, error) {
+
+	result, err := r.collection.UpdateOne(c, helper.VersionFilter(id, expectedVersion), helper.VersionedUpdate(set, unset))
+	if err != nil {
+		return false, err
+	}
 
+	return result.MatchedCount > 0, nil
 }
 
-func (r *topicRepository) GetTopicByID(c context.Context, id primitive.ObjectID) (*Topic, error) {
+func (r *topicRepository) DeleteTopic(c context.Context, id primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteOne(c, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *topicRepository) DeleteTopicsByUserID(c context.Context, userID primitive.ObjectID) error {
+
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *topicRepository) SearchPublicTopics(c context.Context, req *SearchPublicTopicsRequest) ([]*Topic, error) {
+
+	filter := bson.M{"visibility": VisibilityPublic}
+	if req.Query != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(req.Query), "$options": "i"}
+	}
+	if req.Language != "" {
+		filter["language"] = strings.ToLower(req.Language)
+	}
+	if req.Tag != "" {
+		filter["tags"] = strings.ToLower(req.Tag)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "clone_count", Value: -1}, {Key: "created_at", Value: -1}}).
+		SetSkip(int64((req.Page - 1) * req.Limit)).
+		SetLimit(int64(req.Limit))
+
+	var topics []*Topic
+
+	cursor, err := r.collection.Find(c, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var topic Topic
+		if err := cursor.Decode(&topic); err != nil {
+			return nil, err
+		}
+		topics = append(topics, &topic)
+	}
+
+	return topics, nil
+}
+
+func (r *topicRepository) GetTopicByShareToken(c context.Context, token string) (*Topic, error) {
 
 	var topic Topic
-	
-	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&topic)
+
+	err := r.collection.FindOne(c, bson.M{"share_token": token}).Decode(&topic)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	return &topic, nil
 
 }
 
-func (r *topicRepository) GetTopicsByUserID(c context.Context, userID primitive.ObjectID) ([]*Topic, error) {
+func (r *topicRepository) IncrementCloneCount(c context.Context, id primitive.ObjectID) error {
+
+	update := bumpVersion(bson.M{"updated_at": time.Now()})
+	update["$inc"].(bson.M)["clone_count"] = 1
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *topicRepository) IncrementRevision(c context.Context, id primitive.ObjectID) error {
+
+	update := bumpVersion(bson.M{"updated_at": time.Now()})
+	update["$inc"].(bson.M)["revision"] = 1
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *topicRepository) GetTopicsByMember(c context.Context, userID primitive.ObjectID, status string) ([]*Topic, error) {
+
+	filter := bson.M{
+		"members": bson.M{"$elemMatch": bson.M{"user_id": userID, "status": status}},
+	}
 
 	var topics []*Topic
-	
-	cursor, err := r.collection.Find(c, bson.M{"user_id": userID})
+
+	cursor, err := r.collection.Find(c, filter)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(c)
-	
+
 	for cursor.Next(c) {
 		var topic Topic
 		if err := cursor.Decode(&topic); err != nil {
@@ -88,22 +215,50 @@ func (r *topicRepository) GetTopicsByUserID(c context.Context, userID primitive.
 
 	return topics, nil
 }
-func (r *topicRepository) UpdateTopic(c context.Context, id primitive.ObjectID, topic *Topic) error {
 
-	filter := bson.M{"_id": id}
-	update := bson.M{"$set": topic}
+func (r *topicRepository) AddMember(c context.Context, id primitive.ObjectID, member *TopicMember) error {
 
-	_, err := r.collection.UpdateOne(c, filter, update)
+	filter := bson.M{"_id": id, "members.user_id": bson.M{"$ne": member.UserID}}
+	update := bumpVersion(bson.M{"updated_at": time.Now()})
+	update["$push"] = bson.M{"members": member}
+
+	result, err := r.collection.UpdateOne(c, filter, update)
 	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user is already a member of this topic")
+	}
+
 	return nil
 }
 
-func (r *topicRepository) DeleteTopic(c context.Context, id primitive.ObjectID) error {
+func (r *topicRepository) UpdateMember(c context.Context, id primitive.ObjectID, userID primitive.ObjectID, fields bson.M) error {
 
-	_, err := r.collection.DeleteOne(c, bson.M{"_id": id})
+	set := bson.M{"updated_at": time.Now()}
+	for key, value := range fields {
+		set["members.$."+key] = value
+	}
+
+	result, err := r.collection.UpdateOne(c, bson.M{"_id": id, "members.user_id": userID}, bumpVersion(set))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
+
+func (r *topicRepository) RemoveMember(c context.Context, id primitive.ObjectID, userID primitive.ObjectID) error {
+
+	update := bumpVersion(bson.M{"updated_at": time.Now()})
+	update["$pull"] = bson.M{"members": bson.M{"user_id": userID}}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
 	if err != nil {
 		return err
 	}
@@ -111,3 +266,69 @@ func (r *topicRepository) DeleteTopic(c context.Context, id primitive.ObjectID)
 
 }
 
+func (r *topicRepository) RemoveMemberFromAllTopics(c context.Context, userID primitive.ObjectID) error {
+
+	update := bumpVersion(bson.M{"updated_at": time.Now()})
+	update["$pull"] = bson.M{"members": bson.M{"user_id": userID}}
+
+	_, err := r.collection.UpdateMany(c, bson.M{"members.user_id": userID}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+func (r *topicRepository) ReparentTopics(c context.Context, parentID primitive.ObjectID, newParentID *primitive.ObjectID) error {
+
+	update := bson.M{
+		"$unset": bson.M{"parent_id": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$inc":   bson.M{"version": 1},
+	}
+	if newParentID != nil {
+		update = bson.M{
+			"$set": bson.M{"parent_id": *newParentID, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		}
+	}
+
+	_, err := r.collection.UpdateMany(c, bson.M{"parent_id": parentID}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
+
+// bumpVersion builds an update that sets the given fields and increments the
+// topic's version, for writes that do not go through UpdateTopicFields.
+func bumpVersion(set bson.M) bson.M {
+
+	update := helper.VersionedUpdate(set, nil)
+	update["$inc"] = bson.M{"version": 1}
+
+	return update
+}
+
+func (r *topicRepository) SetParent(c context.Context, id primitive.ObjectID, parentID *primitive.ObjectID) error {
+
+	update := bson.M{
+		"$unset": bson.M{"parent_id": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$inc":   bson.M{"version": 1},
+	}
+	if parentID != nil {
+		update = bson.M{
+			"$set": bson.M{"parent_id": *parentID, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		}
+	}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+
+}
@@ -1,13 +1,83 @@
 package topics
 
+// CreateTopicRequest accepts an optional client-generated ID so that offline
+// clients can create topics before they first sync.
 type CreateTopicRequest struct {
-	TopicName        string `json:"name" bson:"name"`
-	TopicDescription string `json:"description" bson:"description"`
-	Color            string `json:"color" bson:"color"`
+	ID               string   `json:"id" bson:"id"`
+	TopicName        string   `json:"name" bson:"name"`
+	TopicDescription string   `json:"description" bson:"description"`
+	Color            string   `json:"color" bson:"color"`
+	Visibility       string   `json:"visibility" bson:"visibility"`
+	Language         string   `json:"language" bson:"language"`
+	TargetLanguage   string   `json:"target_language" bson:"target_language"`
+	Tags             []string `json:"tags" bson:"tags"`
+	ParentID         string   `json:"parent_id" bson:"parent_id"`
 }
 
 type UpdateTopicRequest struct {
-	TopicName        *string `json:"name" bson:"name"`
-	TopicDescription *string `json:"description" bson:"description"`
-	Color            *string `json:"color" bson:"color"`
-}
\ No newline at end of file
+	TopicName        *string   `json:"name" bson:"name"`
+	TopicDescription *string   `json:"description" bson:"description"`
+	Color            *string   `json:"color" bson:"color"`
+	Visibility       *string   `json:"visibility" bson:"visibility"`
+	Language         *string   `json:"language" bson:"language"`
+	TargetLanguage   *string   `json:"target_language" bson:"target_language"`
+	Tags             *[]string `json:"tags" bson:"tags"`
+
+	// ExpectedVersion is taken from the If-Match header; nil skips the check.
+	ExpectedVersion *int64 `json:"-" bson:"-"`
+}
+
+type SearchPublicTopicsRequest struct {
+	Query    string `form:"q" json:"q"`
+	Language string `form:"language" json:"language"`
+	Tag      string `form:"tag" json:"tag"`
+	Page     int    `form:"page" json:"page"`
+	Limit    int    `form:"limit" json:"limit"`
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" bson:"email"`
+	Role  string `json:"role" bson:"role"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" bson:"role"`
+}
+
+type ListActivityRequest struct {
+	Limit int `form:"limit" json:"limit"`
+}
+
+// ListTopicsRequest filters the user's topic list. ParentID "root" selects
+// top-level topics only.
+type ListTopicsRequest struct {
+	Tag      string `form:"tag" json:"tag"`
+	ParentID string `form:"parent_id" json:"parent_id"`
+}
+
+type MoveTopicRequest struct {
+	ParentID *string `json:"parent_id" bson:"parent_id"`
+}
+
+// ExtractWordsRequest is pasted text, such as an article or subtitles, to
+// mine for new words. Autofill is copied onto every suggestion.
+type ExtractWordsRequest struct {
+	Text      string `json:"text"`
+	Limit     int    `json:"limit"`
+	MinLength int    `json:"min_length"`
+	Autofill  bool   `json:"autofill"`
+}
+
+// ImportMediaRequest describes a subtitle (.srt, .vtt) or e-book (.epub) file
+// sent as the raw request body. Format defaults to the file name's extension
+// and the topic name to the book title or file name.
+type ImportMediaRequest struct {
+	FileName       string `form:"filename" json:"filename"`
+	Format         string `form:"format" json:"format"`
+	TopicName      string `form:"topic_name" json:"topic_name"`
+	Language       string `form:"language" json:"language"`
+	TargetLanguage string `form:"target_language" json:"target_language"`
+	Limit          int    `form:"limit" json:"limit"`
+	MinLength      int    `form:"min_length" json:"min_length"`
+	Autofill       bool   `form:"autofill" json:"autofill"`
+}
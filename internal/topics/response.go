@@ -1,20 +1,119 @@
 package topics
 
 import (
+	"flashcard/internal/words"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TopicResponse struct {
-	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	TopicName        string             `json:"name" bson:"name"`
-	TopicDescription *string            `json:"description" bson:"description"`
-	Color            string             `json:"color" bson:"color"`
-	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
-	WordCount        int                `json:"word_count" bson:"word_count"`
-	UnWordCount      int                `json:"un_word_count" bson:"un_word_count"`
-	PercentCompeted  float64            `json:"percent_competed" bson:"percent_competed"`
-	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+	ID               primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TopicName        string              `json:"name" bson:"name"`
+	TopicDescription *string             `json:"description" bson:"description"`
+	Color            string              `json:"color" bson:"color"`
+	UserID           primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	ParentID         *primitive.ObjectID `json:"parent_id" bson:"parent_id,omitempty"`
+	Visibility       string              `json:"visibility" bson:"visibility"`
+	ShareToken       string              `json:"share_token,omitempty" bson:"share_token,omitempty"`
+	Language         string              `json:"language" bson:"language"`
+	TargetLanguage   string              `json:"target_language,omitempty" bson:"target_language,omitempty"`
+	Tags             []string            `json:"tags" bson:"tags"`
+	SourceTopicID    *primitive.ObjectID `json:"source_topic_id,omitempty" bson:"source_topic_id,omitempty"`
+	Role             string              `json:"role" bson:"role"`
+	JoinedAt         *time.Time          `json:"joined_at,omitempty" bson:"joined_at,omitempty"`
+	WordCount        int                 `json:"word_count" bson:"word_count"`
+	UnWordCount      int                 `json:"un_word_count" bson:"un_word_count"`
+	PercentCompeted  float64             `json:"percent_competed" bson:"percent_competed"`
+	Version          int64               `json:"version" bson:"version"`
+	CreatedAt        time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+type PublicTopicResponse struct {
+	ID               primitive.ObjectID `json:"id"`
+	TopicName        string             `json:"name"`
+	TopicDescription *string            `json:"description"`
+	Color            string             `json:"color"`
+	Language         string             `json:"language"`
+	Tags             []string           `json:"tags"`
+	CloneCount       int                `json:"clone_count"`
+	WordCount        int                `json:"word_count"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type PublicWordResponse struct {
+	ID         primitive.ObjectID `json:"id"`
+	Word       string             `json:"word"`
+	Definition string             `json:"definition"`
+	Example    *string            `json:"example"`
+	WordType   string             `json:"word_type"`
+}
+
+type PublicTopicDetailResponse struct {
+	PublicTopicResponse
+	Words []*PublicWordResponse `json:"words"`
+}
+
+type ShareLinkResponse struct {
+	ShareToken string `json:"share_token"`
+	Visibility string `json:"visibility"`
+}
+
+type UpstreamChangesResponse struct {
+	SourceTopicID    primitive.ObjectID `json:"source_topic_id"`
+	SourceRevision   int64              `json:"source_revision"`
+	UpstreamRevision int64              `json:"upstream_revision"`
+	*words.UpstreamDiff
+}
+
+type MemberResponse struct {
+	UserID   primitive.ObjectID `json:"user_id"`
+	Email    string             `json:"email"`
+	Role     string             `json:"role"`
+	Status   string             `json:"status"`
+	JoinedAt *time.Time         `json:"joined_at,omitempty"`
+}
+
+type InvitationResponse struct {
+	TopicID   primitive.ObjectID `json:"topic_id"`
+	TopicName string             `json:"topic_name"`
+	OwnerID   primitive.ObjectID `json:"owner_id"`
+	Role      string             `json:"role"`
+	InvitedAt time.Time          `json:"invited_at"`
+}
+
+type ActivityResponse struct {
+	ID        primitive.ObjectID  `json:"id"`
+	UserID    primitive.ObjectID  `json:"user_id"`
+	UserEmail string              `json:"user_email"`
+	Action    string              `json:"action"`
+	WordID    *primitive.ObjectID `json:"word_id,omitempty"`
+	Word      string              `json:"word,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+type FolderProgressResponse struct {
+	TopicID    primitive.ObjectID `json:"topic_id"`
+	TopicCount int                `json:"topic_count"`
+	*words.TopicProgress
+}
+
+// WordSuggestion is a ready-to-send create request for a word found in the
+// text; the suggestions can be confirmed together through the batch endpoint.
+type WordSuggestion struct {
+	*words.CreateWordRequest
+	Frequency int `json:"frequency"`
+}
+
+type ExtractWordsResponse struct {
+	TokenCount  int               `json:"token_count"`
+	Suggestions []*WordSuggestion `json:"suggestions"`
+}
+
+type ImportMediaResponse struct {
+	Topic      *TopicResponse           `json:"topic"`
+	TokenCount int                      `json:"token_count"`
+	Words      *words.BatchWordResponse `json:"words"`
 }
@@ -14,8 +14,31 @@ func RegisterRoutes(r *gin.Engine, handler *TopicHandler) {
 		topicGroup.GET("", middleware.JWTAuthMiddleware(), handler.GetAllTopics)
 		topicGroup.GET("/:topic_id", middleware.JWTAuthMiddleware(), handler.GetTopicByID)
 		topicGroup.GET("/user", middleware.JWTAuthMiddleware(), handler.GetAllTopicsByUser)
+		topicGroup.POST("/import", middleware.JWTAuthMiddleware(), handler.ImportMedia)
 		topicGroup.PUT("/:topic_id", middleware.JWTAuthMiddleware(), handler.UpdateTopic)
 		topicGroup.DELETE("/:topic_id", middleware.JWTAuthMiddleware(), handler.DeleteTopic)
+		topicGroup.POST("/:topic_id/share", middleware.JWTAuthMiddleware(), handler.ShareTopic)
+		topicGroup.DELETE("/:topic_id/share", middleware.JWTAuthMiddleware(), handler.RevokeShareLink)
+		topicGroup.POST("/:topic_id/clone", middleware.JWTAuthMiddleware(), handler.CloneTopic)
+		topicGroup.GET("/:topic_id/upstream", middleware.JWTAuthMiddleware(), handler.GetUpstreamChanges)
+		topicGroup.POST("/:topic_id/upstream/merge", middleware.JWTAuthMiddleware(), handler.MergeUpstream)
+		topicGroup.GET("/invitations", middleware.JWTAuthMiddleware(), handler.GetInvitations)
+		topicGroup.GET("/:topic_id/members", middleware.JWTAuthMiddleware(), handler.GetMembers)
+		topicGroup.POST("/:topic_id/members", middleware.JWTAuthMiddleware(), handler.InviteMember)
+		topicGroup.POST("/:topic_id/members/accept", middleware.JWTAuthMiddleware(), handler.AcceptInvitation)
+		topicGroup.PUT("/:topic_id/members/:user_id", middleware.JWTAuthMiddleware(), handler.UpdateMemberRole)
+		topicGroup.DELETE("/:topic_id/members/:user_id", middleware.JWTAuthMiddleware(), handler.RemoveMember)
+		topicGroup.GET("/:topic_id/activity", middleware.JWTAuthMiddleware(), handler.GetActivity)
+		topicGroup.PUT("/:topic_id/move", middleware.JWTAuthMiddleware(), handler.MoveTopic)
+		topicGroup.GET("/:topic_id/progress", middleware.JWTAuthMiddleware(), handler.GetFolderProgress)
+		topicGroup.POST("/:topic_id/extract", middleware.JWTAuthMiddleware(), handler.ExtractWords)
+	}
+
+	publicGroup := r.Group("/api/v1/public/topics")
+	{
+		publicGroup.GET("", handler.SearchPublicTopics)
+		publicGroup.GET("/:topic_id", handler.GetPublicTopic)
+		publicGroup.GET("/shared/:share_token", handler.GetSharedTopic)
 	}
 
 }
\ No newline at end of file
@@ -2,31 +2,81 @@ package topics
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flashcard/helper"
+	"flashcard/internal/user"
 	"flashcard/internal/words"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TopicService interface {
 	CreateTopic(c context.Context, req *CreateTopicRequest, userID string) error
-	GetAllTopics(c context.Context) ([]*Topic, error)
-	GetTopicByID(c context.Context, id string) (*TopicResponse, error)
-	GetTopicsByUserID(c context.Context, userID string) ([]*TopicResponse, error)
-	UpdateTopic(c context.Context, id string, req *UpdateTopicRequest) error
-	DeleteTopic(c context.Context, id string) error
+	GetTopicByID(c context.Context, id string, userID string) (*TopicResponse, error)
+	GetTopicsByUserID(c context.Context, userID string, req *ListTopicsRequest) ([]*TopicResponse, error)
+	UpdateTopic(c context.Context, id string, userID string, req *UpdateTopicRequest) (*TopicResponse, error)
+	DeleteTopic(c context.Context, id string, userID string) error
+	DeleteUserData(c context.Context, userID string) error
+	ShareTopic(c context.Context, id string, userID string) (*ShareLinkResponse, error)
+	RevokeShareLink(c context.Context, id string, userID string) error
+	SearchPublicTopics(c context.Context, req *SearchPublicTopicsRequest) ([]*PublicTopicResponse, error)
+	GetPublicTopic(c context.Context, id string) (*PublicTopicDetailResponse, error)
+	GetSharedTopic(c context.Context, shareToken string) (*PublicTopicDetailResponse, error)
+	CloneTopic(c context.Context, id string, userID string, shareToken string) (*Topic, error)
+	CloneTopicForUser(c context.Context, id string, ownerID string, targetUserID string) (*Topic, error)
+	GetUpstreamChanges(c context.Context, id string, userID string) (*UpstreamChangesResponse, error)
+	MergeUpstream(c context.Context, id string, userID string, req *words.MergeUpstreamRequest) (*UpstreamChangesResponse, error)
+	GetMembers(c context.Context, id string, userID string) ([]*MemberResponse, error)
+	InviteMember(c context.Context, id string, userID string, req *InviteMemberRequest) (*MemberResponse, error)
+	UpdateMemberRole(c context.Context, id string, userID string, memberID string, req *UpdateMemberRequest) error
+	RemoveMember(c context.Context, id string, userID string, memberID string) error
+	AcceptInvitation(c context.Context, id string, userID string) error
+	GetInvitations(c context.Context, userID string) ([]*InvitationResponse, error)
+	GetActivity(c context.Context, id string, userID string, req *ListActivityRequest) ([]*ActivityResponse, error)
+	MoveTopic(c context.Context, id string, userID string, req *MoveTopicRequest) error
+	GetFolderProgress(c context.Context, id string, userID string) (*FolderProgressResponse, error)
+	GetAccessibleTopics(c context.Context, userID string) ([]*TopicResponse, error)
+	ExtractWords(c context.Context, id string, userID string, req *ExtractWordsRequest) (*ExtractWordsResponse, error)
+	ImportMedia(c context.Context, userID string, req *ImportMediaRequest, body io.Reader) (*ImportMediaResponse, error)
+}
+
+// maxFolderDepth bounds how deeply topics can be nested, which also bounds
+// the ancestor walk used to reject cycles.
+const maxFolderDepth = 32
+
+// MemberDirectory resolves invitees and activity authors to user accounts.
+type MemberDirectory interface {
+	FindByEmail(ctx context.Context, email string) (*user.User, error)
+	FindByID(ctx context.Context, userID primitive.ObjectID) (*user.User, error)
+}
+
+// DeletionLog records topics that disappear for some users, so that offline
+// clients can drop their local copies on the next sync.
+type DeletionLog interface {
+	RecordTopicDeletion(c context.Context, topicID primitive.ObjectID, userIDs []primitive.ObjectID) error
 }
 
 type topicService struct {
-	topicRepository TopicRepository
-	wordService words.WordService
+	topicRepository    TopicRepository
+	activityRepository TopicActivityRepository
+	wordService        words.WordService
+	memberDirectory    MemberDirectory
+	deletionLog        DeletionLog
 }
 
-func NewTopicService(topicRepository TopicRepository, wordService words.WordService) TopicService {
+func NewTopicService(topicRepository TopicRepository, activityRepository TopicActivityRepository, wordService words.WordService, memberDirectory MemberDirectory, deletionLog DeletionLog) TopicService {
 	return &topicService{
-		topicRepository: topicRepository,
-		wordService:     wordService,
+		topicRepository:    topicRepository,
+		activityRepository: activityRepository,
+		wordService:        wordService,
+		memberDirectory:    memberDirectory,
+		deletionLog:        deletionLog,
 	}
 }
 
@@ -44,41 +94,61 @@ func (s *topicService) CreateTopic(c context.Context, req *CreateTopicRequest, u
 		return fmt.Errorf("user id is required")
 	}
 
+	if req.Visibility == "" {
+		req.Visibility = VisibilityPrivate
+	}
+
+	if !isValidVisibility(req.Visibility) {
+		return fmt.Errorf("invalid visibility %q", req.Visibility)
+	}
+
 	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return err
 	}
 
+	topicID := primitive.NewObjectID()
+	if req.ID != "" {
+		if topicID, err = primitive.ObjectIDFromHex(req.ID); err != nil {
+			return err
+		}
+	}
+
 	topic := &Topic{
-		ID :              primitive.NewObjectID(),
+		ID :              topicID,
 		TopicName:        req.TopicName,
 		TopicDescription: &req.TopicDescription,
 		Color:            req.Color,
 		UserID:           objectID,
+		Visibility:       req.Visibility,
+		Language:         strings.ToLower(strings.TrimSpace(req.Language)),
+		TargetLanguage:   strings.ToLower(strings.TrimSpace(req.TargetLanguage)),
+		Tags:             helper.NormalizeTags(req.Tags),
+		Version:          1,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
 
-	return s.topicRepository.CreateTopic(c, topic)
+	if req.ParentID != "" {
+		parentID, err := s.validateParent(c, topic, req.ParentID)
+		if err != nil {
+			return err
+		}
+		topic.ParentID = parentID
+	}
 
-}
+	return s.topicRepository.CreateTopic(c, topic)
 
-func (s *topicService) GetAllTopics(c context.Context) ([]*Topic, error) {
-	return s.topicRepository.GetAllTopics(c)
 }
 
-func (s *topicService) GetTopicByID(c context.Context, id string) (*TopicResponse, error) {
+func (s *topicService) GetTopicByID(c context.Context, id string, userID string) (*TopicResponse, error) {
 	
-	if id == "" {
-		return nil, fmt.Errorf("topic id is required")
-	}
-
-	objectID, err := primitive.ObjectIDFromHex(id)
+	topic, err := s.getTopicForRole(c, id, userID, RoleViewer)
 	if err != nil {
 		return nil, err
 	}
 
-	topic, err := s.topicRepository.GetTopicByID(c, objectID)
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -92,21 +162,13 @@ func (s *topicService) GetTopicByID(c context.Context, id string) (*TopicRespons
 		return nil, err
 	}
 
-	res := &TopicResponse{
-		ID:               topic.ID,
-		TopicName:        topic.TopicName,
-		TopicDescription: topic.TopicDescription,
-		Color:            topic.Color,
-		UserID:           topic.UserID,
-		CreatedAt:        topic.CreatedAt,
-		UpdatedAt:        topic.UpdatedAt,
-		WordCount:        len(works),
-	}
+	res := toTopicResponse(topic, objectUserID)
+	res.WordCount = len(works)
 
 	return res, nil
 }
 
-func (s *topicService) GetTopicsByUserID(c context.Context, userID string) ([]*TopicResponse, error) {
+func (s *topicService) GetTopicsByUserID(c context.Context, userID string, req *ListTopicsRequest) ([]*TopicResponse, error) {
 	
 	if userID == "" {
 		return nil, fmt.Errorf("user id is required")
@@ -121,40 +183,28 @@ func (s *topicService) GetTopicsByUserID(c context.Context, userID string) ([]*T
 	if err != nil {
 		return nil, err
 	}
-	isTrue := true
+
+	sharedTopics, err := s.topicRepository.GetTopicsByMember(c, objectID, MemberStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	topics = append(topics, sharedTopics...)
+
+	topics, err = filterTopics(topics, req)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []*TopicResponse
 	for _, topic := range topics {
-		req := &words.SearchWordRequest{
-			Word: nil,
-			Istrue: &isTrue,
-		}
-		completedWords, err := s.wordService.GetWordsByTopicID(c, topic.ID.Hex(), req)
-		if err != nil {
-			return nil, err
-		}
-		allWords, err := s.wordService.GetWordsByTopicID(c, topic.ID.Hex(), &words.SearchWordRequest{
-			Word: nil,
-			Istrue: nil,
-		})
+		progress, err := s.wordService.GetTopicProgress(c, topic.ID.Hex(), userID)
 		if err != nil {
 			return nil, err
 		}
-		percentCompleted := 0.0
-		if len(allWords) > 0 {
-			percentCompleted = float64(len(completedWords)) / float64(len(allWords)) * 100
-		}
-		res := &TopicResponse{
-			ID:               topic.ID,
-			TopicName:        topic.TopicName,
-			TopicDescription: topic.TopicDescription,
-			Color:            topic.Color,
-			UserID:           topic.UserID,
-			CreatedAt:        topic.CreatedAt,
-			UpdatedAt:        topic.UpdatedAt,
-			WordCount:        len(allWords),
-			UnWordCount:      len(allWords) - len(completedWords),
-			PercentCompeted:  percentCompleted,
-		}
+		res := toTopicResponse(topic, objectID)
+		res.WordCount = progress.WordCount
+		res.UnWordCount = progress.WordCount - progress.CompletedCount
+		res.PercentCompeted = progress.CompletionPercent
 
 		result = append(result, res)
 	}
@@ -162,20 +212,52 @@ func (s *topicService) GetTopicsByUserID(c context.Context, userID string) ([]*T
 	return result, nil
 }
 
-func (s *topicService) UpdateTopic(c context.Context, id string, req *UpdateTopicRequest) error {
-	
-	if id == "" {
-		return fmt.Errorf("topic id is required")
+// GetAccessibleTopics lists the topics a user owns or has joined, without
+// the word counts GetTopicsByUserID adds.
+func (s *topicService) GetAccessibleTopics(c context.Context, userID string) ([]*TopicResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	topics, err := s.topicRepository.GetTopicsByUserID(c, objectID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	sharedTopics, err := s.topicRepository.GetTopicsByMember(c, objectID, MemberStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	topics = append(topics, sharedTopics...)
+
+	result := make([]*TopicResponse, 0, len(topics))
+	for _, topic := range topics {
+		result = append(result, toTopicResponse(topic, objectID))
+	}
+
+	return result, nil
+}
+
+func (s *topicService) UpdateTopic(c context.Context, id string, userID string, req *UpdateTopicRequest) (*TopicResponse, error) {
+	
+	topic, err := s.getTopicForRole(c, id, userID, RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Visibility != nil && topic.UserID.Hex() != userID {
+		return nil, fmt.Errorf("%w: only the owner can change visibility", helper.ErrPermissionDenied)
+	}
+
+	if req.ExpectedVersion != nil && *req.ExpectedVersion != topic.Version {
+		return nil, fmt.Errorf("%w: topic is at version %d", helper.ErrPreconditionFailed, topic.Version)
 	}
 
-	topic, er := s.topicRepository.GetTopicByID(c, objectID)
-	if er != nil {
-		return er
+	snapshot, err := bson.Marshal(topic)
+	if err != nil {
+		return nil, err
 	}
 
 	if req.Color != nil {
@@ -190,36 +272,974 @@ func (s *topicService) UpdateTopic(c context.Context, id string, req *UpdateTopi
 		topic.TopicDescription = req.TopicDescription
 	}
 
-	return s.topicRepository.UpdateTopic(c, objectID, topic)
+	if req.TopicName != nil || req.TopicDescription != nil {
+		topic.Revision++
+	}
+
+	if req.Visibility != nil {
+		if !isValidVisibility(*req.Visibility) {
+			return nil, fmt.Errorf("invalid visibility %q", *req.Visibility)
+		}
+		topic.Visibility = *req.Visibility
+	}
+
+	if req.Language != nil {
+		topic.Language = strings.ToLower(strings.TrimSpace(*req.Language))
+	}
+
+	if req.TargetLanguage != nil {
+		topic.TargetLanguage = strings.ToLower(strings.TrimSpace(*req.TargetLanguage))
+	}
+
+	if req.Tags != nil {
+		topic.Tags = helper.NormalizeTags(*req.Tags)
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	set, unset, err := helper.ChangedFields(snapshot, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.writeTopicFields(c, topic, set, unset); err != nil {
+		return nil, err
+	}
+
+	return toTopicResponse(topic, objectUserID), nil
+
+}
+
+// saveTopicChanges persists what changed on topic since snapshot was taken,
+// on the condition that the stored topic is still at that version.
+func (s *topicService) saveTopicChanges(c context.Context, topic *Topic, snapshot bson.Raw) error {
+
+	set, unset, err := helper.ChangedFields(snapshot, topic)
+	if err != nil {
+		return err
+	}
+
+	return s.writeTopicFields(c, topic, set, unset)
+}
+
+// writeTopicFields bumps the version of topic and applies set and unset to
+// the stored topic if it is still at the previous version.
+func (s *topicService) writeTopicFields(c context.Context, topic *Topic, set bson.M, unset bson.M) error {
+
+	if len(set) == 0 && len(unset) == 0 {
+		return nil
+	}
+
+	expectedVersion := topic.Version
+	topic.Version++
+	topic.UpdatedAt = time.Now()
+	set["version"] = topic.Version
+	set["updated_at"] = topic.UpdatedAt
+
+	updated, err := s.topicRepository.UpdateTopicFields(c, topic.ID, expectedVersion, set, unset)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return fmt.Errorf("%w: topic was modified concurrently", helper.ErrPreconditionFailed)
+	}
 
+	return nil
 }
 
-func (s *topicService) DeleteTopic(c context.Context, id string) error {
+func (s *topicService) DeleteTopic(c context.Context, id string, userID string) error {
 	
-	if id == "" {
-		return fmt.Errorf("topic id is required")
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return err
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	return s.deleteTopic(c, topic)
+
+}
+
+func (s *topicService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return err
 	}
 
-	words, err := s.wordService.GetWordsByTopicID(c, id, &words.SearchWordRequest{
-		Word: nil,
-		Istrue: nil,
-	})
+	if err := s.topicRepository.RemoveMemberFromAllTopics(c, objectID); err != nil {
+		return err
+	}
+
+	if err := s.activityRepository.DeleteActivitiesByUserID(c, objectID); err != nil {
+		return err
+	}
+
+	topics, err := s.topicRepository.GetTopicsByUserID(c, objectID)
 	if err != nil {
 		return err
 	}
 
-	for _, word := range words {
-		err := s.wordService.DeleteWord(c, word.ID.Hex())
-		if err != nil {
+	for _, topic := range topics {
+		if err := s.deleteTopic(c, topic); err != nil {
 			return err
 		}
 	}
 
-	return s.topicRepository.DeleteTopic(c, objectID)
+	return nil
+
+}
+
+func (s *topicService) ShareTopic(c context.Context, id string, userID string) (*ShareLinkResponse, error) {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := bson.Marshal(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if topic.ShareToken == "" {
+		token, err := generateShareToken()
+		if err != nil {
+			return nil, err
+		}
+		topic.ShareToken = token
+	}
+
+	if topic.Visibility == "" || topic.Visibility == VisibilityPrivate {
+		topic.Visibility = VisibilityUnlisted
+	}
+
+	if err := s.saveTopicChanges(c, topic, snapshot); err != nil {
+		return nil, err
+	}
+
+	return &ShareLinkResponse{
+		ShareToken: topic.ShareToken,
+		Visibility: topic.Visibility,
+	}, nil
+}
+
+func (s *topicService) RevokeShareLink(c context.Context, id string, userID string) error {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := bson.Marshal(topic)
+	if err != nil {
+		return err
+	}
+
+	topic.ShareToken = ""
+	if topic.Visibility == VisibilityUnlisted {
+		topic.Visibility = VisibilityPrivate
+	}
+
+	return s.saveTopicChanges(c, topic, snapshot)
+}
+
+func (s *topicService) SearchPublicTopics(c context.Context, req *SearchPublicTopicsRequest) ([]*PublicTopicResponse, error) {
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	topics, err := s.topicRepository.SearchPublicTopics(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	topicIDs := make([]primitive.ObjectID, 0, len(topics))
+	for _, topic := range topics {
+		topicIDs = append(topicIDs, topic.ID)
+	}
+
+	wordCounts, err := s.wordService.CountTopicWords(c, topicIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*PublicTopicResponse, 0, len(topics))
+	for _, topic := range topics {
+		result = append(result, toPublicTopicResponse(topic, int(wordCounts[topic.ID])))
+	}
+
+	return result, nil
+}
+
+func (s *topicService) GetPublicTopic(c context.Context, id string) (*PublicTopicDetailResponse, error) {
+
+	if id == "" {
+		return nil, fmt.Errorf("topic id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := s.topicRepository.GetTopicByID(c, objectID)
+	if err != nil || topic.Visibility != VisibilityPublic {
+		return nil, fmt.Errorf("topic not found")
+	}
+
+	return s.publicTopicDetail(c, topic)
+}
+
+func (s *topicService) GetSharedTopic(c context.Context, shareToken string) (*PublicTopicDetailResponse, error) {
+
+	if shareToken == "" {
+		return nil, fmt.Errorf("share token is required")
+	}
+
+	topic, err := s.topicRepository.GetTopicByShareToken(c, shareToken)
+	if err != nil || !isShared(topic) {
+		return nil, fmt.Errorf("topic not found")
+	}
+
+	return s.publicTopicDetail(c, topic)
+}
+
+func (s *topicService) CloneTopic(c context.Context, id string, userID string, shareToken string) (*Topic, error) {
+
+	if id == "" {
+		return nil, fmt.Errorf("topic id is required")
+	}
+
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := findTopic(c, s.topicRepository, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	canClone := memberRole(source, objectUserID) != "" ||
+		source.Visibility == VisibilityPublic ||
+		(source.Visibility == VisibilityUnlisted && shareToken != "" && shareToken == source.ShareToken)
+	if !canClone {
+		return nil, fmt.Errorf("%w: topic not found", helper.ErrResourceNotFound)
+	}
+
+	return s.cloneTopic(c, source, objectUserID)
+}
+
+// CloneTopicForUser copies a topic the owner controls into another user's
+// account without requiring the topic to be shared, e.g. when a teacher
+// assigns it to a class.
+func (s *topicService) CloneTopicForUser(c context.Context, id string, ownerID string, targetUserID string) (*Topic, error) {
 
-}
\ No newline at end of file
+	source, err := s.getTopicForRole(c, id, ownerID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	objectTargetID, err := primitive.ObjectIDFromHex(targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cloneTopic(c, source, objectTargetID)
+}
+
+func (s *topicService) cloneTopic(c context.Context, source *Topic, objectUserID primitive.ObjectID) (*Topic, error) {
+
+	now := time.Now()
+	sourceTopicID := source.ID
+	clone := &Topic{
+		ID:               primitive.NewObjectID(),
+		TopicName:        source.TopicName,
+		TopicDescription: source.TopicDescription,
+		Color:            source.Color,
+		UserID:           objectUserID,
+		Visibility:       VisibilityPrivate,
+		Language:         source.Language,
+		TargetLanguage:   source.TargetLanguage,
+		Tags:             source.Tags,
+		SourceTopicID:    &sourceTopicID,
+		SourceRevision:   source.Revision,
+		Version:          1,
+		ClonedAt:         &now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := s.topicRepository.CreateTopic(c, clone); err != nil {
+		return nil, err
+	}
+
+	if err := s.wordService.CloneWords(c, source.ID.Hex(), clone.ID.Hex(), objectUserID.Hex()); err != nil {
+		return nil, err
+	}
+
+	if source.UserID != objectUserID {
+		if err := s.topicRepository.IncrementCloneCount(c, source.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return clone, nil
+}
+
+func (s *topicService) GetUpstreamChanges(c context.Context, id string, userID string) (*UpstreamChangesResponse, error) {
+
+	topic, upstream, err := s.getUpstreamPair(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := s.wordService.DiffUpstream(c, topic.ID.Hex(), upstream.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpstreamChangesResponse{
+		SourceTopicID:    upstream.ID,
+		SourceRevision:   topic.SourceRevision,
+		UpstreamRevision: upstream.Revision,
+		UpstreamDiff:     diff,
+	}, nil
+}
+
+func (s *topicService) MergeUpstream(c context.Context, id string, userID string, req *words.MergeUpstreamRequest) (*UpstreamChangesResponse, error) {
+
+	topic, upstream, err := s.getUpstreamPair(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := s.wordService.MergeUpstream(c, topic.ID.Hex(), upstream.ID.Hex(), userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err = s.topicRepository.GetTopicByID(c, topic.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := bson.Marshal(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	topic.SourceRevision = upstream.Revision
+	if err := s.saveTopicChanges(c, topic, snapshot); err != nil {
+		return nil, err
+	}
+
+	return &UpstreamChangesResponse{
+		SourceTopicID:    upstream.ID,
+		SourceRevision:   topic.SourceRevision,
+		UpstreamRevision: upstream.Revision,
+		UpstreamDiff:     applied,
+	}, nil
+}
+
+func (s *topicService) GetMembers(c context.Context, id string, userID string) ([]*MemberResponse, error) {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.memberDirectory.FindByID(c, topic.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerEmail := ""
+	if owner != nil {
+		ownerEmail = owner.Email
+	}
+
+	members := []*MemberResponse{{
+		UserID: topic.UserID,
+		Email:  ownerEmail,
+		Role:   RoleOwner,
+		Status: MemberStatusAccepted,
+	}}
+	for i := range topic.Members {
+		members = append(members, toMemberResponse(&topic.Members[i]))
+	}
+
+	return members, nil
+}
+
+func (s *topicService) InviteMember(c context.Context, id string, userID string, req *InviteMemberRequest) (*MemberResponse, error) {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	if req.Role == "" {
+		req.Role = RoleViewer
+	}
+
+	if !isAssignableRole(req.Role) {
+		return nil, fmt.Errorf("invalid role %q", req.Role)
+	}
+
+	invitee, err := s.memberDirectory.FindByEmail(c, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitee == nil {
+		return nil, fmt.Errorf("%w: no account found for %s", helper.ErrResourceNotFound, email)
+	}
+
+	if invitee.ID == topic.UserID {
+		return nil, fmt.Errorf("the owner cannot be invited to their own topic")
+	}
+
+	member := &TopicMember{
+		UserID:    invitee.ID,
+		Email:     invitee.Email,
+		Role:      req.Role,
+		Status:    MemberStatusPending,
+		InvitedBy: topic.UserID,
+		InvitedAt: time.Now(),
+	}
+
+	if err := s.topicRepository.AddMember(c, topic.ID, member); err != nil {
+		return nil, err
+	}
+
+	return toMemberResponse(member), nil
+}
+
+func (s *topicService) UpdateMemberRole(c context.Context, id string, userID string, memberID string, req *UpdateMemberRequest) error {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if !isAssignableRole(req.Role) {
+		return fmt.Errorf("invalid role %q", req.Role)
+	}
+
+	objectMemberID, err := primitive.ObjectIDFromHex(memberID)
+	if err != nil {
+		return err
+	}
+
+	if findMember(topic, objectMemberID) == nil {
+		return fmt.Errorf("%w: member not found", helper.ErrResourceNotFound)
+	}
+
+	return s.topicRepository.UpdateMember(c, topic.ID, objectMemberID, bson.M{"role": req.Role})
+}
+
+// RemoveMember lets the owner remove anyone, and lets a member leave the
+// topic or decline a pending invitation by removing themselves.
+func (s *topicService) RemoveMember(c context.Context, id string, userID string, memberID string) error {
+
+	if id == "" {
+		return fmt.Errorf("topic id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	objectMemberID, err := primitive.ObjectIDFromHex(memberID)
+	if err != nil {
+		return err
+	}
+
+	topic, err := findTopic(c, s.topicRepository, objectID)
+	if err != nil {
+		return err
+	}
+
+	if findMember(topic, objectMemberID) == nil {
+		return fmt.Errorf("%w: member not found", helper.ErrResourceNotFound)
+	}
+
+	if topic.UserID.Hex() != userID && memberID != userID {
+		return fmt.Errorf("%w: only the owner can remove other members", helper.ErrPermissionDenied)
+	}
+
+	if err := s.topicRepository.RemoveMember(c, topic.ID, objectMemberID); err != nil {
+		return err
+	}
+
+	return s.deletionLog.RecordTopicDeletion(c, topic.ID, []primitive.ObjectID{objectMemberID})
+}
+
+func (s *topicService) AcceptInvitation(c context.Context, id string, userID string) error {
+
+	if id == "" {
+		return fmt.Errorf("topic id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	topic, err := findTopic(c, s.topicRepository, objectID)
+	if err != nil {
+		return err
+	}
+
+	member := findMember(topic, objectUserID)
+	if member == nil {
+		return fmt.Errorf("%w: invitation not found", helper.ErrResourceNotFound)
+	}
+
+	if member.Status == MemberStatusAccepted {
+		return nil
+	}
+
+	return s.topicRepository.UpdateMember(c, topic.ID, objectUserID, bson.M{
+		"status":    MemberStatusAccepted,
+		"joined_at": time.Now(),
+	})
+}
+
+func (s *topicService) GetInvitations(c context.Context, userID string) ([]*InvitationResponse, error) {
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := s.topicRepository.GetTopicsByMember(c, objectUserID, MemberStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]*InvitationResponse, 0, len(topics))
+	for _, topic := range topics {
+		member := findMember(topic, objectUserID)
+		if member == nil {
+			continue
+		}
+		invitations = append(invitations, &InvitationResponse{
+			TopicID:   topic.ID,
+			TopicName: topic.TopicName,
+			OwnerID:   topic.UserID,
+			Role:      member.Role,
+			InvitedAt: member.InvitedAt,
+		})
+	}
+
+	return invitations, nil
+}
+
+func (s *topicService) GetActivity(c context.Context, id string, userID string, req *ListActivityRequest) ([]*ActivityResponse, error) {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Limit < 1 || req.Limit > 200 {
+		req.Limit = 50
+	}
+
+	activities, err := s.activityRepository.GetActivitiesByTopicID(c, topic.ID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make(map[primitive.ObjectID]string, len(topic.Members)+1)
+	for _, member := range topic.Members {
+		emails[member.UserID] = member.Email
+	}
+
+	result := make([]*ActivityResponse, 0, len(activities))
+	for _, activity := range activities {
+		email, ok := emails[activity.UserID]
+		if !ok {
+			author, err := s.memberDirectory.FindByID(c, activity.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if author != nil {
+				email = author.Email
+			}
+			emails[activity.UserID] = email
+		}
+
+		result = append(result, &ActivityResponse{
+			ID:        activity.ID,
+			UserID:    activity.UserID,
+			UserEmail: email,
+			Action:    activity.Action,
+			WordID:    activity.WordID,
+			Word:      activity.Word,
+			CreatedAt: activity.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *topicService) MoveTopic(c context.Context, id string, userID string, req *MoveTopicRequest) error {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	var parentID *primitive.ObjectID
+	if req.ParentID != nil && *req.ParentID != "" {
+		parentID, err = s.validateParent(c, topic, *req.ParentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.topicRepository.SetParent(c, topic.ID, parentID)
+}
+
+// GetFolderProgress sums word progress over a topic and every descendant
+// topic the user can view.
+func (s *topicService) GetFolderProgress(c context.Context, id string, userID string) (*FolderProgressResponse, error) {
+
+	root, err := s.getTopicForRole(c, id, userID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerTopics, err := s.topicRepository.GetTopicsByUserID(c, root.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[primitive.ObjectID][]*Topic)
+	for _, topic := range ownerTopics {
+		if topic.ParentID != nil {
+			children[*topic.ParentID] = append(children[*topic.ParentID], topic)
+		}
+	}
+
+	result := &FolderProgressResponse{
+		TopicID:       root.ID,
+		TopicProgress: &words.TopicProgress{},
+	}
+
+	queue := []*Topic{root}
+	for len(queue) > 0 {
+		topic := queue[0]
+		queue = queue[1:]
+
+		if authorizeTopic(topic, objectUserID, RoleViewer) == nil {
+			progress, err := s.wordService.GetTopicProgress(c, topic.ID.Hex(), userID)
+			if err != nil {
+				return nil, err
+			}
+			result.TopicProgress.Add(progress)
+			result.TopicCount++
+		}
+
+		queue = append(queue, children[topic.ID]...)
+	}
+
+	result.TopicProgress.UpdatePercentages()
+
+	return result, nil
+}
+
+// validateParent checks that parentID is a topic of the same owner and that
+// placing topic under it would not create a cycle.
+func (s *topicService) validateParent(c context.Context, topic *Topic, parentID string) (*primitive.ObjectID, error) {
+
+	objectParentID, err := primitive.ObjectIDFromHex(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestorID := &objectParentID
+	for depth := 0; ancestorID != nil; depth++ {
+		if *ancestorID == topic.ID {
+			return nil, fmt.Errorf("a topic cannot be moved into itself or one of its subfolders")
+		}
+
+		if depth >= maxFolderDepth {
+			return nil, fmt.Errorf("folders cannot be nested more than %d levels deep", maxFolderDepth)
+		}
+
+		ancestor, err := findTopic(c, s.topicRepository, *ancestorID)
+		if err != nil {
+			return nil, err
+		}
+
+		if ancestor.UserID != topic.UserID {
+			return nil, fmt.Errorf("%w: parent topic not found", helper.ErrResourceNotFound)
+		}
+
+		ancestorID = ancestor.ParentID
+	}
+
+	return &objectParentID, nil
+}
+
+func (s *topicService) getUpstreamPair(c context.Context, id string, userID string) (*Topic, *Topic, error) {
+
+	topic, err := s.getTopicForRole(c, id, userID, RoleOwner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if topic.SourceTopicID == nil {
+		return nil, nil, fmt.Errorf("topic was not cloned from another topic")
+	}
+
+	upstream, err := s.topicRepository.GetTopicByID(c, *topic.SourceTopicID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("source topic is no longer available")
+	}
+
+	if upstream.UserID != topic.UserID && !isShared(upstream) {
+		return nil, nil, fmt.Errorf("source topic is no longer available")
+	}
+
+	return topic, upstream, nil
+}
+
+func (s *topicService) getTopicForRole(c context.Context, id string, userID string, required string) (*Topic, error) {
+
+	if id == "" {
+		return nil, fmt.Errorf("topic id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := findTopic(c, s.topicRepository, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTopic(topic, objectUserID, required); err != nil {
+		return nil, err
+	}
+
+	return topic, nil
+}
+
+func (s *topicService) deleteTopic(c context.Context, topic *Topic) error {
+
+	if err := s.topicRepository.ReparentTopics(c, topic.ID, topic.ParentID); err != nil {
+		return err
+	}
+
+	if err := s.wordService.DeleteTopicWords(c, topic.ID.Hex()); err != nil {
+		return err
+	}
+
+	if err := s.activityRepository.DeleteActivitiesByTopicID(c, topic.ID); err != nil {
+		return err
+	}
+
+	if err := s.topicRepository.DeleteTopic(c, topic.ID); err != nil {
+		return err
+	}
+
+	audience := []primitive.ObjectID{topic.UserID}
+	for _, member := range topic.Members {
+		if member.Status == MemberStatusAccepted {
+			audience = append(audience, member.UserID)
+		}
+	}
+
+	return s.deletionLog.RecordTopicDeletion(c, topic.ID, audience)
+}
+
+func (s *topicService) publicTopicDetail(c context.Context, topic *Topic) (*PublicTopicDetailResponse, error) {
+
+	topicWords, err := s.wordService.GetWordsByTopicID(c, topic.ID.Hex(), &words.SearchWordRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	publicWords := make([]*PublicWordResponse, 0, len(topicWords))
+	for _, word := range topicWords {
+		publicWords = append(publicWords, &PublicWordResponse{
+			ID:         word.ID,
+			Word:       word.Word,
+			Definition: word.Definition,
+			Example:    word.Example,
+			WordType:   word.WordType,
+		})
+	}
+
+	return &PublicTopicDetailResponse{
+		PublicTopicResponse: *toPublicTopicResponse(topic, len(topicWords)),
+		Words:               publicWords,
+	}, nil
+}
+
+// toTopicResponse shows a topic to userID. Only the owner sees the share
+// token, since it grants access to unlisted topics.
+func toTopicResponse(topic *Topic, userID primitive.ObjectID) *TopicResponse {
+
+	shareToken := ""
+	if topic.UserID == userID {
+		shareToken = topic.ShareToken
+	}
+
+	var joinedAt *time.Time
+	if member := findMember(topic, userID); member != nil {
+		joinedAt = member.JoinedAt
+	}
+
+	return &TopicResponse{
+		ID:               topic.ID,
+		TopicName:        topic.TopicName,
+		TopicDescription: topic.TopicDescription,
+		Color:            topic.Color,
+		UserID:           topic.UserID,
+		ParentID:         topic.ParentID,
+		Visibility:       topic.Visibility,
+		ShareToken:       shareToken,
+		Language:         topic.Language,
+		TargetLanguage:   topic.TargetLanguage,
+		Tags:             topic.Tags,
+		SourceTopicID:    topic.SourceTopicID,
+		Role:             memberRole(topic, userID),
+		JoinedAt:         joinedAt,
+		Version:          topic.Version,
+		CreatedAt:        topic.CreatedAt,
+		UpdatedAt:        topic.UpdatedAt,
+	}
+}
+
+func toMemberResponse(member *TopicMember) *MemberResponse {
+	return &MemberResponse{
+		UserID:   member.UserID,
+		Email:    member.Email,
+		Role:     member.Role,
+		Status:   member.Status,
+		JoinedAt: member.JoinedAt,
+	}
+}
+
+func toPublicTopicResponse(topic *Topic, wordCount int) *PublicTopicResponse {
+	return &PublicTopicResponse{
+		ID:               topic.ID,
+		TopicName:        topic.TopicName,
+		TopicDescription: topic.TopicDescription,
+		Color:            topic.Color,
+		Language:         topic.Language,
+		Tags:             topic.Tags,
+		CloneCount:       topic.CloneCount,
+		WordCount:        wordCount,
+		CreatedAt:        topic.CreatedAt,
+		UpdatedAt:        topic.UpdatedAt,
+	}
+}
+
+func filterTopics(topics []*Topic, req *ListTopicsRequest) ([]*Topic, error) {
+
+	if req == nil || (req.Tag == "" && req.ParentID == "") {
+		return topics, nil
+	}
+
+	var parentID *primitive.ObjectID
+	if req.ParentID != "" && req.ParentID != "root" {
+		objectID, err := primitive.ObjectIDFromHex(req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		parentID = &objectID
+	}
+
+	tag := strings.ToLower(strings.TrimSpace(req.Tag))
+	filtered := make([]*Topic, 0, len(topics))
+	for _, topic := range topics {
+		if tag != "" && !containsTag(topic.Tags, tag) {
+			continue
+		}
+		if req.ParentID == "root" && topic.ParentID != nil {
+			continue
+		}
+		if parentID != nil && (topic.ParentID == nil || *topic.ParentID != *parentID) {
+			continue
+		}
+		filtered = append(filtered, topic)
+	}
+
+	return filtered, nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidVisibility(visibility string) bool {
+	return visibility == VisibilityPrivate || visibility == VisibilityUnlisted || visibility == VisibilityPublic
+}
+
+func isShared(topic *Topic) bool {
+	return topic.Visibility == VisibilityUnlisted || topic.Visibility == VisibilityPublic
+}
+
+func generateShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
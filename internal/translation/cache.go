@@ -0,0 +1,61 @@
+package translation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type cachingTranslator struct {
+	translator      Translator
+	cacheRepository CacheRepository
+}
+
+// NewCachingTranslator serves repeated translations from Mongo. Entries are
+// keyed per provider, so switching providers does not return stale results.
+func NewCachingTranslator(translator Translator, cacheRepository CacheRepository) Translator {
+	return &cachingTranslator{translator: translator, cacheRepository: cacheRepository}
+}
+
+func (t *cachingTranslator) Translate(c context.Context, text string, sourceLanguage string, targetLanguage string) (string, error) {
+
+	id := cacheKey(t.translator.Name(), text, sourceLanguage, targetLanguage)
+
+	cached, err := t.cacheRepository.GetTranslation(c, id)
+	if err != nil {
+		return "", err
+	}
+	if cached != nil {
+		return cached.Translation, nil
+	}
+
+	translated, err := t.translator.Translate(c, text, sourceLanguage, targetLanguage)
+	if err != nil {
+		return "", err
+	}
+
+	err = t.cacheRepository.SaveTranslation(c, &CachedTranslation{
+		ID:             id,
+		Text:           text,
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: targetLanguage,
+		Translation:    translated,
+		Provider:       t.translator.Name(),
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return translated, nil
+}
+
+func (t *cachingTranslator) Name() string {
+	return t.translator.Name()
+}
+
+func cacheKey(provider, text, sourceLanguage, targetLanguage string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + sourceLanguage + "\x00" + targetLanguage + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,123 @@
+package translation
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type memoryCacheRepository struct {
+	entries map[string]*CachedTranslation
+	saves   int
+}
+
+func (r *memoryCacheRepository) GetTranslation(c context.Context, id string) (*CachedTranslation, error) {
+	return r.entries[id], nil
+}
+
+func (r *memoryCacheRepository) SaveTranslation(c context.Context, translation *CachedTranslation) error {
+	r.saves++
+	r.entries[translation.ID] = translation
+	return nil
+}
+
+// countingTranslator wraps a translator and counts the calls that reach it.
+type countingTranslator struct {
+	Translator
+	name  string
+	calls int
+	err   error
+}
+
+func (t *countingTranslator) Translate(c context.Context, text string, sourceLanguage string, targetLanguage string) (string, error) {
+	t.calls++
+	if t.err != nil {
+		return "", t.err
+	}
+	return t.Translator.Translate(c, text, sourceLanguage, targetLanguage)
+}
+
+func (t *countingTranslator) Name() string {
+	return t.name
+}
+
+func newCountingTranslator(name string) *countingTranslator {
+	return &countingTranslator{Translator: NewStubTranslator(), name: name}
+}
+
+func TestCachingTranslatorServesRepeatsFromCache(t *testing.T) {
+
+	provider := newCountingTranslator("stub")
+	cache := &memoryCacheRepository{entries: make(map[string]*CachedTranslation)}
+	translator := NewCachingTranslator(provider, cache)
+
+	for i := 0; i < 3; i++ {
+		translated, err := translator.Translate(context.Background(), "house", "en", "de")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if translated != "[de] house" {
+			t.Fatalf("translation = %q", translated)
+		}
+	}
+
+	if provider.calls != 1 || cache.saves != 1 {
+		t.Fatalf("provider calls = %d, saves = %d; want 1 and 1", provider.calls, cache.saves)
+	}
+}
+
+func TestCachingTranslatorMissesOnDifferentKey(t *testing.T) {
+
+	provider := newCountingTranslator("stub")
+	cache := &memoryCacheRepository{entries: make(map[string]*CachedTranslation)}
+	translator := NewCachingTranslator(provider, cache)
+
+	requests := [][3]string{
+		{"house", "en", "de"},
+		{"house", "en", "fr"},
+		{"house", "", "de"},
+		{"House", "en", "de"},
+	}
+	for _, req := range requests {
+		if _, err := translator.Translate(context.Background(), req[0], req[1], req[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if provider.calls != len(requests) {
+		t.Fatalf("provider calls = %d, want %d", provider.calls, len(requests))
+	}
+
+	// Another provider must not be served the first provider's entries.
+	other := newCountingTranslator("other")
+	if _, err := NewCachingTranslator(other, cache).Translate(context.Background(), "house", "en", "de"); err != nil {
+		t.Fatal(err)
+	}
+	if other.calls != 1 {
+		t.Fatal("expected a cache miss for another provider")
+	}
+}
+
+func TestCachingTranslatorDoesNotCacheFailures(t *testing.T) {
+
+	provider := newCountingTranslator("stub")
+	provider.err = errors.New("service unavailable")
+	cache := &memoryCacheRepository{entries: make(map[string]*CachedTranslation)}
+	translator := NewCachingTranslator(provider, cache)
+
+	if _, err := translator.Translate(context.Background(), "house", "en", "de"); err == nil {
+		t.Fatal("expected the provider error")
+	}
+	if cache.saves != 0 {
+		t.Fatal("a failed translation was cached")
+	}
+
+	provider.err = nil
+	translated, err := translator.Translate(context.Background(), "house", "en", "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if translated != "[de] house" || provider.calls != 2 {
+		t.Fatalf("translation = %q after %d calls", translated, provider.calls)
+	}
+}
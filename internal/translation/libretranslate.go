@@ -0,0 +1,92 @@
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type libreTranslator struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewLibreTranslator talks to a self-hosted LibreTranslate-compatible
+// service through its POST /translate endpoint.
+func NewLibreTranslator(baseURL string, apiKey string, timeout time.Duration) Translator {
+	return &libreTranslator{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type libreRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreResponse struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+func (t *libreTranslator) Translate(c context.Context, text string, sourceLanguage string, targetLanguage string) (string, error) {
+
+	if sourceLanguage == "" {
+		sourceLanguage = "auto"
+	}
+
+	payload, err := json.Marshal(&libreRequest{
+		Q:      text,
+		Source: sourceLanguage,
+		Target: targetLanguage,
+		Format: "text",
+		APIKey: t.apiKey,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, t.baseURL+"/translate", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	var result libreResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("translation service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest || result.Error != "" {
+		return "", fmt.Errorf("translation service returned status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	return result.TranslatedText, nil
+}
+
+func (t *libreTranslator) Name() string {
+	return ProviderLibreTranslate
+}
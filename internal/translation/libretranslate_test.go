@@ -0,0 +1,46 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLibreTranslator(t *testing.T) {
+
+	var received libreRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/translate" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		if received.Target == "xx" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "xx is not supported"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"translatedText": "Haus"})
+	}))
+	defer server.Close()
+
+	translator := NewLibreTranslator(server.URL+"/", "key", time.Second)
+
+	translated, err := translator.Translate(context.Background(), "house", "", "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if translated != "Haus" {
+		t.Fatalf("translation = %q", translated)
+	}
+	if received.Source != "auto" || received.APIKey != "key" || received.Q != "house" {
+		t.Fatalf("unexpected request %+v", received)
+	}
+
+	if _, err := translator.Translate(context.Background(), "house", "en", "xx"); err == nil {
+		t.Fatal("expected the service error")
+	}
+}
@@ -0,0 +1,17 @@
+package translation
+
+import (
+	"time"
+)
+
+// CachedTranslation is one translated text, stored under a hash of the text
+// and its language pair so each text is only sent to the provider once.
+type CachedTranslation struct {
+	ID             string    `json:"-" bson:"_id"`
+	Text           string    `json:"text" bson:"text"`
+	SourceLanguage string    `json:"source_language" bson:"source_language"`
+	TargetLanguage string    `json:"target_language" bson:"target_language"`
+	Translation    string    `json:"translation" bson:"translation"`
+	Provider       string    `json:"provider" bson:"provider"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,47 @@
+package translation
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CacheRepository interface {
+	GetTranslation(c context.Context, id string) (*CachedTranslation, error)
+	SaveTranslation(c context.Context, translation *CachedTranslation) error
+}
+
+type cacheRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCacheRepository(collection *mongo.Collection) CacheRepository {
+	return &cacheRepository{collection: collection}
+}
+
+func (r *cacheRepository) GetTranslation(c context.Context, id string) (*CachedTranslation, error) {
+
+	var translation CachedTranslation
+
+	err := r.collection.FindOne(c, bson.M{"_id": id}).Decode(&translation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &translation, nil
+}
+
+func (r *cacheRepository) SaveTranslation(c context.Context, translation *CachedTranslation) error {
+
+	_, err := r.collection.ReplaceOne(c, bson.M{"_id": translation.ID}, translation, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return nil
+}
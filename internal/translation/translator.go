@@ -0,0 +1,56 @@
+package translation
+
+import (
+	"context"
+	"flashcard/config"
+	"fmt"
+	"strings"
+)
+
+const (
+	ProviderStub           = "stub"
+	ProviderLibreTranslate = "libretranslate"
+)
+
+// Translator translates text between two languages. An empty source
+// language asks the provider to detect it.
+type Translator interface {
+	Translate(c context.Context, text string, sourceLanguage string, targetLanguage string) (string, error)
+	Name() string
+}
+
+// NewTranslator builds the provider named by TRANSLATION_PROVIDER. It
+// returns nil when translation is not configured.
+func NewTranslator(cfg *config.Config) (Translator, error) {
+
+	switch strings.ToLower(cfg.TranslationProvider) {
+	case "":
+		return nil, nil
+	case ProviderStub:
+		return NewStubTranslator(), nil
+	case ProviderLibreTranslate:
+		if cfg.TranslationAPIURL == "" {
+			return nil, fmt.Errorf("translation provider %s requires an api url", ProviderLibreTranslate)
+		}
+		return NewLibreTranslator(cfg.TranslationAPIURL, cfg.TranslationAPIKey, cfg.TranslationTimeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported translation provider %q", cfg.TranslationProvider)
+	}
+}
+
+type stubTranslator struct{}
+
+// NewStubTranslator returns a translator that works offline and is
+// deterministic: it tags the text with the target language instead of
+// translating it. It is meant for tests and local development.
+func NewStubTranslator() Translator {
+	return stubTranslator{}
+}
+
+func (stubTranslator) Translate(c context.Context, text string, sourceLanguage string, targetLanguage string) (string, error) {
+	return "[" + targetLanguage + "] " + text, nil
+}
+
+func (stubTranslator) Name() string {
+	return ProviderStub
+}
@@ -1,25 +1,29 @@
 package user
 
 import (
+	"errors"
 	"flashcard/helper"
+	"flashcard/middleware"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	UserService UserService
+	UserService  UserService
+	OAuthService OAuthService
 }
 
-func NewUserHandler(UserService UserService) *UserHandler {
-	return &UserHandler{UserService: UserService}
+func NewUserHandler(UserService UserService, OAuthService OAuthService) *UserHandler {
+	return &UserHandler{UserService: UserService, OAuthService: OAuthService}
 }
 
 func (h *UserHandler) RefreshToken(c *gin.Context) {
 
 	refreshToken := c.Query("refresh_token")
 
-	newToken, newRefreshToken, err := h.UserService.RefreshToken(refreshToken)
+	newToken, newRefreshToken, err := h.UserService.RefreshToken(c, refreshToken)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -87,6 +91,10 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 	
 	user, err := h.UserService.LoginUser(c, req.Email, req.Password)
 
+	if sendAccountLocked(c, err) {
+		return
+	}
+
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
@@ -95,41 +103,263 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 	helper.SendSuccess(c, http.StatusOK, "success", user)
 }
 
-func (h *UserHandler) DeleteUser(c *gin.Context) {
-	
-	userID := c.Param("user_id")
+func (h *UserHandler) LogoutUser(c *gin.Context) {
 
-	err := h.UserService.DeleteUser(c, userID)
+	userIdInterface, ok := c.Get("user_id")
+
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
 
+	userId, ok := userIdInterface.(string)
+	if !ok {
+		helper.SendError(c, http.StatusInternalServerError, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	err := h.UserService.LogoutUser(c, userId)
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
 	}
 
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
 }
 
-func (h *UserHandler) LogoutUser(c *gin.Context) {
+func (h *UserHandler) ChangePassword(c *gin.Context) {
 
-	userIdInterface, ok := c.Get("user_id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
 
+	user, err := h.UserService.ChangePassword(c, userID, &req)
+	if sendAccountLocked(c, err) {
+		return
+	}
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", user)
+}
+
+func (h *UserHandler) DeleteCurrentUser(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
 	if !ok {
 		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
 		return
 	}
 
-	userId, ok := userIdInterface.(string)
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	user, err := h.UserService.ScheduleAccountDeletion(c, userID, &req)
+	if sendAccountLocked(c, err) {
+		return
+	}
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", map[string]interface{}{
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	})
+}
+
+func (h *UserHandler) RestoreCurrentUser(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
 	if !ok {
-		helper.SendError(c, http.StatusInternalServerError, nil, helper.ErrInvalidOperation)
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
 		return
 	}
 
-	err := h.UserService.LogoutUser(c, userId)
+	err := h.UserService.RestoreAccount(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *UserHandler) CompleteTwoFactorLogin(c *gin.Context) {
+
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	user, err := h.UserService.CompleteTwoFactorLogin(c, &req)
+
+	if sendAccountLocked(c, err) {
+		return
+	}
+
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", user)
+}
+
+func (h *UserHandler) EnrollTwoFactor(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	enrollment, err := h.UserService.EnrollTwoFactor(c, userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", enrollment)
+}
+
+func (h *UserHandler) ConfirmTwoFactor(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	codes, err := h.UserService.ConfirmTwoFactor(c, userID, &req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", codes)
+}
+
+func (h *UserHandler) DisableTwoFactor(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.UserService.DisableTwoFactor(c, userID, &req)
+	if sendAccountLocked(c, err) {
+		return
+	}
 	if err != nil {
 		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 		return
 	}
 
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
+}
+
+func (h *UserHandler) BeginOAuthLogin(c *gin.Context) {
+
+	provider := c.Param("provider")
 
-}
\ No newline at end of file
+	authURL, err := h.OAuthService.BeginLogin(c, provider)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", map[string]string{
+		"auth_url": authURL,
+	})
+}
+
+func (h *UserHandler) BeginOAuthReauthentication(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	authURL, err := h.OAuthService.BeginReauthentication(c, c.Param("provider"), userID)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", map[string]string{
+		"auth_url": authURL,
+	})
+}
+
+func (h *UserHandler) CompleteOAuthLogin(c *gin.Context) {
+
+	provider := c.Param("provider")
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		helper.SendError(c, http.StatusBadRequest, errors.New(providerErr), helper.ErrInvalidRequest)
+		return
+	}
+
+	user, err := h.OAuthService.CompleteLogin(c, provider, c.Query("code"), c.Query("state"))
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", user)
+}
+
+func currentUserID(c *gin.Context) (string, bool) {
+
+	userIDInterface, ok := c.Get("user_id")
+	if !ok {
+		return "", false
+	}
+
+	userID, ok := userIDInterface.(string)
+	return userID, ok
+}
+
+// sendAccountLocked answers 429 with a Retry-After header when err reports a
+// locked account.
+func sendAccountLocked(c *gin.Context, err error) bool {
+
+	var lockedErr *AccountLockedError
+	if !errors.As(err, &lockedErr) {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(middleware.RetryAfterSeconds(lockedErr.RetryAfter)))
+	helper.SendError(c, http.StatusTooManyRequests, err, helper.ErrTooManyRequests)
+	return true
+}
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,4 +18,40 @@ type User struct {
 	UserType     string             `json:"user_type" bson:"user_type"`
 	CreatedAt    string             `json:"created_at" bson:"created_at"`
 	UpdatedAt    string             `json:"updated_at" bson:"updated_at"`
+
+	FailedLoginAttempts int        `json:"-" bson:"failed_login_attempts"`
+	LockedUntil         *time.Time `json:"-" bson:"locked_until,omitempty"`
+	SessionVersion      int        `json:"-" bson:"session_version"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" bson:"deletion_scheduled_at,omitempty"`
+	Identities          []Identity `json:"identities,omitempty" bson:"identities,omitempty"`
+
+	TwoFactorEnabled  bool     `json:"two_factor_enabled" bson:"two_factor_enabled"`
+	TOTPSecret        string   `json:"-" bson:"totp_secret,omitempty"`
+	PendingTOTPSecret string   `json:"-" bson:"pending_totp_secret,omitempty"`
+	LastTOTPCounter   int64    `json:"-" bson:"last_totp_counter"`
+	RecoveryCodes     []string `json:"-" bson:"recovery_codes,omitempty"`
+
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty" bson:"-"`
+	ChallengeToken    string `json:"challenge_token,omitempty" bson:"-"`
+	ReauthToken       string `json:"reauth_token,omitempty" bson:"-"`
+}
+
+type Identity struct {
+	Provider string    `json:"provider" bson:"provider"`
+	Subject  string    `json:"subject" bson:"subject"`
+	Email    string    `json:"email" bson:"email"`
+	LinkedAt time.Time `json:"linked_at" bson:"linked_at"`
+}
+
+type OAuthState struct {
+	State        string    `json:"state" bson:"_id"`
+	Provider     string    `json:"provider" bson:"provider"`
+	CodeVerifier string    `json:"-" bson:"code_verifier"`
+	Nonce        string    `json:"-" bson:"nonce"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" bson:"expires_at"`
+
+	// UserID is set when a signed-in user signs in again to confirm a
+	// sensitive change rather than to start a session.
+	UserID *primitive.ObjectID `json:"-" bson:"user_id,omitempty"`
 }
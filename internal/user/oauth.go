@@ -0,0 +1,499 @@
+package user
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flashcard/config"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+type OAuthProvider interface {
+	AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error)
+}
+
+func NewOAuthProviders(cfg *config.Config) (map[string]OAuthProvider, error) {
+
+	providers := make(map[string]OAuthProvider)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for name, providerCfg := range cfg.OAuthProviders {
+		if providerCfg.ClientID == "" || providerCfg.RedirectURL == "" {
+			return nil, fmt.Errorf("oauth provider %s requires a client id and redirect url", name)
+		}
+
+		switch providerCfg.Type {
+		case "oidc":
+			if providerCfg.IssuerURL == "" {
+				return nil, fmt.Errorf("oauth provider %s requires an issuer url", name)
+			}
+			providers[name] = &oidcProvider{config: providerCfg, client: client}
+		case "github":
+			providers[name] = &githubProvider{config: providerCfg, client: client}
+		default:
+			return nil, fmt.Errorf("oauth provider %s has unsupported type %q", name, providerCfg.Type)
+		}
+	}
+
+	return providers, nil
+}
+
+func randomURLSafeString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func buildAuthCodeURL(endpoint string, cfg config.OAuthProviderConfig, state, codeChallenge, nonce string) (string, error) {
+
+	authURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", cfg.RedirectURL)
+	query.Set("scope", strings.Join(cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	if nonce != "" {
+		query.Set("nonce", nonce)
+	}
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+type oauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+func exchangeCode(ctx context.Context, client *http.Client, endpoint string, cfg config.OAuthProviderConfig, code, codeVerifier string) (*oauthTokenResponse, error) {
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token oauthTokenResponse
+	if err := doJSON(client, req, &token); err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	if token.Error != "" {
+		return nil, fmt.Errorf("failed to exchange authorization code: %s %s", token.Error, token.ErrorDesc)
+	}
+
+	if token.AccessToken == "" {
+		return nil, errors.New("failed to exchange authorization code: missing access token")
+	}
+
+	return &token, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, out interface{}) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return doJSON(client, req, out)
+}
+
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s returned status %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type oidcProvider struct {
+	config config.OAuthProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	endpoint := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, p.client, endpoint, "", &discovery); err != nil {
+		return nil, fmt.Errorf("failed to load openid configuration: %w", err)
+	}
+
+	// The discovery document must describe the configured issuer, so that
+	// it cannot choose which issuer's tokens are accepted.
+	if !sameIssuer(discovery.Issuer, p.config.IssuerURL) {
+		return nil, fmt.Errorf("openid configuration names issuer %q, expected %q", discovery.Issuer, p.config.IssuerURL)
+	}
+
+	if p.config.AuthURL != "" {
+		discovery.AuthorizationEndpoint = p.config.AuthURL
+	}
+	if p.config.TokenURL != "" {
+		discovery.TokenEndpoint = p.config.TokenURL
+	}
+	if p.config.UserInfoURL != "" {
+		discovery.UserInfoEndpoint = p.config.UserInfoURL
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error) {
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return buildAuthCodeURL(discovery.AuthorizationEndpoint, p.config, state, codeChallenge, nonce)
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error) {
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, p.client, discovery.TokenEndpoint, p.config, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IDToken == "" {
+		return nil, errors.New("provider did not return an id token")
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, token.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &OAuthIdentity{
+		Provider:      p.config.Name,
+		Subject:       stringClaim(claims, "sub"),
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		FirstName:     stringClaim(claims, "given_name"),
+		LastName:      stringClaim(claims, "family_name"),
+	}
+
+	if identity.Email == "" && discovery.UserInfoEndpoint != "" {
+		var userInfo map[string]interface{}
+		if err := getJSON(ctx, p.client, discovery.UserInfoEndpoint, token.AccessToken, &userInfo); err != nil {
+			return nil, fmt.Errorf("failed to load user info: %w", err)
+		}
+		if stringClaim(userInfo, "sub") != identity.Subject {
+			return nil, errors.New("user info subject does not match id token")
+		}
+		identity.Email = stringClaim(userInfo, "email")
+		identity.EmailVerified = boolClaim(userInfo, "email_verified")
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("id token is missing the subject")
+	}
+
+	return identity, nil
+}
+
+func (p *oidcProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, rawToken, nonce string) (jwt.MapClaims, error) {
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, discovery, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if !sameIssuer(stringClaim(claims, "iss"), p.config.IssuerURL) {
+		return nil, errors.New("invalid id token: unexpected issuer")
+	}
+
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return nil, errors.New("invalid id token: unexpected audience")
+	}
+
+	if stringClaim(claims, "nonce") != nonce {
+		return nil, errors.New("invalid id token: nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+// sameIssuer compares issuer identifiers, ignoring a trailing slash on
+// either side.
+func sameIssuer(a, b string) bool {
+	return a != "" && strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+func (p *oidcProvider) signingKey(ctx context.Context, discovery *oidcDiscovery, kid string) (interface{}, error) {
+
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	// Unknown key id: the provider may have rotated its keys, so refresh the set.
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, p.client, discovery.JWKSURI, "", &keySet); err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = publicKey
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		if kid == "" && len(keys) == 1 {
+			for _, only := range keys {
+				return only, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+type githubProvider struct {
+	config config.OAuthProviderConfig
+	client *http.Client
+}
+
+func (p *githubProvider) AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error) {
+	return buildAuthCodeURL(p.config.AuthURL, p.config, state, codeChallenge, "")
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error) {
+
+	token, err := exchangeCode(ctx, p.client, p.config.TokenURL, p.config, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, p.client, p.config.UserInfoURL, token.AccessToken, &profile); err != nil {
+		return nil, fmt.Errorf("failed to load github profile: %w", err)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.client, p.config.EmailsURL, token.AccessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to load github emails: %w", err)
+	}
+
+	identity := &OAuthIdentity{
+		Provider: p.config.Name,
+		Subject:  fmt.Sprintf("%d", profile.ID),
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(profile.Name), " ")
+	if firstName == "" {
+		firstName = profile.Login
+	}
+	identity.FirstName = firstName
+	identity.LastName = lastName
+
+	return identity, nil
+}
+
+func stringClaim(claims map[string]interface{}, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+func boolClaim(claims map[string]interface{}, key string) bool {
+	switch value := claims[key].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
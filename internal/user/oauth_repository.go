@@ -0,0 +1,52 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type OAuthStateRepository interface {
+	Create(ctx context.Context, state *OAuthState) error
+	Consume(ctx context.Context, state string) (*OAuthState, error)
+}
+
+type oauthStateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOAuthStateRepository(collection *mongo.Collection) OAuthStateRepository {
+	return &oauthStateRepository{collection: collection}
+}
+
+func (r *oauthStateRepository) Create(ctx context.Context, state *OAuthState) error {
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": time.Now()}})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.InsertOne(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *oauthStateRepository) Consume(ctx context.Context, state string) (*OAuthState, error) {
+
+	var oauthState OAuthState
+
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": state}).Decode(&oauthState)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &oauthState, nil
+}
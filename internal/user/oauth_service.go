@@ -0,0 +1,226 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OAuthService interface {
+	BeginLogin(ctx context.Context, provider string) (string, error)
+	CompleteLogin(ctx context.Context, provider, code, state string) (*User, error)
+	BeginReauthentication(ctx context.Context, provider, userID string) (string, error)
+}
+
+type oauthService struct {
+	providers       map[string]OAuthProvider
+	stateRepository OAuthStateRepository
+	repository      UserRepository
+	userService     UserService
+	stateTTL        time.Duration
+}
+
+func NewOAuthService(providers map[string]OAuthProvider, stateRepository OAuthStateRepository, repository UserRepository, userService UserService, stateTTL time.Duration) OAuthService {
+	return &oauthService{
+		providers:       providers,
+		stateRepository: stateRepository,
+		repository:      repository,
+		userService:     userService,
+		stateTTL:        stateTTL,
+	}
+}
+
+func (s *oauthService) BeginLogin(ctx context.Context, provider string) (string, error) {
+	return s.begin(ctx, provider, nil)
+}
+
+// BeginReauthentication starts a provider login for a signed-in user. Its
+// callback returns a reauth token instead of a session, which confirms
+// password-protected changes on accounts that have no password.
+func (s *oauthService) BeginReauthentication(ctx context.Context, provider, userID string) (string, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.repository.FindByID(ctx, objectID)
+	if err != nil {
+		return "", err
+	}
+
+	if user == nil || !hasIdentity(user, provider) {
+		return "", fmt.Errorf("no %s account is linked to this user", provider)
+	}
+
+	return s.begin(ctx, provider, &objectID)
+}
+
+func (s *oauthService) begin(ctx context.Context, provider string, userID *primitive.ObjectID) (string, error) {
+
+	oauthProvider, ok := s.providers[provider]
+	if !ok {
+		return "", fmt.Errorf("unknown oauth provider %q", provider)
+	}
+
+	state, err := randomURLSafeString(32)
+	if err != nil {
+		return "", err
+	}
+
+	codeVerifier, err := randomURLSafeString(32)
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := randomURLSafeString(16)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := oauthProvider.AuthCodeURL(ctx, state, pkceChallenge(codeVerifier), nonce)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.stateRepository.Create(ctx, &OAuthState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		UserID:       userID,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.stateTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store oauth state: %w", err)
+	}
+
+	return authURL, nil
+}
+
+func (s *oauthService) CompleteLogin(ctx context.Context, provider, code, state string) (*User, error) {
+
+	if code == "" || state == "" {
+		return nil, fmt.Errorf("code and state are required")
+	}
+
+	oauthProvider, ok := s.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown oauth provider %q", provider)
+	}
+
+	oauthState, err := s.stateRepository.Consume(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if oauthState == nil || oauthState.Provider != provider || time.Now().After(oauthState.ExpiresAt) {
+		return nil, fmt.Errorf("invalid or expired oauth state")
+	}
+
+	identity, err := oauthProvider.Exchange(ctx, code, oauthState.CodeVerifier, oauthState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if oauthState.UserID != nil {
+		return s.reauthenticate(ctx, *oauthState.UserID, identity)
+	}
+
+	user, err := s.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userService.StartSession(ctx, user)
+}
+
+// reauthenticate checks that the provider account is the one linked to the
+// user who started the flow and hands out a reauth token.
+func (s *oauthService) reauthenticate(ctx context.Context, userID primitive.ObjectID, identity *OAuthIdentity) (*User, error) {
+
+	user, err := s.repository.FindByIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.ID != userID {
+		return nil, fmt.Errorf("the %s account is not linked to this user", identity.Provider)
+	}
+
+	reauthToken, err := s.userService.GenerateReauthToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{ID: user.ID, Email: user.Email, ReauthToken: reauthToken}, nil
+}
+
+func (s *oauthService) resolveUser(ctx context.Context, identity *OAuthIdentity) (*User, error) {
+
+	user, err := s.repository.FindByIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if user != nil {
+		return user, nil
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, fmt.Errorf("the %s account has no verified email address", identity.Provider)
+	}
+
+	email := normalizeEmail(identity.Email)
+	linked := Identity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    email,
+		LinkedAt: time.Now(),
+	}
+
+	user, err = s.repository.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user != nil {
+		if err := s.repository.AddIdentity(ctx, user.ID, linked); err != nil {
+			return nil, fmt.Errorf("failed to link %s account: %w", identity.Provider, err)
+		}
+		user.Identities = append(user.Identities, linked)
+		return user, nil
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	user = &User{
+		ID:         primitive.NewObjectID(),
+		FristName:  identity.FirstName,
+		LastName:   identity.LastName,
+		Email:      email,
+		UserType:   "user",
+		Identities: []Identity{linked},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	createdUser, err := s.repository.Create(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return createdUser, nil
+}
+
+func hasIdentity(user *User, provider string) bool {
+	for _, identity := range user.Identities {
+		if identity.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
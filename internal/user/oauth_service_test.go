@@ -0,0 +1,491 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"flashcard/config"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockOIDCProvider is an OpenID Connect provider that signs in whoever the
+// test authorizes for a code.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// discoveryIssuer and tokenIssuer override the issuer the provider
+	// announces and signs into id tokens, when set.
+	discoveryIssuer string
+	tokenIssuer     string
+
+	mu     sync.Mutex
+	logins map[string]mockLogin
+}
+
+type mockLogin struct {
+	claims        jwt.MapClaims
+	codeChallenge string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockOIDCProvider{key: key, logins: make(map[string]mockLogin)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := p.server.URL
+		if p.discoveryIssuer != "" {
+			issuer = p.discoveryIssuer
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		p.mu.Lock()
+		login, ok := p.logins[r.Form.Get("code")]
+		delete(p.logins, r.Form.Get("code"))
+		p.mu.Unlock()
+
+		if !ok || pkceChallenge(r.Form.Get("code_verifier")) != login.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, login.claims)
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(p.key)
+		if err != nil {
+			t.Error(err)
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"id_token":     idToken,
+			"token_type":   "Bearer",
+		})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// authorize plays the user approving the login started at authURL and
+// returns the code and state the callback receives.
+func (p *mockOIDCProvider) authorize(t *testing.T, authURL string, subject, email string) (string, string) {
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+
+	code := "code-" + query.Get("state")
+
+	issuer := p.server.URL
+	if p.tokenIssuer != "" {
+		issuer = p.tokenIssuer
+	}
+
+	p.mu.Lock()
+	p.logins[code] = mockLogin{
+		codeChallenge: query.Get("code_challenge"),
+		claims: jwt.MapClaims{
+			"iss":            issuer,
+			"aud":            "client",
+			"sub":            subject,
+			"email":          email,
+			"email_verified": true,
+			"nonce":          query.Get("nonce"),
+			"exp":            time.Now().Add(time.Minute).Unix(),
+		},
+	}
+	p.mu.Unlock()
+
+	return code, query.Get("state")
+}
+
+type memoryUserRepository struct {
+	users map[primitive.ObjectID]*User
+}
+
+func (r *memoryUserRepository) FindAll(ctx context.Context) ([]*User, error) {
+	var users []*User
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *User) (*User, error) {
+	stored := *user
+	r.users[user.ID] = &stored
+	return user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, userID primitive.ObjectID) (*User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUserRepository) UpdateByID(ctx context.Context, userID primitive.ObjectID, updateFields bson.M) error {
+	user := r.users[userID]
+	for field, value := range updateFields {
+		switch field {
+		case "password":
+			user.Password = value.(string)
+		case "session_version":
+			user.SessionVersion = value.(int)
+		case "deletion_scheduled_at":
+			scheduledAt, _ := value.(time.Time)
+			user.DeletionScheduledAt = &scheduledAt
+		case "failed_login_attempts":
+			user.FailedLoginAttempts = value.(int)
+		case "locked_until":
+			lockedUntil, ok := value.(time.Time)
+			if !ok {
+				user.LockedUntil = nil
+				continue
+			}
+			user.LockedUntil = &lockedUntil
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteByID(ctx context.Context, userID primitive.ObjectID) error {
+	delete(r.users, userID)
+	return nil
+}
+
+func (r *memoryUserRepository) IncrementFailedLogins(ctx context.Context, userID primitive.ObjectID) (int, error) {
+	r.users[userID].FailedLoginAttempts++
+	return r.users[userID].FailedLoginAttempts, nil
+}
+
+func (r *memoryUserRepository) FindScheduledForDeletion(ctx context.Context, before time.Time) ([]*User, error) {
+	var users []*User
+	for _, user := range r.users {
+		if user.DeletionScheduledAt != nil && user.DeletionScheduledAt.Before(before) {
+			found := *user
+			users = append(users, &found)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) FindByIdentity(ctx context.Context, provider, subject string) (*User, error) {
+	for _, user := range r.users {
+		for _, identity := range user.Identities {
+			if identity.Provider == provider && identity.Subject == subject {
+				found := *user
+				return &found, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryUserRepository) AddIdentity(ctx context.Context, userID primitive.ObjectID, identity Identity) error {
+	r.users[userID].Identities = append(r.users[userID].Identities, identity)
+	return nil
+}
+
+func (r *memoryUserRepository) ConsumeRecoveryCode(ctx context.Context, userID primitive.ObjectID, codeHash string) (bool, error) {
+	return false, nil
+}
+
+func (r *memoryUserRepository) AdvanceTOTPCounter(ctx context.Context, userID primitive.ObjectID, counter int64) (bool, error) {
+	return false, nil
+}
+
+type memoryOAuthStateRepository struct {
+	states map[string]*OAuthState
+}
+
+func (r *memoryOAuthStateRepository) Create(ctx context.Context, state *OAuthState) error {
+	r.states[state.State] = state
+	return nil
+}
+
+func (r *memoryOAuthStateRepository) Consume(ctx context.Context, state string) (*OAuthState, error) {
+	oauthState := r.states[state]
+	delete(r.states, state)
+	return oauthState, nil
+}
+
+type oauthFixture struct {
+	provider     *mockOIDCProvider
+	users        *memoryUserRepository
+	userService  UserService
+	oauthService OAuthService
+}
+
+func newOAuthFixture(t *testing.T) *oauthFixture {
+
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	provider := newMockOIDCProvider(t)
+	cfg := &config.Config{
+		PasswordHashAlgorithm: "bcrypt",
+		BcryptCost:            4,
+		Argon2Time:            1,
+		Argon2Memory:          64,
+		Argon2Threads:         1,
+		PasswordMinLength:     8,
+		MaxLoginAttempts:      5,
+		LockoutDuration:       time.Minute,
+		TwoFactorChallengeTTL: time.Minute,
+		OAuthProviders: map[string]config.OAuthProviderConfig{
+			"mock": {
+				Name:        "mock",
+				Type:        "oidc",
+				ClientID:    "client",
+				RedirectURL: "http://localhost/callback",
+				IssuerURL:   provider.server.URL,
+				Scopes:      []string{"openid", "email"},
+			},
+		},
+	}
+
+	hasher, err := NewPasswordHasher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	providers, err := NewOAuthProviders(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &memoryUserRepository{users: make(map[primitive.ObjectID]*User)}
+	userService := NewUserService(users, hasher, policy, cfg)
+	states := &memoryOAuthStateRepository{states: make(map[string]*OAuthState)}
+
+	return &oauthFixture{
+		provider:     provider,
+		users:        users,
+		userService:  userService,
+		oauthService: NewOAuthService(providers, states, users, userService, time.Minute),
+	}
+}
+
+func (f *oauthFixture) login(t *testing.T, subject, email string) (*User, error) {
+
+	authURL, err := f.oauthService.BeginLogin(context.Background(), "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := f.provider.authorize(t, authURL, subject, email)
+	return f.oauthService.CompleteLogin(context.Background(), "mock", code, state)
+}
+
+func TestOAuthLoginCreatesUser(t *testing.T) {
+
+	f := newOAuthFixture(t)
+
+	user, err := f.login(t, "subject-1", "New.User@Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Token == "" {
+		t.Fatal("expected a session token")
+	}
+	if user.Email != "new.user@example.com" {
+		t.Fatalf("email = %q, want it normalized", user.Email)
+	}
+
+	again, err := f.login(t, "subject-1", "new.user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != user.ID || len(f.users.users) != 1 {
+		t.Fatal("a second login with the same subject created another account")
+	}
+}
+
+func TestOAuthLoginLinksMixedCaseEmail(t *testing.T) {
+
+	f := newOAuthFixture(t)
+
+	existing := &User{ID: primitive.NewObjectID(), Email: "Alice@Example.com"}
+	f.users.users[existing.ID] = existing
+
+	user, err := f.login(t, "subject-1", "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.ID != existing.ID {
+		t.Fatal("expected the provider account to be linked to the existing user")
+	}
+	if len(f.users.users) != 1 || len(f.users.users[existing.ID].Identities) != 1 {
+		t.Fatal("expected exactly one account with one linked identity")
+	}
+}
+
+func TestOAuthLoginRejectsReplayedState(t *testing.T) {
+
+	f := newOAuthFixture(t)
+
+	authURL, err := f.oauthService.BeginLogin(context.Background(), "mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := f.provider.authorize(t, authURL, "subject-1", "user@example.com")
+	if _, err := f.oauthService.CompleteLogin(context.Background(), "mock", code, state); err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ = f.provider.authorize(t, authURL, "subject-1", "user@example.com")
+	if _, err := f.oauthService.CompleteLogin(context.Background(), "mock", code, state); err == nil {
+		t.Fatal("expected a used state to be rejected")
+	}
+}
+
+func TestOAuthReauthenticationSetsInitialPassword(t *testing.T) {
+
+	f := newOAuthFixture(t)
+
+	user, err := f.login(t, "subject-1", "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.userService.ChangePassword(context.Background(), user.ID.Hex(), &ChangePasswordRequest{NewPassword: "Passw0rdLong"})
+	if err == nil {
+		t.Fatal("expected a passwordless account to need a reauth token")
+	}
+
+	authURL, err := f.oauthService.BeginReauthentication(context.Background(), "mock", user.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := f.provider.authorize(t, authURL, "subject-1", "user@example.com")
+	reauth, err := f.oauthService.CompleteLogin(context.Background(), "mock", code, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reauth.ReauthToken == "" || reauth.Token != "" {
+		t.Fatal("expected a reauth token and no session")
+	}
+
+	_, err = f.userService.ChangePassword(context.Background(), user.ID.Hex(), &ChangePasswordRequest{NewPassword: "Passw0rdLong", ReauthToken: reauth.ReauthToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.userService.LoginUser(context.Background(), "USER@example.com", "Passw0rdLong"); err != nil {
+		t.Fatalf("login with the new password: %v", err)
+	}
+
+	_, err = f.userService.ScheduleAccountDeletion(context.Background(), user.ID.Hex(), &DeleteAccountRequest{ReauthToken: reauth.ReauthToken})
+	if err == nil {
+		t.Fatal("expected the reauth token to be revoked by the password change")
+	}
+}
+
+func TestOAuthReauthenticationRejectsOtherAccount(t *testing.T) {
+
+	f := newOAuthFixture(t)
+
+	user, err := f.login(t, "subject-1", "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.login(t, "subject-2", "other@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := f.oauthService.BeginReauthentication(context.Background(), "mock", user.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, state := f.provider.authorize(t, authURL, "subject-2", "other@example.com")
+	if _, err := f.oauthService.CompleteLogin(context.Background(), "mock", code, state); err == nil {
+		t.Fatal("expected another provider account to be rejected")
+	}
+}
+
+func TestOAuthLoginRejectsDiscoveryForAnotherIssuer(t *testing.T) {
+
+	f := newOAuthFixture(t)
+	f.provider.discoveryIssuer = "https://attacker.example.com"
+
+	if _, err := f.oauthService.BeginLogin(context.Background(), "mock"); err == nil {
+		t.Fatal("expected a discovery document naming another issuer to be rejected")
+	}
+}
+
+func TestOAuthLoginRejectsTokenFromAnotherIssuer(t *testing.T) {
+
+	f := newOAuthFixture(t)
+	f.provider.tokenIssuer = "https://attacker.example.com"
+
+	if _, err := f.login(t, "subject-1", "user@example.com"); err == nil {
+		t.Fatal("expected an id token from another issuer to be rejected")
+	}
+	if len(f.users.users) != 0 {
+		t.Fatal("a rejected login created an account")
+	}
+}
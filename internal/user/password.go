@@ -0,0 +1,216 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"flashcard/config"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HashAlgorithmBcrypt   = "bcrypt"
+	HashAlgorithmArgon2id = "argon2id"
+)
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(encodedHash, password string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+type passwordScheme interface {
+	PasswordHasher
+	Recognizes(encodedHash string) bool
+}
+
+// passwordHasher hashes new passwords with the configured scheme but still
+// verifies hashes written by any supported scheme, so stored hashes can be
+// upgraded on the next successful login.
+type passwordHasher struct {
+	current passwordScheme
+	schemes []passwordScheme
+}
+
+func NewPasswordHasher(cfg *config.Config) (PasswordHasher, error) {
+
+	bcryptScheme := &bcryptHasher{cost: cfg.BcryptCost}
+	argon2Scheme := &argon2idHasher{
+		time:       cfg.Argon2Time,
+		memory:     cfg.Argon2Memory,
+		threads:    cfg.Argon2Threads,
+		keyLength:  32,
+		saltLength: 16,
+	}
+
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid bcrypt cost %d", cfg.BcryptCost)
+	}
+
+	if cfg.Argon2Time == 0 || cfg.Argon2Memory == 0 || cfg.Argon2Threads == 0 {
+		return nil, fmt.Errorf("invalid argon2id parameters")
+	}
+
+	hasher := &passwordHasher{
+		schemes: []passwordScheme{bcryptScheme, argon2Scheme},
+	}
+
+	switch cfg.PasswordHashAlgorithm {
+	case HashAlgorithmBcrypt:
+		hasher.current = bcryptScheme
+	case HashAlgorithmArgon2id:
+		hasher.current = argon2Scheme
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+
+	return hasher, nil
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *passwordHasher) Verify(encodedHash, password string) (bool, error) {
+
+	for _, scheme := range h.schemes {
+		if scheme.Recognizes(encodedHash) {
+			return scheme.Verify(encodedHash, password)
+		}
+	}
+
+	return false, errors.New("unsupported password hash")
+}
+
+func (h *passwordHasher) NeedsRehash(encodedHash string) bool {
+	return !h.current.Recognizes(encodedHash) || h.current.NeedsRehash(encodedHash)
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h *bcryptHasher) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func (h *bcryptHasher) Verify(encodedHash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *bcryptHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
+
+type argon2idHasher struct {
+	time       uint32
+	memory     uint32
+	threads    uint8
+	keyLength  uint32
+	saltLength int
+}
+
+func (h *argon2idHasher) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+
+	salt := make([]byte, h.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.time, h.memory, h.threads, h.keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.time, h.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(encodedHash, password string) (bool, error) {
+
+	params, salt, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encodedHash string) bool {
+
+	params, salt, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params.time != h.time ||
+		params.memory != h.memory ||
+		params.threads != h.threads ||
+		uint32(len(key)) != h.keyLength ||
+		len(salt) != h.saltLength
+}
+
+func decodeArgon2idHash(encodedHash string) (*argon2idHasher, []byte, []byte, error) {
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, err
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, errors.New("incompatible argon2 version")
+	}
+
+	params := &argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,152 @@
+package user
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"flashcard/config"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const passwordMaxLength = 72
+
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	breachedPasswords map[string]struct{}
+	breachedHashes    map[string]struct{}
+}
+
+func NewPasswordPolicy(cfg *config.Config) (*PasswordPolicy, error) {
+
+	policy := &PasswordPolicy{
+		MinLength:         cfg.PasswordMinLength,
+		RequireUpper:      cfg.PasswordRequireUpper,
+		RequireLower:      cfg.PasswordRequireLower,
+		RequireDigit:      cfg.PasswordRequireDigit,
+		RequireSymbol:     cfg.PasswordRequireSymbol,
+		breachedPasswords: make(map[string]struct{}),
+		breachedHashes:    make(map[string]struct{}),
+	}
+
+	if cfg.PasswordBreachListPath == "" {
+		return policy, nil
+	}
+
+	if err := policy.loadBreachList(cfg.PasswordBreachListPath); err != nil {
+		return nil, fmt.Errorf("failed to load password breach list: %w", err)
+	}
+
+	return policy, nil
+}
+
+// loadBreachList reads one entry per line. Entries are either plain passwords
+// or SHA-1 hex digests (as published by Have I Been Pwned, optionally followed
+// by ":count").
+func (p *PasswordPolicy) loadBreachList(path string) error {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		candidate := line
+		if idx := strings.IndexByte(candidate, ':'); idx == sha1.Size*2 {
+			candidate = candidate[:idx]
+		}
+
+		if isSHA1Hex(candidate) {
+			p.breachedHashes[strings.ToUpper(candidate)] = struct{}{}
+			continue
+		}
+
+		p.breachedPasswords[strings.ToLower(line)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+func (p *PasswordPolicy) Validate(password string) error {
+
+	if len(password) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+
+	if len(password) > passwordMaxLength {
+		return fmt.Errorf("password must be at most %d characters long", passwordMaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return fmt.Errorf("password must contain an uppercase letter")
+	}
+
+	if p.RequireLower && !hasLower {
+		return fmt.Errorf("password must contain a lowercase letter")
+	}
+
+	if p.RequireDigit && !hasDigit {
+		return fmt.Errorf("password must contain a digit")
+	}
+
+	if p.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("password must contain a symbol")
+	}
+
+	if p.isBreached(password) {
+		return fmt.Errorf("password has appeared in a data breach, please choose another one")
+	}
+
+	return nil
+}
+
+func (p *PasswordPolicy) isBreached(password string) bool {
+
+	if _, ok := p.breachedPasswords[strings.ToLower(password)]; ok {
+		return true
+	}
+
+	if len(p.breachedHashes) == 0 {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	_, ok := p.breachedHashes[strings.ToUpper(hex.EncodeToString(sum[:]))]
+	return ok
+}
+
+func isSHA1Hex(value string) bool {
+	if len(value) != sha1.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
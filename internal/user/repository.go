@@ -2,10 +2,12 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository interface {
@@ -15,6 +17,12 @@ type UserRepository interface {
 	FindByID(ctx context.Context, userId primitive.ObjectID) (*User, error)
 	UpdateByID(ctx context.Context, userID primitive.ObjectID, updateFields bson.M) error
 	DeleteByID(ctx context.Context, userID primitive.ObjectID) error
+	IncrementFailedLogins(ctx context.Context, userID primitive.ObjectID) (int, error)
+	FindScheduledForDeletion(ctx context.Context, before time.Time) ([]*User, error)
+	FindByIdentity(ctx context.Context, provider, subject string) (*User, error)
+	AddIdentity(ctx context.Context, userID primitive.ObjectID, identity Identity) error
+	ConsumeRecoveryCode(ctx context.Context, userID primitive.ObjectID, codeHash string) (bool, error)
+	AdvanceTOTPCounter(ctx context.Context, userID primitive.ObjectID, counter int64) (bool, error)
 }
 
 type userRepository struct {
@@ -69,7 +77,7 @@ func (r *userRepository) FindByID(ctx context.Context, userID primitive.ObjectID
 		return nil, err
 	}
 	
-	return nil, nil
+	return &user, nil
 }
 
 func (r *userRepository) Create(ctx context.Context, user *User) (*User, error) {
@@ -87,7 +95,10 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*User,
 
 	var user User
 
-	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	// Accounts registered before emails were normalized may be stored in
+	// mixed case, so the match ignores case.
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -111,4 +122,103 @@ func (r *userRepository) UpdateByID(ctx context.Context, userID primitive.Object
 
 	return nil
 	
-}
\ No newline at end of file
+}
+
+func (r *userRepository) IncrementFailedLogins(ctx context.Context, userID primitive.ObjectID) (int, error) {
+
+	filter := bson.M{"_id": userID}
+	update := bson.M{"$inc": bson.M{"failed_login_attempts": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user User
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.FailedLoginAttempts, nil
+
+}
+
+func (r *userRepository) FindScheduledForDeletion(ctx context.Context, before time.Time) ([]*User, error) {
+
+	var users []*User
+
+	cursor, err := r.collection.Find(ctx, bson.M{"deletion_scheduled_at": bson.M{"$lte": before}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user User
+		if err := cursor.Decode(&user); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, nil
+
+}
+
+func (r *userRepository) FindByIdentity(ctx context.Context, provider, subject string) (*User, error) {
+
+	filter := bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}
+
+	var user User
+
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+
+}
+
+func (r *userRepository) AddIdentity(ctx context.Context, userID primitive.ObjectID, identity Identity) error {
+
+	filter := bson.M{"_id": userID}
+	update := bson.M{"$push": bson.M{"identities": identity}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (r *userRepository) ConsumeRecoveryCode(ctx context.Context, userID primitive.ObjectID, codeHash string) (bool, error) {
+
+	filter := bson.M{"_id": userID, "recovery_codes": codeHash}
+	update := bson.M{"$pull": bson.M{"recovery_codes": codeHash}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+
+}
+
+func (r *userRepository) AdvanceTOTPCounter(ctx context.Context, userID primitive.ObjectID, counter int64) (bool, error) {
+
+	filter := bson.M{"_id": userID, "last_totp_counter": bson.M{"$not": bson.M{"$gte": counter}}}
+	update := bson.M{"$set": bson.M{"last_totp_counter": counter}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+
+}
@@ -12,3 +12,29 @@ type LoginRequest struct {
 	Email    string `json:"email" bson:"email"`
 	Password string `json:"password" bson:"password"`
 }
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" bson:"current_password"`
+	NewPassword     string `json:"new_password" bson:"new_password"`
+	ReauthToken     string `json:"reauth_token" bson:"reauth_token"`
+}
+
+type DeleteAccountRequest struct {
+	Password    string `json:"password" bson:"password"`
+	ReauthToken string `json:"reauth_token" bson:"reauth_token"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" bson:"code"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" bson:"challenge_token"`
+	Code           string `json:"code" bson:"code"`
+	RecoveryCode   string `json:"recovery_code" bson:"recovery_code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password    string `json:"password" bson:"password"`
+	ReauthToken string `json:"reauth_token" bson:"reauth_token"`
+}
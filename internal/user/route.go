@@ -6,16 +6,25 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.Engine, handler *UserHandler) {
+func RegisterRoutes(r *gin.Engine, handler *UserHandler, ipRateLimiter, accountRateLimiter gin.HandlerFunc) {
 
 	userGroup := r.Group("/api/v1/user") 
 	{
-		userGroup.POST("/login", handler.LoginUser)
-		userGroup.POST("/register", handler.RegisterUser)
+		userGroup.POST("/login", ipRateLimiter, accountRateLimiter, handler.LoginUser)
+		userGroup.POST("/login/2fa", ipRateLimiter, handler.CompleteTwoFactorLogin)
+		userGroup.POST("/register", ipRateLimiter, accountRateLimiter, handler.RegisterUser)
 		userGroup.POST("/logout", middleware.JWTAuthMiddleware(), handler.LogoutUser)
 		userGroup.GET("", handler.GetAllUsers)
 		userGroup.GET("/:user_id", middleware.JWTAuthMiddleware(), handler.GetUserByID)
-		userGroup.DELETE("/:user_id", handler.DeleteUser)
+		userGroup.PUT("/me/password", middleware.JWTAuthMiddleware(), handler.ChangePassword)
+		userGroup.DELETE("/me", middleware.JWTAuthMiddleware(), handler.DeleteCurrentUser)
+		userGroup.POST("/me/restore", middleware.JWTAuthMiddleware(), handler.RestoreCurrentUser)
+		userGroup.POST("/me/2fa/enroll", middleware.JWTAuthMiddleware(), handler.EnrollTwoFactor)
+		userGroup.POST("/me/2fa/verify", middleware.JWTAuthMiddleware(), handler.ConfirmTwoFactor)
+		userGroup.POST("/me/2fa/disable", middleware.JWTAuthMiddleware(), handler.DisableTwoFactor)
 		userGroup.GET("/refresh", handler.RefreshToken)	
+		userGroup.GET("/oauth/:provider/login", ipRateLimiter, handler.BeginOAuthLogin)
+		userGroup.GET("/oauth/:provider/callback", ipRateLimiter, handler.CompleteOAuthLogin)
+		userGroup.GET("/me/oauth/:provider/reauth", middleware.JWTAuthMiddleware(), handler.BeginOAuthReauthentication)
 	}
 }
\ No newline at end of file
@@ -3,16 +3,17 @@ package user
 import (
 	"context"
 	"errors"
+	"flashcard/config"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
@@ -22,17 +23,63 @@ type UserService interface {
 	GetAllUsers(ctx context.Context) ([]*User, error)
 	DeleteUser(ctx context.Context, userID string) error
 	ValidateToken(tokenString string) (*jwt.Token, error)
-	RefreshToken(refreshToken string) (string, string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
 	LogoutUser(ctx context.Context, userID string) error
+	ValidateSession(ctx context.Context, userID string, sessionVersion int) error
+	ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) (*User, error)
+	ScheduleAccountDeletion(ctx context.Context, userID string, req *DeleteAccountRequest) (*User, error)
+	RestoreAccount(ctx context.Context, userID string) error
+	PurgeScheduledDeletions(ctx context.Context) error
+	StartSession(ctx context.Context, user *User) (*User, error)
+	CompleteTwoFactorLogin(ctx context.Context, req *TwoFactorLoginRequest) (*User, error)
+	EnrollTwoFactor(ctx context.Context, userID string) (*TwoFactorEnrollmentResponse, error)
+	ConfirmTwoFactor(ctx context.Context, userID string, req *TwoFactorCodeRequest) (*RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userID string, req *DisableTwoFactorRequest) error
+	GenerateReauthToken(user *User) (string, error)
+}
+
+const (
+	tokenTypeAccess    = "access"
+	tokenTypeRefresh   = "refresh"
+	tokenTypeChallenge = "2fa_challenge"
+	tokenTypeReauth    = "reauth"
+)
+
+type AccountDataRemover interface {
+	DeleteUserData(ctx context.Context, userID string) error
+}
+
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return "account is temporarily locked due to too many failed login attempts"
 }
 
 type userService struct {
-	repository     UserRepository
+	repository          UserRepository
+	hasher              PasswordHasher
+	policy              *PasswordPolicy
+	dataRemovers        []AccountDataRemover
+	maxLoginAttempts    int
+	lockoutDuration     time.Duration
+	deletionGracePeriod time.Duration
+	twoFactorIssuer     string
+	challengeTTL        time.Duration
 }
 
-func NewUserService(repository UserRepository) UserService {
+func NewUserService(repository UserRepository, hasher PasswordHasher, policy *PasswordPolicy, cfg *config.Config, dataRemovers ...AccountDataRemover) UserService {
 	return &userService{
-		repository:     repository,
+		repository:          repository,
+		hasher:              hasher,
+		policy:              policy,
+		dataRemovers:        dataRemovers,
+		maxLoginAttempts:    cfg.MaxLoginAttempts,
+		lockoutDuration:     cfg.LockoutDuration,
+		deletionGracePeriod: cfg.AccountDeletionGracePeriod,
+		twoFactorIssuer:     cfg.TwoFactorIssuer,
+		challengeTTL:        cfg.TwoFactorChallengeTTL,
 	}
 }
 
@@ -54,6 +101,12 @@ func (s *userService) DeleteUser(ctx context.Context, userID string) error {
 		return err
 	}
 
+	for _, remover := range s.dataRemovers {
+		if err := remover.DeleteUserData(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete user data: %w", err)
+		}
+	}
+
 	err = s.repository.DeleteByID(ctx, objectID)
 	if err != nil {
 		return err
@@ -64,17 +117,19 @@ func (s *userService) DeleteUser(ctx context.Context, userID string) error {
 
 func (s *userService) GetUserByID(ctx context.Context, userID string) (*User, error) {
 
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	user, err := s.findUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repository.FindByID(ctx, objectID)
+	user.Password = ""
+	return user, nil
 
 }
 
 func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*User, error) {
 
+	req.Email = normalizeEmail(req.Email)
 	if req.Email == "" {
 		return nil, fmt.Errorf("email is required")
 	}
@@ -87,6 +142,10 @@ func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*
 		return nil, fmt.Errorf("password is required")
 	}
 
+	if err := s.policy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
 	user, err := s.repository.FindByEmail(ctx, req.Email)
 
 	if user != nil {
@@ -97,9 +156,13 @@ func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*
 		return nil, fmt.Errorf("failed to check user existence: %w", err)
 	}
 
-	hashedPassword := s.HashPassword(req.Password)
+	hashedPassword, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
 	newUserID := primitive.NewObjectID()
-	token, refreshToken := s.GenerateToken(newUserID.Hex())
+	token, refreshToken := s.GenerateToken(newUserID.Hex(), 0)
 
 	now := time.Now().Format(time.RFC3339)
 	user = &User{
@@ -127,6 +190,7 @@ func (s *userService) RegisterUser(ctx context.Context, req *RegisterRequest) (*
 
 func (s *userService) LoginUser(ctx context.Context, email, password string) (*User, error) {
 
+	email = normalizeEmail(email)
 	if email == "" || password == "" {
 		return nil, fmt.Errorf("email and password are required")
 	}
@@ -136,20 +200,69 @@ func (s *userService) LoginUser(ctx context.Context, email, password string) (*U
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	isValid, _ := s.VerifyPassword(user.Password, password)
+	now := time.Now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return nil, &AccountLockedError{RetryAfter: user.LockedUntil.Sub(now)}
+	}
+
+	isValid, err := s.hasher.Verify(user.Password, password)
+	if err != nil {
+		log.Printf("Failed to verify password for user %s: %v", user.ID.Hex(), err)
+	}
 	if !isValid {
+		if err := s.recordFailedLogin(ctx, user, now); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	token, refreshToken := s.GenerateToken(user.ID.Hex())
+	if s.hasher.NeedsRehash(user.Password) {
+		rehashed, err := s.hasher.Hash(password)
+		if err != nil {
+			log.Printf("Failed to upgrade password hash for user %s: %v", user.ID.Hex(), err)
+		} else if err := s.repository.UpdateByID(ctx, user.ID, bson.M{"password": rehashed}); err != nil {
+			log.Printf("Failed to store upgraded password hash for user %s: %v", user.ID.Hex(), err)
+		}
+	}
+
+	return s.StartSession(ctx, user)
+}
+
+// StartSession issues the token pair for an authenticated user, or a
+// short-lived challenge token when the user still has to pass 2FA.
+func (s *userService) StartSession(ctx context.Context, user *User) (*User, error) {
+
+	if user.TwoFactorEnabled {
+		challengeToken, err := s.generateChallengeToken(user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &User{
+			ID:                user.ID,
+			Email:             user.Email,
+			TwoFactorEnabled:  true,
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+func (s *userService) issueTokens(ctx context.Context, user *User) (*User, error) {
+
+	token, refreshToken := s.GenerateToken(user.ID.Hex(), user.SessionVersion)
 
 	updateFields := bson.M{
-		"token":         token,
-		"refresh_token": refreshToken,
-		"updatedAt":     time.Now().Format(time.RFC3339),
+		"token":                 token,
+		"refresh_token":         refreshToken,
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+		"updatedAt":             time.Now().Format(time.RFC3339),
 	}
 
-	err = s.repository.UpdateByID(ctx, user.ID, updateFields)
+	err := s.repository.UpdateByID(ctx, user.ID, updateFields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user tokens: %w", err)
 	}
@@ -161,27 +274,35 @@ func (s *userService) LoginUser(ctx context.Context, email, password string) (*U
 	return user, nil
 }
 
-func (s *userService) HashPassword(password string) string {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+func (s *userService) recordFailedLogin(ctx context.Context, user *User, now time.Time) error {
+
+	if s.maxLoginAttempts <= 0 {
+		return nil
+	}
+
+	attempts, err := s.repository.IncrementFailedLogins(ctx, user.ID)
 	if err != nil {
-		log.Panic(err)
+		return fmt.Errorf("failed to record login attempt: %w", err)
 	}
-	return string(bytes)
-}
 
-func (s *userService) VerifyPassword(userPassword string, providedPassword string) (bool, string) {
-	err := bcrypt.CompareHashAndPassword([]byte(userPassword), []byte(providedPassword))
-	check := true
-	msg := ""
+	if attempts < s.maxLoginAttempts {
+		return nil
+	}
 
-	if err != nil {
-		msg = "Login or password is incorrect"
-		check = false
+	lockedUntil := now.Add(s.lockoutDuration)
+	updateFields := bson.M{
+		"failed_login_attempts": 0,
+		"locked_until":          lockedUntil,
 	}
-	return check, msg
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	return &AccountLockedError{RetryAfter: s.lockoutDuration}
 }
 
-func (s *userService) GenerateToken(userID string) (string, string) {
+func (s *userService) GenerateToken(userID string, sessionVersion int) (string, string) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		log.Panic("JWT_SECRET not set")
@@ -189,6 +310,8 @@ func (s *userService) GenerateToken(userID string) (string, string) {
 
 	claims := jwt.MapClaims{
 		"user_id": userID,
+		"sv":      sessionVersion,
+		"typ":     tokenTypeAccess,
 		"exp":     jwt.NewNumericDate(time.Now().Add(time.Hour * 8)),
 	}
 
@@ -200,6 +323,8 @@ func (s *userService) GenerateToken(userID string) (string, string) {
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": userID,
+		"sv":      sessionVersion,
+		"typ":     tokenTypeRefresh,
 		"exp":     jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
 	})
 	refreshTokenString, err := refreshToken.SignedString([]byte(secret))
@@ -239,7 +364,7 @@ func (s *userService) ValidateToken(tokenString string) (*jwt.Token, error) {
 	return token, nil
 }
 
-func (s *userService) RefreshToken(refreshToken string) (string, string, error) {
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
 	token, err := s.ValidateToken(refreshToken)
 	if err != nil {
 		return "", "", errors.New("invalid refresh token")
@@ -250,12 +375,21 @@ func (s *userService) RefreshToken(refreshToken string) (string, string, error)
 		return "", "", errors.New("invalid token claims")
 	}
 
+	if tokenType, ok := claims["typ"].(string); ok && tokenType != tokenTypeRefresh {
+		return "", "", errors.New("invalid refresh token")
+	}
+
 	user_id, ok := claims["user_id"].(string)
 	if !ok {
 		return "", "", errors.New("invalid email in token")
 	}
 
-	newToken, newRefreshToken := s.GenerateToken(user_id)
+	sessionVersion, _ := claims["sv"].(float64)
+	if err := s.ValidateSession(ctx, user_id, int(sessionVersion)); err != nil {
+		return "", "", err
+	}
+
+	newToken, newRefreshToken := s.GenerateToken(user_id, int(sessionVersion))
 	return newToken, newRefreshToken, nil
 }
 
@@ -279,3 +413,419 @@ func (s *userService) LogoutUser(ctx context.Context, userID string) error {
 
 	return nil
 }
+
+func (s *userService) ValidateSession(ctx context.Context, userID string, sessionVersion int) error {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repository.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if user.SessionVersion != sessionVersion {
+		return errors.New("session has been revoked")
+	}
+
+	return nil
+}
+
+func (s *userService) ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) (*User, error) {
+
+	if req.NewPassword == "" {
+		return nil, fmt.Errorf("new password is required")
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reauthenticate(ctx, user, req.CurrentPassword, req.ReauthToken); err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	sessionVersion := user.SessionVersion + 1
+	token, refreshToken := s.GenerateToken(user.ID.Hex(), sessionVersion)
+
+	updateFields := bson.M{
+		"password":        hashedPassword,
+		"session_version": sessionVersion,
+		"token":           token,
+		"refresh_token":   refreshToken,
+		"updated_at":      time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return nil, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	user.Token = token
+	user.RefreshToken = refreshToken
+	user.SessionVersion = sessionVersion
+	user.Password = ""
+
+	return user, nil
+}
+
+func (s *userService) ScheduleAccountDeletion(ctx context.Context, userID string, req *DeleteAccountRequest) (*User, error) {
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reauthenticate(ctx, user, req.Password, req.ReauthToken); err != nil {
+		return nil, err
+	}
+
+	scheduledAt := time.Now().Add(s.deletionGracePeriod)
+	updateFields := bson.M{
+		"deletion_scheduled_at": scheduledAt,
+		"updated_at":            time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	user.DeletionScheduledAt = &scheduledAt
+	user.Password = ""
+
+	return user, nil
+}
+
+func (s *userService) RestoreAccount(ctx context.Context, userID string) error {
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return fmt.Errorf("account is not scheduled for deletion")
+	}
+
+	updateFields := bson.M{
+		"deletion_scheduled_at": nil,
+		"updated_at":            time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return fmt.Errorf("failed to restore account: %w", err)
+	}
+
+	return nil
+}
+
+func (s *userService) PurgeScheduledDeletions(ctx context.Context) error {
+
+	users, err := s.repository.FindScheduledForDeletion(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := s.DeleteUser(ctx, user.ID.Hex()); err != nil {
+			log.Printf("Failed to purge user %s: %v", user.ID.Hex(), err)
+			continue
+		}
+	}
+
+	return nil
+}
+
+// normalizeEmail is applied wherever an email is stored or looked up, so
+// addresses that differ only in case belong to the same account.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *userService) findUser(ctx context.Context, userID string) (*User, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repository.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return user, nil
+}
+
+func (s *userService) generateChallengeToken(user *User) (string, error) {
+
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", errors.New("JWT_SECRET not set")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"sv":      user.SessionVersion,
+		"typ":     tokenTypeChallenge,
+		"exp":     jwt.NewNumericDate(time.Now().Add(s.challengeTTL)),
+	})
+
+	return token.SignedString([]byte(secret))
+}
+
+// GenerateReauthToken proves that the user has just signed in again, for
+// accounts without a password. It is bound to the current session version.
+func (s *userService) GenerateReauthToken(user *User) (string, error) {
+
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", errors.New("JWT_SECRET not set")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"sv":      user.SessionVersion,
+		"typ":     tokenTypeReauth,
+		"exp":     jwt.NewNumericDate(time.Now().Add(s.challengeTTL)),
+	})
+
+	return token.SignedString([]byte(secret))
+}
+
+// reauthenticate confirms a sensitive change with the user's password or,
+// for accounts created through an identity provider, with a reauth token
+// from a fresh provider login.
+// Wrong passwords count towards the same lockout as failed logins, so a
+// stolen access token cannot be used to guess the password.
+func (s *userService) reauthenticate(ctx context.Context, user *User, password string, reauthToken string) error {
+
+	if password != "" {
+		now := time.Now()
+		if user.LockedUntil != nil && user.LockedUntil.After(now) {
+			return &AccountLockedError{RetryAfter: user.LockedUntil.Sub(now)}
+		}
+
+		isValid, _ := s.hasher.Verify(user.Password, password)
+		if !isValid {
+			if err := s.recordFailedLogin(ctx, user, now); err != nil {
+				return err
+			}
+			return fmt.Errorf("password is incorrect")
+		}
+
+		if user.FailedLoginAttempts > 0 {
+			if err := s.repository.UpdateByID(ctx, user.ID, bson.M{"failed_login_attempts": 0}); err != nil {
+				return fmt.Errorf("failed to reset login attempts: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if reauthToken == "" {
+		if user.Password == "" {
+			return fmt.Errorf("reauth token is required; sign in again with your identity provider")
+		}
+		return fmt.Errorf("password is required")
+	}
+
+	token, err := s.ValidateToken(reauthToken)
+	if err != nil {
+		return errors.New("invalid or expired reauth token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenTypeReauth {
+		return errors.New("invalid or expired reauth token")
+	}
+
+	userID, _ := claims["user_id"].(string)
+	sessionVersion, _ := claims["sv"].(float64)
+	if userID != user.ID.Hex() || int(sessionVersion) != user.SessionVersion {
+		return errors.New("invalid or expired reauth token")
+	}
+
+	return nil
+}
+
+func (s *userService) CompleteTwoFactorLogin(ctx context.Context, req *TwoFactorLoginRequest) (*User, error) {
+
+	if req.ChallengeToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		return nil, fmt.Errorf("challenge token and code or recovery code are required")
+	}
+
+	token, err := s.ValidateToken(req.ChallengeToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != tokenTypeChallenge {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	userID, _ := claims["user_id"].(string)
+	sessionVersion, _ := claims["sv"].(float64)
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.SessionVersion != int(sessionVersion) || !user.TwoFactorEnabled {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	now := time.Now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return nil, &AccountLockedError{RetryAfter: user.LockedUntil.Sub(now)}
+	}
+
+	var verified bool
+	if req.RecoveryCode != "" {
+		verified, err = s.repository.ConsumeRecoveryCode(ctx, user.ID, hashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			return nil, err
+		}
+	} else if counter, ok := verifyTOTP(user.TOTPSecret, req.Code, now, user.LastTOTPCounter); ok {
+		verified, err = s.repository.AdvanceTOTPCounter(ctx, user.ID, counter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !verified {
+		if err := s.recordFailedLogin(ctx, user, now); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+func (s *userService) EnrollTwoFactor(ctx context.Context, userID string) (*TwoFactorEnrollmentResponse, error) {
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	updateFields := bson.M{
+		"pending_totp_secret": secret,
+		"updated_at":          time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return nil, fmt.Errorf("failed to start two-factor enrollment: %w", err)
+	}
+
+	return &TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: totpURI(s.twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *userService) ConfirmTwoFactor(ctx context.Context, userID string, req *TwoFactorCodeRequest) (*RecoveryCodesResponse, error) {
+
+	if req.Code == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	if user.PendingTOTPSecret == "" {
+		return nil, fmt.Errorf("two-factor enrollment has not been started")
+	}
+
+	counter, ok := verifyTOTP(user.PendingTOTPSecret, req.Code, time.Now(), 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	updateFields := bson.M{
+		"two_factor_enabled":  true,
+		"totp_secret":         user.PendingTOTPSecret,
+		"pending_totp_secret": "",
+		"last_totp_counter":   counter,
+		"recovery_codes":      hashes,
+		"updated_at":          time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *userService) DisableTwoFactor(ctx context.Context, userID string, req *DisableTwoFactorRequest) error {
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TwoFactorEnabled {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if err := s.reauthenticate(ctx, user, req.Password, req.ReauthToken); err != nil {
+		return err
+	}
+
+	updateFields := bson.M{
+		"two_factor_enabled":  false,
+		"totp_secret":         "",
+		"pending_totp_secret": "",
+		"last_totp_counter":   0,
+		"recovery_codes":      []string{},
+		"updated_at":          time.Now().Format(time.RFC3339),
+	}
+
+	if err := s.repository.UpdateByID(ctx, user.ID, updateFields); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	return nil
+}
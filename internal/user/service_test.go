@@ -0,0 +1,116 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"flashcard/config"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// failingRemover fails to delete the data of one user.
+type failingRemover struct {
+	failFor string
+	deleted []string
+}
+
+func (r *failingRemover) DeleteUserData(ctx context.Context, userID string) error {
+	if userID == r.failFor {
+		return errors.New("storage unavailable")
+	}
+	r.deleted = append(r.deleted, userID)
+	return nil
+}
+
+func newTestUserService(t *testing.T, users *memoryUserRepository, removers ...AccountDataRemover) UserService {
+
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	cfg := &config.Config{
+		PasswordHashAlgorithm: "bcrypt",
+		BcryptCost:            4,
+		Argon2Time:            1,
+		Argon2Memory:          64,
+		Argon2Threads:         1,
+		PasswordMinLength:     8,
+		MaxLoginAttempts:      3,
+		LockoutDuration:       time.Minute,
+		TwoFactorChallengeTTL: time.Minute,
+	}
+
+	hasher, err := NewPasswordHasher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPasswordPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewUserService(users, hasher, policy, cfg, removers...)
+}
+
+func TestChangePasswordLocksOutRepeatedWrongPasswords(t *testing.T) {
+
+	users := &memoryUserRepository{users: make(map[primitive.ObjectID]*User)}
+	service := newTestUserService(t, users)
+
+	registered, err := service.RegisterUser(context.Background(), &RegisterRequest{Email: "user@example.com", Password: "Passw0rdLong", Phone: "+15550100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := registered.ID.Hex()
+
+	change := func(current string) error {
+		_, err := service.ChangePassword(context.Background(), userID, &ChangePasswordRequest{CurrentPassword: current, NewPassword: "N3wPasswordLong"})
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := change("wrong"); err == nil {
+			t.Fatal("expected a wrong password to be rejected")
+		}
+	}
+
+	var locked *AccountLockedError
+	if err := change("wrong"); !errors.As(err, &locked) {
+		t.Fatalf("third wrong password: err = %v, want the account locked", err)
+	}
+
+	if err := change("Passw0rdLong"); !errors.As(err, &locked) {
+		t.Fatalf("correct password while locked: err = %v, want the account locked", err)
+	}
+
+	if _, err := service.LoginUser(context.Background(), "user@example.com", "Passw0rdLong"); !errors.As(err, &locked) {
+		t.Fatalf("login while locked: err = %v, want the account locked", err)
+	}
+}
+
+func TestPurgeScheduledDeletionsContinuesAfterFailure(t *testing.T) {
+
+	users := &memoryUserRepository{users: make(map[primitive.ObjectID]*User)}
+
+	scheduledAt := time.Now().Add(-time.Hour)
+	var ids []primitive.ObjectID
+	for i := 0; i < 3; i++ {
+		user := &User{ID: primitive.NewObjectID(), DeletionScheduledAt: &scheduledAt}
+		users.users[user.ID] = user
+		ids = append(ids, user.ID)
+	}
+
+	remover := &failingRemover{failFor: ids[1].Hex()}
+	service := newTestUserService(t, users, remover)
+
+	if err := service.PurgeScheduledDeletions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users.users) != 1 || users.users[ids[1]] == nil {
+		t.Fatalf("expected only the failing user to remain, %d users left", len(users.users))
+	}
+	if len(remover.deleted) != 2 {
+		t.Fatalf("deleted data of %d users, want 2", len(remover.deleted))
+	}
+}
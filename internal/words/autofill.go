@@ -0,0 +1,74 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"flashcard/helper"
+	"strings"
+)
+
+const maxAutofillSenses = 5
+
+// autofill completes a new word from the dictionary. Only fields the user
+// left empty are filled, and a word the dictionary does not know is saved
+// as it is.
+func (s *wordService) autofill(c context.Context, word *Word) error {
+
+	if strings.TrimSpace(word.Word) == "" {
+		return nil
+	}
+
+	language, err := normalizeLanguageCode(word.SourceLanguage)
+	if err != nil {
+		return err
+	}
+
+	entry, err := s.dictionary.Lookup(c, word.Word, language)
+	if errors.Is(err, helper.ErrResourceNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if word.Pronunciation == "" {
+		word.Pronunciation = entry.Pronunciation
+	}
+
+	if len(word.Synonyms) == 0 {
+		word.Synonyms = entry.Synonyms
+	}
+
+	if len(word.Antonyms) == 0 {
+		word.Antonyms = entry.Antonyms
+	}
+
+	if len(entry.Senses) == 0 {
+		return nil
+	}
+
+	if word.PartOfSpeech == "" && word.WordType == "" {
+		word.PartOfSpeech, _ = ParsePartOfSpeech(entry.Senses[0].PartOfSpeech)
+	}
+
+	if len(word.Senses) > 0 || word.Definition != "" {
+		if word.Example == nil && len(entry.Senses[0].Examples) > 0 {
+			example := entry.Senses[0].Examples[0]
+			word.Example = &example
+		}
+		return nil
+	}
+
+	for _, sense := range entry.Senses {
+		if len(word.Senses) == maxAutofillSenses {
+			break
+		}
+		examples := append([]string{}, sense.Examples...)
+		if len(word.Senses) == 0 && word.Example != nil {
+			examples = append([]string{*word.Example}, examples...)
+		}
+		word.Senses = append(word.Senses, Sense{Definition: sense.Definition, Examples: examples})
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package words
+
+import (
+	"context"
+	"flashcard/helper"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxBatchOperations = 500
+
+// batchEntry is an operation that passed validation and has a write queued.
+// known is the is_true value an update sets on the user's progress.
+type batchEntry struct {
+	result         *BatchOperationResult
+	word           *Word
+	contentChanged bool
+	known          *bool
+}
+
+// BatchWords validates every operation first, runs the valid ones as a single
+// bulk write and then syncs cards, tombstones, revisions and activity for the
+// ones that were applied.
+func (s *wordService) BatchWords(c context.Context, userID string, req *BatchWordRequest) (*BatchWordResponse, error) {
+
+	if len(req.Operations) == 0 {
+		return nil, fmt.Errorf("at least one operation is required")
+	}
+
+	if len(req.Operations) > maxBatchOperations {
+		return nil, fmt.Errorf("a batch holds at most %d operations", maxBatchOperations)
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &BatchWordResponse{Results: make([]*BatchOperationResult, 0, len(req.Operations))}
+	writes := make([]*WordWrite, 0, len(req.Operations))
+	entries := make([]*batchEntry, 0, len(req.Operations))
+	seen := make(map[string]bool, len(req.Operations))
+	var marked []*batchEntry
+
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		result := &BatchOperationResult{Index: i, Op: op.Op, ID: op.ID}
+		response.Results = append(response.Results, result)
+
+		if op.ID != "" {
+			if seen[op.ID] {
+				failBatchOperation(result, fmt.Errorf("word %s appears more than once in the batch", op.ID))
+				continue
+			}
+			seen[op.ID] = true
+		}
+
+		write, entry, err := s.prepareBatchOperation(c, op, userID, objectUserID)
+		if err != nil {
+			failBatchOperation(result, err)
+			continue
+		}
+
+		entry.result = result
+		result.ID = entry.word.ID.Hex()
+		result.Version = entry.word.Version
+		if write == nil {
+			result.Status = BatchStatusUnchanged
+			if entry.known != nil {
+				marked = append(marked, entry)
+			}
+			continue
+		}
+
+		writes = append(writes, write)
+		entries = append(entries, entry)
+	}
+
+	writeErrs, err := s.wordRepository.BulkWriteWords(c, writes)
+	if err != nil {
+		return nil, err
+	}
+
+	var synced, deleted []*Word
+	var activities []*batchEntry
+	touchedTopics := make(map[primitive.ObjectID]bool)
+	for i, entry := range entries {
+		if writeErrs[i] != nil {
+			failBatchOperation(entry.result, batchWriteError(writeErrs[i]))
+			continue
+		}
+
+		entry.result.Status = BatchStatusApplied
+		touchedTopics[entry.word.TopicID] = true
+		if entry.result.Op == BatchOpDelete {
+			deleted = append(deleted, entry.word)
+		} else {
+			synced = append(synced, entry.word)
+		}
+		if entry.contentChanged {
+			activities = append(activities, entry)
+		}
+		if entry.known != nil {
+			marked = append(marked, entry)
+		}
+	}
+
+	markedWords := map[bool][]*Word{}
+	for _, entry := range marked {
+		markedWords[*entry.known] = append(markedWords[*entry.known], entry.word)
+	}
+	for known, words := range markedWords {
+		if err := s.saveProgress(c, objectUserID, words, func(p *WordProgress) { p.IsTrue = known }); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.cardSync.SyncWordCards(c, synced...); err != nil {
+		return nil, err
+	}
+
+	if err := s.forgetDeletedWords(c, deleted); err != nil {
+		return nil, err
+	}
+
+	for topicID := range touchedTopics {
+		if err := s.topicRevisions.IncrementRevision(c, topicID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range activities {
+		action := ActivityWordUpdated
+		switch entry.result.Op {
+		case BatchOpCreate:
+			action = ActivityWordAdded
+		case BatchOpDelete:
+			action = ActivityWordDeleted
+		}
+		if err := s.topicAccess.RecordWordActivity(c, entry.word.TopicID, objectUserID, action, entry.word); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, result := range response.Results {
+		if result.Status == BatchStatusFailed {
+			response.Failed++
+		} else {
+			response.Applied++
+		}
+	}
+
+	return response, nil
+}
+
+// prepareBatchOperation checks permissions and builds the write for one
+// operation. An update that changes nothing returns no write.
+func (s *wordService) prepareBatchOperation(c context.Context, op *BatchWordOperation, userID string, objectUserID primitive.ObjectID) (*WordWrite, *batchEntry, error) {
+
+	switch op.Op {
+	case BatchOpCreate:
+		if op.Word == nil {
+			return nil, nil, fmt.Errorf("word is required to create a word")
+		}
+
+		req := *op.Word
+		if req.ID == "" {
+			req.ID = op.ID
+		}
+
+		word, err := s.newWord(c, &req, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &WordWrite{Insert: word}, &batchEntry{word: word, contentChanged: true}, nil
+
+	case BatchOpUpdate:
+		if op.Changes == nil {
+			return nil, nil, fmt.Errorf("changes are required to update a word")
+		}
+
+		word, err := s.loadEditableWord(c, op.ID, objectUserID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req := *op.Changes
+		req.ExpectedVersion = op.Version
+
+		before := word.Content()
+		expectedVersion := word.Version
+
+		set, unset, err := s.editWord(c, word, &req, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entry := &batchEntry{word: word, contentChanged: len(diffWordContent(before, word.Content())) > 0, known: req.IsTrue}
+		if len(set) == 0 && len(unset) == 0 {
+			return nil, entry, nil
+		}
+
+		return &WordWrite{Update: &WordFieldUpdate{
+			ID:              word.ID,
+			ExpectedVersion: expectedVersion,
+			Set:             set,
+			Unset:           unset,
+		}}, entry, nil
+
+	case BatchOpDelete:
+		word, err := s.loadEditableWord(c, op.ID, objectUserID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if op.Version != nil && *op.Version != word.Version {
+			return nil, nil, fmt.Errorf("%w: word is at version %d", helper.ErrPreconditionFailed, word.Version)
+		}
+
+		return &WordWrite{Delete: &WordDeletion{ID: word.ID, ExpectedVersion: word.Version}}, &batchEntry{word: word, contentChanged: true}, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// MoveTopicWords moves words of one topic into another topic the user owns.
+func (s *wordService) MoveTopicWords(c context.Context, topicID string, userID string, req *MoveTopicWordsRequest) (*BulkActionResponse, error) {
+
+	if req.TargetTopicID == "" {
+		return nil, fmt.Errorf("target topic id is required")
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(req.TargetTopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID, objectUserID, selected, err := s.selectTopicWords(c, topicID, userID, req.WordIDs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetID == sourceID {
+		return nil, fmt.Errorf("target topic must differ from the source topic")
+	}
+
+	if err := s.topicAccess.CanOwnTopic(c, targetID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.moveWords(c, objectUserID, targetID, selected, req.ResetProgress); err != nil {
+		return nil, err
+	}
+
+	return &BulkActionResponse{Updated: int64(len(selected))}, nil
+}
+
+// ResetTopicProgress clears the user's review history of words in a topic
+// and puts their cards back on a fresh schedule for them.
+func (s *wordService) ResetTopicProgress(c context.Context, topicID string, userID string, req *TopicWordsRequest) (*BulkActionResponse, error) {
+
+	_, objectUserID, selected, err := s.selectTopicWords(c, topicID, userID, req.WordIDs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveProgress(c, objectUserID, selected, resetWordProgress); err != nil {
+		return nil, err
+	}
+
+	if err := s.cardSync.ResetWordCards(c, objectUserID, wordIDs(selected)...); err != nil {
+		return nil, err
+	}
+
+	return &BulkActionResponse{Updated: int64(len(selected))}, nil
+}
+
+// MarkTopicWordsKnown marks words of a topic as known to the user and moves
+// their cards past the learning steps for them.
+func (s *wordService) MarkTopicWordsKnown(c context.Context, topicID string, userID string, req *TopicWordsRequest) (*BulkActionResponse, error) {
+
+	_, objectUserID, selected, err := s.selectTopicWords(c, topicID, userID, req.WordIDs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveProgress(c, objectUserID, selected, func(p *WordProgress) { p.IsTrue = true }); err != nil {
+		return nil, err
+	}
+
+	if err := s.cardSync.MarkWordCardsKnown(c, objectUserID, wordIDs(selected)...); err != nil {
+		return nil, err
+	}
+
+	return &BulkActionResponse{Updated: int64(len(selected))}, nil
+}
+
+// selectTopicWords checks that the user can view the topic, or edit it when
+// edit is set, and returns the requested words of it, or all of them when no
+// IDs are given.
+func (s *wordService) selectTopicWords(c context.Context, topicID string, userID string, ids []string, edit bool) (primitive.ObjectID, primitive.ObjectID, []*Word, error) {
+
+	if topicID == "" {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, fmt.Errorf("topic id is required")
+	}
+
+	objectTopicID, err := primitive.ObjectIDFromHex(topicID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	authorize := s.topicAccess.CanViewTopic
+	if edit {
+		authorize = s.topicAccess.CanEditTopic
+	}
+	if err := authorize(c, objectTopicID, objectUserID); err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	topicWords, err := s.wordRepository.GetWordsByTopicID(c, objectTopicID, &SearchWordRequest{})
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	if len(ids) == 0 {
+		return objectTopicID, objectUserID, topicWords, nil
+	}
+
+	byID := make(map[string]*Word, len(topicWords))
+	for _, word := range topicWords {
+		byID[word.ID.Hex()] = word
+	}
+
+	selected := make([]*Word, 0, len(ids))
+	for _, id := range ids {
+		word, ok := byID[id]
+		if !ok {
+			return primitive.NilObjectID, primitive.NilObjectID, nil, fmt.Errorf("%w: word %s is not in this topic", helper.ErrResourceNotFound, id)
+		}
+		delete(byID, id)
+		selected = append(selected, word)
+	}
+
+	return objectTopicID, objectUserID, selected, nil
+}
+
+func (s *wordService) loadEditableWord(c context.Context, id string, objectUserID primitive.ObjectID) (*Word, error) {
+
+	word, _, err := s.loadWord(c, id, objectUserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanEditTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	return word, nil
+}
+
+// forgetDeletedWords removes the cards, audio and offline copies of words
+// that were deleted in bulk.
+func (s *wordService) forgetDeletedWords(c context.Context, deleted []*Word) error {
+
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	if err := s.cardSync.DeleteWordCards(c, wordIDs(deleted)...); err != nil {
+		return err
+	}
+
+	if err := s.progressRepository.DeleteProgressByWordIDs(c, wordIDs(deleted)); err != nil {
+		return err
+	}
+
+	byTopic := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, word := range deleted {
+		byTopic[word.TopicID] = append(byTopic[word.TopicID], word.ID)
+	}
+	for topicID, ids := range byTopic {
+		if err := s.deletionLog.RecordWordDeletions(c, topicID, ids...); err != nil {
+			return err
+		}
+	}
+
+	return s.releaseAudio(c, deleted...)
+}
+
+func failBatchOperation(result *BatchOperationResult, err error) {
+	_, errorCode := helper.ServiceErrorStatus(err)
+	result.Status = BatchStatusFailed
+	result.Version = 0
+	result.Error = err.Error()
+	result.ErrorCode = errorCode
+}
+
+func batchWriteError(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("a word with this id already exists")
+	}
+	return err
+}
+
+func wordIDs(words []*Word) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(words))
+	for _, word := range words {
+		ids = append(ids, word.ID)
+	}
+	return ids
+}
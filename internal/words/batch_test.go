@@ -0,0 +1,736 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"flashcard/helper"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryWordRepository keeps words in a map and applies versioned writes the
+// way the collection does. beforeBulkWrite and beforeUpdate run between
+// loading and writing, to simulate another client's write landing in between.
+type memoryWordRepository struct {
+	WordRepository
+	words           map[primitive.ObjectID]*Word
+	beforeBulkWrite func()
+	beforeUpdate    func()
+}
+
+func newMemoryWordRepository(words ...*Word) *memoryWordRepository {
+	r := &memoryWordRepository{words: make(map[primitive.ObjectID]*Word)}
+	for _, word := range words {
+		r.words[word.ID] = copyWord(word)
+	}
+	return r
+}
+
+// copyWord detaches documents from the map, like a database would.
+func copyWord(word *Word) *Word {
+	raw, err := bson.Marshal(word)
+	if err != nil {
+		panic(err)
+	}
+	var clone Word
+	if err := bson.Unmarshal(raw, &clone); err != nil {
+		panic(err)
+	}
+	return &clone
+}
+
+// updateWord applies $set and $unset to a stored word through its BSON form.
+func updateWord(word *Word, set bson.M, unset bson.M) *Word {
+
+	raw, err := bson.Marshal(word)
+	if err != nil {
+		panic(err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		panic(err)
+	}
+	for key, value := range set {
+		doc[key] = value
+	}
+	for key := range unset {
+		delete(doc, key)
+	}
+
+	raw, err = bson.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	var updated Word
+	if err := bson.Unmarshal(raw, &updated); err != nil {
+		panic(err)
+	}
+	return &updated
+}
+
+func (r *memoryWordRepository) GetWordByID(c context.Context, id primitive.ObjectID) (*Word, error) {
+	if word, ok := r.words[id]; ok {
+		return copyWord(word), nil
+	}
+	return nil, nil
+}
+
+func (r *memoryWordRepository) GetWordsByIDs(c context.Context, ids []primitive.ObjectID) ([]*Word, error) {
+	var found []*Word
+	for _, id := range ids {
+		if word, ok := r.words[id]; ok {
+			found = append(found, copyWord(word))
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryWordRepository) GetWordsByTopicID(c context.Context, topicID primitive.ObjectID, req *SearchWordRequest) ([]*Word, error) {
+	var found []*Word
+	for _, word := range r.words {
+		if word.TopicID == topicID {
+			found = append(found, copyWord(word))
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryWordRepository) CountWordsByTopicID(c context.Context, topicID primitive.ObjectID) (int64, error) {
+	var count int64
+	for _, word := range r.words {
+		if word.TopicID == topicID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryWordRepository) CreateWords(c context.Context, words []*Word) error {
+	for _, word := range words {
+		r.words[word.ID] = copyWord(word)
+	}
+	return nil
+}
+
+func (r *memoryWordRepository) BulkWriteWords(c context.Context, writes []*WordWrite) ([]error, error) {
+
+	if r.beforeBulkWrite != nil {
+		r.beforeBulkWrite()
+	}
+
+	results := make([]error, len(writes))
+	for i, write := range writes {
+		switch {
+		case write.Insert != nil:
+			if _, ok := r.words[write.Insert.ID]; ok {
+				results[i] = mongo.WriteError{Index: i, Code: 11000, Message: "duplicate key"}
+				continue
+			}
+			r.words[write.Insert.ID] = copyWord(write.Insert)
+		case write.Update != nil:
+			stored, ok := r.words[write.Update.ID]
+			if !ok || stored.Version != write.Update.ExpectedVersion {
+				results[i] = fmt.Errorf("%w: word was modified concurrently", helper.ErrPreconditionFailed)
+				continue
+			}
+			r.words[write.Update.ID] = updateWord(stored, write.Update.Set, write.Update.Unset)
+		case write.Delete != nil:
+			stored, ok := r.words[write.Delete.ID]
+			if !ok || stored.Version != write.Delete.ExpectedVersion {
+				results[i] = fmt.Errorf("%w: word was modified concurrently", helper.ErrPreconditionFailed)
+				continue
+			}
+			delete(r.words, write.Delete.ID)
+		}
+	}
+	return results, nil
+}
+
+func (r *memoryWordRepository) UpdateWordFields(c context.Context, id primitive.ObjectID, expectedVersion int64, set bson.M, unset bson.M) (bool, error) {
+
+	if r.beforeUpdate != nil {
+		r.beforeUpdate()
+	}
+
+	stored, ok := r.words[id]
+	if !ok || stored.Version != expectedVersion {
+		return false, nil
+	}
+	r.words[id] = updateWord(stored, set, unset)
+	return true, nil
+}
+
+func (r *memoryWordRepository) DeleteWord(c context.Context, id primitive.ObjectID) error {
+	delete(r.words, id)
+	return nil
+}
+
+func (r *memoryWordRepository) UpdateWordsInTopic(c context.Context, topicID primitive.ObjectID, wordIDs []primitive.ObjectID, set bson.M, unset bson.M) (int64, error) {
+	var modified int64
+	for _, id := range wordIDs {
+		stored, ok := r.words[id]
+		if !ok || stored.TopicID != topicID {
+			continue
+		}
+		updated := updateWord(stored, set, unset)
+		updated.Version++
+		r.words[id] = updated
+		modified++
+	}
+	return modified, nil
+}
+
+type memoryProgressRepository struct {
+	ProgressRepository
+	progress map[string]*WordProgress
+}
+
+func newMemoryProgressRepository() *memoryProgressRepository {
+	return &memoryProgressRepository{progress: make(map[string]*WordProgress)}
+}
+
+func (r *memoryProgressRepository) GetProgress(c context.Context, userID primitive.ObjectID, wordIDs []primitive.ObjectID) ([]*WordProgress, error) {
+	var found []*WordProgress
+	for _, id := range wordIDs {
+		if p, ok := r.progress[progressID(id, userID)]; ok {
+			clone := *p
+			found = append(found, &clone)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryProgressRepository) GetStudiedWordIDs(c context.Context, wordIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	wanted := make(map[primitive.ObjectID]bool, len(wordIDs))
+	for _, id := range wordIDs {
+		wanted[id] = true
+	}
+	var studied []primitive.ObjectID
+	for _, p := range r.progress {
+		if wanted[p.WordID] && p.ReviewCount > 0 {
+			studied = append(studied, p.WordID)
+		}
+	}
+	return studied, nil
+}
+
+func (r *memoryProgressRepository) SaveProgress(c context.Context, progress []*WordProgress) error {
+	for _, p := range progress {
+		clone := *p
+		r.progress[p.ID] = &clone
+	}
+	return nil
+}
+
+func (r *memoryProgressRepository) DeleteProgressByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error {
+	for _, id := range wordIDs {
+		for key, p := range r.progress {
+			if p.WordID == id {
+				delete(r.progress, key)
+			}
+		}
+	}
+	return nil
+}
+
+// memoryTopicAccess grants roles per topic and user: "viewer", "editor" or
+// "owner".
+type memoryTopicAccess struct {
+	TopicAccess
+	roles map[primitive.ObjectID]map[primitive.ObjectID]string
+}
+
+func newMemoryTopicAccess() *memoryTopicAccess {
+	return &memoryTopicAccess{roles: make(map[primitive.ObjectID]map[primitive.ObjectID]string)}
+}
+
+func (a *memoryTopicAccess) grant(topicID, userID primitive.ObjectID, role string) {
+	if a.roles[topicID] == nil {
+		a.roles[topicID] = make(map[primitive.ObjectID]string)
+	}
+	a.roles[topicID][userID] = role
+}
+
+func (a *memoryTopicAccess) require(topicID, userID primitive.ObjectID, roles ...string) error {
+	role := a.roles[topicID][userID]
+	for _, allowed := range roles {
+		if role == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: topic access", helper.ErrPermissionDenied)
+}
+
+func (a *memoryTopicAccess) CanViewTopic(c context.Context, topicID, userID primitive.ObjectID) error {
+	return a.require(topicID, userID, "viewer", "editor", "owner")
+}
+
+func (a *memoryTopicAccess) CanEditTopic(c context.Context, topicID, userID primitive.ObjectID) error {
+	return a.require(topicID, userID, "editor", "owner")
+}
+
+func (a *memoryTopicAccess) CanOwnTopic(c context.Context, topicID, userID primitive.ObjectID) error {
+	return a.require(topicID, userID, "owner")
+}
+
+func (a *memoryTopicAccess) RecordWordActivity(c context.Context, topicID, userID primitive.ObjectID, action string, word *Word) error {
+	return nil
+}
+
+// recordingCardSync remembers which words' cards each call touched.
+type recordingCardSync struct {
+	synced, deleted, reset, known []primitive.ObjectID
+	copied                        map[primitive.ObjectID]*Word
+}
+
+func (s *recordingCardSync) SyncWordCards(c context.Context, words ...*Word) error {
+	s.synced = append(s.synced, wordIDs(words)...)
+	return nil
+}
+
+func (s *recordingCardSync) DeleteWordCards(c context.Context, ids ...primitive.ObjectID) error {
+	s.deleted = append(s.deleted, ids...)
+	return nil
+}
+
+func (s *recordingCardSync) ResetWordCards(c context.Context, userID primitive.ObjectID, ids ...primitive.ObjectID) error {
+	s.reset = append(s.reset, ids...)
+	return nil
+}
+
+func (s *recordingCardSync) MarkWordCardsKnown(c context.Context, userID primitive.ObjectID, ids ...primitive.ObjectID) error {
+	s.known = append(s.known, ids...)
+	return nil
+}
+
+func (s *recordingCardSync) CopyWordCards(c context.Context, userID primitive.ObjectID, copies map[primitive.ObjectID]*Word) error {
+	s.copied = copies
+	return nil
+}
+
+func (s *recordingCardSync) DeleteTopicCards(c context.Context, topicID primitive.ObjectID) error {
+	return nil
+}
+
+type recordingDeletionLog struct {
+	deleted []primitive.ObjectID
+}
+
+func (l *recordingDeletionLog) RecordWordDeletions(c context.Context, topicID primitive.ObjectID, wordIDs ...primitive.ObjectID) error {
+	l.deleted = append(l.deleted, wordIDs...)
+	return nil
+}
+
+type countingRevisions struct {
+	revisions map[primitive.ObjectID]int
+}
+
+func (r *countingRevisions) IncrementRevision(c context.Context, topicID primitive.ObjectID) error {
+	if r.revisions == nil {
+		r.revisions = make(map[primitive.ObjectID]int)
+	}
+	r.revisions[topicID]++
+	return nil
+}
+
+type testWordService struct {
+	*wordService
+	words    *memoryWordRepository
+	progress *memoryProgressRepository
+	access   *memoryTopicAccess
+	cards    *recordingCardSync
+	deletion *recordingDeletionLog
+}
+
+func newTestWordService(words ...*Word) *testWordService {
+
+	ts := &testWordService{
+		words:    newMemoryWordRepository(words...),
+		progress: newMemoryProgressRepository(),
+		access:   newMemoryTopicAccess(),
+		cards:    &recordingCardSync{},
+		deletion: &recordingDeletionLog{},
+	}
+	ts.wordService = NewWordService(ts.words, ts.progress, &countingRevisions{}, ts.access, nil, 0, nil, ts.cards, ts.deletion, nil, nil, nil).(*wordService)
+
+	return ts
+}
+
+// testWord builds a word the way newWord stores it.
+func testWord(topicID, userID primitive.ObjectID, text string) *Word {
+	now := time.Now()
+	word := &Word{
+		ID:         primitive.NewObjectID(),
+		TopicID:    topicID,
+		UserID:     userID,
+		Word:       text,
+		Definition: text + " definition",
+		Senses:     []Sense{{Definition: text + " definition", Examples: []string{}}},
+		Version:    1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := prepareCard(word); err != nil {
+		panic(err)
+	}
+	return word
+}
+
+func stringPtr(s string) *string { return &s }
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestBatchWords(t *testing.T) {
+
+	ownerID := primitive.NewObjectID()
+	viewerID := primitive.NewObjectID()
+	topicID := primitive.NewObjectID()
+
+	cases := []struct {
+		name string
+		// ops builds the batch from the seeded words.
+		ops            func(a, b *Word) []BatchWordOperation
+		userID         primitive.ObjectID
+		beforeWrite    func(ts *testWordService, a, b *Word)
+		wantStatuses   []string
+		wantErrorCodes []string
+		wantApplied    int
+		wantFailed     int
+		check          func(t *testing.T, ts *testWordService, a, b *Word)
+	}{
+		{
+			name: "valid operations apply while invalid ones fail",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpUpdate, ID: a.ID.Hex(), Changes: &UpdateWordRequest{Definition: stringPtr("changed")}},
+					{Op: BatchOpUpdate, ID: primitive.NewObjectID().Hex(), Changes: &UpdateWordRequest{Definition: stringPtr("missing")}},
+					{Op: BatchOpCreate, Word: &CreateWordRequest{TopicID: topicID.Hex(), Word: "new", Definition: "new definition"}},
+					{Op: BatchOpCreate, Word: &CreateWordRequest{TopicID: topicID.Hex(), Definition: "no word"}},
+					{Op: "rename", ID: b.ID.Hex()},
+				}
+			},
+			userID:         ownerID,
+			wantStatuses:   []string{BatchStatusApplied, BatchStatusFailed, BatchStatusApplied, BatchStatusFailed, BatchStatusFailed},
+			wantErrorCodes: []string{"", helper.ErrNotFound, "", helper.ErrInvalidOperation, helper.ErrInvalidOperation},
+			wantApplied:    2,
+			wantFailed:     3,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID]; got.Definition != "changed" || got.Version != 2 {
+					t.Errorf("word a = %q at version %d, want %q at version 2", got.Definition, got.Version, "changed")
+				}
+				if len(ts.words.words) != 3 {
+					t.Errorf("got %d stored words, want 3", len(ts.words.words))
+				}
+				if len(ts.cards.synced) != 2 {
+					t.Errorf("synced cards of %d words, want 2", len(ts.cards.synced))
+				}
+			},
+		},
+		{
+			name: "a stale version is rejected before writing",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpUpdate, ID: a.ID.Hex(), Version: int64Ptr(0), Changes: &UpdateWordRequest{Definition: stringPtr("stale")}},
+					{Op: BatchOpDelete, ID: b.ID.Hex(), Version: int64Ptr(3)},
+				}
+			},
+			userID:         ownerID,
+			wantStatuses:   []string{BatchStatusFailed, BatchStatusFailed},
+			wantErrorCodes: []string{helper.ErrVersionMismatch, helper.ErrVersionMismatch},
+			wantFailed:     2,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID]; got.Definition != a.Definition {
+					t.Errorf("word a was changed to %q", got.Definition)
+				}
+				if _, ok := ts.words.words[b.ID]; !ok {
+					t.Error("word b was deleted")
+				}
+			},
+		},
+		{
+			name: "a write that lost a race reports a version conflict",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpUpdate, ID: a.ID.Hex(), Changes: &UpdateWordRequest{Definition: stringPtr("mine")}},
+					{Op: BatchOpDelete, ID: b.ID.Hex()},
+				}
+			},
+			userID: ownerID,
+			beforeWrite: func(ts *testWordService, a, b *Word) {
+				ts.words.words[a.ID].Definition = "theirs"
+				ts.words.words[a.ID].Version++
+			},
+			wantStatuses:   []string{BatchStatusFailed, BatchStatusApplied},
+			wantErrorCodes: []string{helper.ErrVersionMismatch, ""},
+			wantApplied:    1,
+			wantFailed:     1,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID].Definition; got != "theirs" {
+					t.Errorf("word a = %q, want the concurrent write kept", got)
+				}
+				if len(ts.cards.deleted) != 1 || ts.cards.deleted[0] != b.ID {
+					t.Errorf("deleted cards of %v, want only word b", ts.cards.deleted)
+				}
+				if len(ts.deletion.deleted) != 1 || ts.deletion.deleted[0] != b.ID {
+					t.Errorf("tombstoned %v, want only word b", ts.deletion.deleted)
+				}
+			},
+		},
+		{
+			name: "a word named twice fails the second time",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpUpdate, ID: a.ID.Hex(), Changes: &UpdateWordRequest{Definition: stringPtr("first")}},
+					{Op: BatchOpDelete, ID: a.ID.Hex()},
+				}
+			},
+			userID:         ownerID,
+			wantStatuses:   []string{BatchStatusApplied, BatchStatusFailed},
+			wantErrorCodes: []string{"", helper.ErrInvalidOperation},
+			wantApplied:    1,
+			wantFailed:     1,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got, ok := ts.words.words[a.ID]; !ok || got.Definition != "first" {
+					t.Errorf("word a = %+v, want it updated and kept", got)
+				}
+			},
+		},
+		{
+			name: "creating an existing id fails without touching the word",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpCreate, ID: a.ID.Hex(), Word: &CreateWordRequest{TopicID: topicID.Hex(), Word: "again", Definition: "again"}},
+				}
+			},
+			userID:         ownerID,
+			wantStatuses:   []string{BatchStatusFailed},
+			wantErrorCodes: []string{helper.ErrInvalidOperation},
+			wantFailed:     1,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID].Word; got != a.Word {
+					t.Errorf("word a = %q, want it untouched", got)
+				}
+			},
+		},
+		{
+			name: "marking known without other changes only saves the user's progress",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpUpdate, ID: a.ID.Hex(), Changes: &UpdateWordRequest{IsTrue: boolPtr(true)}},
+				}
+			},
+			userID:       ownerID,
+			wantStatuses: []string{BatchStatusUnchanged},
+			wantApplied:  1,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID]; got.IsTrue || got.Version != 1 {
+					t.Errorf("stored word was changed: is_true %v at version %d", got.IsTrue, got.Version)
+				}
+				if p := ts.progress.progress[progressID(a.ID, ownerID)]; p == nil || !p.IsTrue {
+					t.Errorf("progress = %+v, want the word known", p)
+				}
+			},
+		},
+		{
+			name: "viewers cannot edit",
+			ops: func(a, b *Word) []BatchWordOperation {
+				return []BatchWordOperation{
+					{Op: BatchOpDelete, ID: a.ID.Hex()},
+				}
+			},
+			userID:         viewerID,
+			wantStatuses:   []string{BatchStatusFailed},
+			wantErrorCodes: []string{helper.ErrForbidden},
+			wantFailed:     1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			a := testWord(topicID, ownerID, "alpha")
+			b := testWord(topicID, ownerID, "beta")
+			ts := newTestWordService(a, b)
+			ts.access.grant(topicID, ownerID, "owner")
+			ts.access.grant(topicID, viewerID, "viewer")
+			if tc.beforeWrite != nil {
+				ts.words.beforeBulkWrite = func() { tc.beforeWrite(ts, a, b) }
+			}
+
+			resp, err := ts.BatchWords(context.Background(), tc.userID.Hex(), &BatchWordRequest{Operations: tc.ops(a, b)})
+			if err != nil {
+				t.Fatalf("BatchWords: %v", err)
+			}
+
+			if len(resp.Results) != len(tc.wantStatuses) {
+				t.Fatalf("got %d results, want %d", len(resp.Results), len(tc.wantStatuses))
+			}
+			for i, result := range resp.Results {
+				if result.Status != tc.wantStatuses[i] {
+					t.Errorf("result %d: status %q, want %q (%s)", i, result.Status, tc.wantStatuses[i], result.Error)
+				}
+				if tc.wantErrorCodes != nil && result.ErrorCode != tc.wantErrorCodes[i] {
+					t.Errorf("result %d: error code %q, want %q", i, result.ErrorCode, tc.wantErrorCodes[i])
+				}
+			}
+			if resp.Applied != tc.wantApplied || resp.Failed != tc.wantFailed {
+				t.Errorf("applied %d, failed %d; want %d, %d", resp.Applied, resp.Failed, tc.wantApplied, tc.wantFailed)
+			}
+
+			if tc.check != nil {
+				tc.check(t, ts, a, b)
+			}
+		})
+	}
+}
+
+func TestTopicBulkActions(t *testing.T) {
+
+	ownerID := primitive.NewObjectID()
+	viewerID := primitive.NewObjectID()
+	topicID := primitive.NewObjectID()
+	targetID := primitive.NewObjectID()
+
+	cases := []struct {
+		name    string
+		userID  primitive.ObjectID
+		run     func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error)
+		wantErr error
+		want    int64
+		check   func(t *testing.T, ts *testWordService, a, b *Word)
+	}{
+		{
+			name:   "moving named words leaves the others",
+			userID: ownerID,
+			run: func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error) {
+				return ts.MoveTopicWords(context.Background(), topicID.Hex(), userID, &MoveTopicWordsRequest{TargetTopicID: targetID.Hex(), WordIDs: []string{a.ID.Hex()}})
+			},
+			want: 1,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID]; got.TopicID != targetID || got.Version != 2 {
+					t.Errorf("word a in %s at version %d, want moved at version 2", got.TopicID.Hex(), got.Version)
+				}
+				if got := ts.words.words[b.ID].TopicID; got != topicID {
+					t.Errorf("word b moved to %s", got.Hex())
+				}
+				if len(ts.deletion.deleted) != 1 || ts.deletion.deleted[0] != a.ID {
+					t.Errorf("tombstoned %v in the source, want word a", ts.deletion.deleted)
+				}
+			},
+		},
+		{
+			name:   "moving a word of another topic moves nothing",
+			userID: ownerID,
+			run: func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error) {
+				return ts.MoveTopicWords(context.Background(), topicID.Hex(), userID, &MoveTopicWordsRequest{TargetTopicID: targetID.Hex(), WordIDs: []string{a.ID.Hex(), primitive.NewObjectID().Hex()}})
+			},
+			wantErr: helper.ErrResourceNotFound,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if got := ts.words.words[a.ID].TopicID; got != topicID {
+					t.Errorf("word a moved to %s", got.Hex())
+				}
+			},
+		},
+		{
+			name:   "a word named twice is rejected",
+			userID: ownerID,
+			run: func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error) {
+				return ts.MarkTopicWordsKnown(context.Background(), topicID.Hex(), userID, &TopicWordsRequest{WordIDs: []string{a.ID.Hex(), a.ID.Hex()}})
+			},
+			wantErr: helper.ErrResourceNotFound,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if len(ts.progress.progress) != 0 || len(ts.cards.known) != 0 {
+					t.Error("progress was saved for a rejected request")
+				}
+			},
+		},
+		{
+			name:   "viewers cannot move words",
+			userID: viewerID,
+			run: func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error) {
+				return ts.MoveTopicWords(context.Background(), topicID.Hex(), userID, &MoveTopicWordsRequest{TargetTopicID: targetID.Hex()})
+			},
+			wantErr: helper.ErrPermissionDenied,
+		},
+		{
+			name:   "viewers mark words known for themselves and their cards follow",
+			userID: viewerID,
+			run: func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error) {
+				return ts.MarkTopicWordsKnown(context.Background(), topicID.Hex(), userID, &TopicWordsRequest{})
+			},
+			want: 2,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				for _, word := range []*Word{a, b} {
+					if p := ts.progress.progress[progressID(word.ID, viewerID)]; p == nil || !p.IsTrue {
+						t.Errorf("progress of %s = %+v, want known", word.Word, p)
+					}
+					if ts.words.words[word.ID].IsTrue {
+						t.Errorf("stored word %s was marked known", word.Word)
+					}
+				}
+				if _, ok := ts.progress.progress[progressID(a.ID, ownerID)]; ok {
+					t.Error("the owner's progress was changed")
+				}
+				if len(ts.cards.known) != 2 {
+					t.Errorf("marked cards of %d words known, want 2", len(ts.cards.known))
+				}
+			},
+		},
+		{
+			name:   "resetting clears only the user's progress and cards",
+			userID: viewerID,
+			run: func(ts *testWordService, userID string, a, b *Word) (*BulkActionResponse, error) {
+				ts.progress.progress[progressID(a.ID, viewerID)] = &WordProgress{ID: progressID(a.ID, viewerID), WordID: a.ID, UserID: viewerID, IsTrue: true, ReviewCount: 4, CorrectCount: 3}
+				ts.progress.progress[progressID(a.ID, ownerID)] = &WordProgress{ID: progressID(a.ID, ownerID), WordID: a.ID, UserID: ownerID, IsTrue: true, ReviewCount: 2}
+				return ts.ResetTopicProgress(context.Background(), topicID.Hex(), userID, &TopicWordsRequest{WordIDs: []string{a.ID.Hex()}})
+			},
+			want: 1,
+			check: func(t *testing.T, ts *testWordService, a, b *Word) {
+				if p := ts.progress.progress[progressID(a.ID, viewerID)]; p.IsTrue || p.ReviewCount != 0 || p.CorrectCount != 0 {
+					t.Errorf("viewer progress = %+v, want reset", p)
+				}
+				if p := ts.progress.progress[progressID(a.ID, ownerID)]; p.ReviewCount != 2 {
+					t.Errorf("owner progress = %+v, want untouched", p)
+				}
+				if len(ts.cards.reset) != 1 || ts.cards.reset[0] != a.ID {
+					t.Errorf("reset cards of %v, want word a", ts.cards.reset)
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			a := testWord(topicID, ownerID, "alpha")
+			b := testWord(topicID, ownerID, "beta")
+			ts := newTestWordService(a, b)
+			ts.access.grant(topicID, ownerID, "owner")
+			ts.access.grant(targetID, ownerID, "owner")
+			ts.access.grant(topicID, viewerID, "viewer")
+
+			resp, err := tc.run(ts, tc.userID.Hex(), a, b)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if resp.Updated != tc.want {
+				t.Errorf("updated %d, want %d", resp.Updated, tc.want)
+			}
+
+			if tc.check != nil {
+				tc.check(t, ts, a, b)
+			}
+		})
+	}
+}
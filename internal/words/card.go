@@ -0,0 +1,190 @@
+package words
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CurrentSchemaVersion is bumped whenever the stored word layout changes.
+// Version 0 words only have the flat definition/example/word_type fields;
+// version 1 adds senses and a part-of-speech enum.
+const CurrentSchemaVersion = 1
+
+const (
+	PartOfSpeechNoun         = "noun"
+	PartOfSpeechVerb         = "verb"
+	PartOfSpeechAdjective    = "adjective"
+	PartOfSpeechAdverb       = "adverb"
+	PartOfSpeechPronoun      = "pronoun"
+	PartOfSpeechPreposition  = "preposition"
+	PartOfSpeechConjunction  = "conjunction"
+	PartOfSpeechInterjection = "interjection"
+	PartOfSpeechDeterminer   = "determiner"
+	PartOfSpeechPhrase       = "phrase"
+	PartOfSpeechOther        = "other"
+)
+
+var partOfSpeechAliases = map[string]string{
+	"noun": PartOfSpeechNoun, "n": PartOfSpeechNoun,
+	"verb": PartOfSpeechVerb, "v": PartOfSpeechVerb,
+	"adjective": PartOfSpeechAdjective, "adj": PartOfSpeechAdjective,
+	"adverb": PartOfSpeechAdverb, "adv": PartOfSpeechAdverb,
+	"pronoun": PartOfSpeechPronoun, "pron": PartOfSpeechPronoun,
+	"preposition": PartOfSpeechPreposition, "prep": PartOfSpeechPreposition,
+	"conjunction": PartOfSpeechConjunction, "conj": PartOfSpeechConjunction,
+	"interjection": PartOfSpeechInterjection, "interj": PartOfSpeechInterjection, "intj": PartOfSpeechInterjection, "exclamation": PartOfSpeechInterjection,
+	"determiner": PartOfSpeechDeterminer, "det": PartOfSpeechDeterminer,
+	"phrase": PartOfSpeechPhrase, "idiom": PartOfSpeechPhrase,
+	"other": PartOfSpeechOther,
+}
+
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// ParsePartOfSpeech maps common spellings and abbreviations onto the enum.
+func ParsePartOfSpeech(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(value), "."))
+	partOfSpeech, ok := partOfSpeechAliases[value]
+	return partOfSpeech, ok
+}
+
+func normalizeLanguageCode(code string) (string, error) {
+
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	if code == "" {
+		return "", nil
+	}
+
+	if !languageCodePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid language code %q", code)
+	}
+
+	return code, nil
+}
+
+// Upgrade migrates a word read from an older schema in place and reports
+// whether anything changed. The legacy fields are kept so older clients can
+// still read the word.
+func (w *Word) Upgrade() bool {
+
+	if w.SchemaVersion >= CurrentSchemaVersion {
+		return false
+	}
+
+	if len(w.Senses) == 0 {
+		w.Senses = legacySenses(w.Definition, w.Example)
+	}
+
+	if w.PartOfSpeech == "" {
+		w.PartOfSpeech, _ = ParsePartOfSpeech(w.WordType)
+	}
+
+	if w.SourceSnapshot != nil {
+		w.SourceSnapshot.upgrade()
+	}
+
+	w.SchemaVersion = CurrentSchemaVersion
+	return true
+}
+
+func (c *WordContent) upgrade() {
+
+	if len(c.Senses) == 0 {
+		c.Senses = legacySenses(c.Definition, c.Example)
+	}
+
+	if c.PartOfSpeech == "" {
+		c.PartOfSpeech, _ = ParsePartOfSpeech(c.WordType)
+	}
+}
+
+func legacySenses(definition string, example *string) []Sense {
+
+	if definition == "" {
+		return nil
+	}
+
+	sense := Sense{Definition: definition, Examples: []string{}}
+	if value := strings.TrimSpace(stringValue(example)); value != "" {
+		sense.Examples = append(sense.Examples, value)
+	}
+
+	return []Sense{sense}
+}
+
+// syncLegacyFields mirrors the primary sense into definition/example, which
+// older clients still read.
+func (w *Word) syncLegacyFields() {
+
+	if len(w.Senses) == 0 {
+		return
+	}
+
+	w.Definition = w.Senses[0].Definition
+	w.Example = nil
+	if len(w.Senses[0].Examples) > 0 {
+		example := w.Senses[0].Examples[0]
+		w.Example = &example
+	}
+
+	if w.WordType == "" {
+		w.WordType = w.PartOfSpeech
+	}
+}
+
+// applyLegacyFields copies a definition/example edit made by an older client
+// into the primary sense.
+func (w *Word) applyLegacyFields() {
+
+	if len(w.Senses) == 0 {
+		w.Senses = legacySenses(w.Definition, w.Example)
+		return
+	}
+
+	primary := Sense{Definition: w.Definition, Examples: []string{}}
+	if example := strings.TrimSpace(stringValue(w.Example)); example != "" {
+		primary.Examples = append(primary.Examples, example)
+	}
+	if len(w.Senses[0].Examples) > 1 {
+		primary.Examples = append(primary.Examples, w.Senses[0].Examples[1:]...)
+	}
+
+	senses := append([]Sense{primary}, w.Senses[1:]...)
+	w.Senses = senses
+}
+
+func cleanSenses(senses []Sense) []Sense {
+
+	cleaned := make([]Sense, 0, len(senses))
+	for _, sense := range senses {
+		definition := strings.TrimSpace(sense.Definition)
+		if definition == "" {
+			continue
+		}
+		cleaned = append(cleaned, Sense{
+			Definition: definition,
+			Examples:   cleanList(sense.Examples),
+		})
+	}
+
+	return cleaned
+}
+
+func cleanList(values []string) []string {
+
+	seen := make(map[string]struct{}, len(values))
+	cleaned := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		cleaned = append(cleaned, value)
+	}
+
+	return cleaned
+}
@@ -31,7 +31,7 @@ func (h *WordHandler) CreateWord(c *gin.Context) {
 
 	err := h.WordService.CreateWord(c, &req, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -40,6 +40,12 @@ func (h *WordHandler) CreateWord(c *gin.Context) {
 
 func (h *WordHandler) GetAllWords(c *gin.Context) {
 	
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	var req SearchWordRequest
 	
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -47,9 +53,9 @@ func (h *WordHandler) GetAllWords(c *gin.Context) {
 		return
 	}
 
-	words, err := h.WordService.GetAllWords(c, &req)
+	words, err := h.WordService.SearchWords(c, userID.(string), &req)
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -59,11 +65,21 @@ func (h *WordHandler) GetAllWords(c *gin.Context) {
 
 func (h *WordHandler) GetWordByID(c *gin.Context) {
 
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	id := c.Param("word_id")
 
-	word, err := h.WordService.GetWordByID(c, id)
+	word, err := h.WordService.GetWordByID(c, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	if helper.NotModified(c, word.Version) {
 		return
 	}
 
@@ -71,8 +87,50 @@ func (h *WordHandler) GetWordByID(c *gin.Context) {
 
 }
 
+func (h *WordHandler) RenderWord(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	cards, err := h.WordService.RenderWord(c, c.Param("word_id"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", cards)
+
+}
+
+func (h *WordHandler) SuggestTranslations(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	suggestion, err := h.WordService.SuggestTranslations(c, c.Param("word_id"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", suggestion)
+
+}
+
 func (h *WordHandler) GetAllWordsByTopicID(c *gin.Context) {
 
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	var req SearchWordRequest
 	
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -82,9 +140,9 @@ func (h *WordHandler) GetAllWordsByTopicID(c *gin.Context) {
 
 	id := c.Param("topic_id")
 
-	words, err := h.WordService.GetWordsByTopicID(c, id, &req)
+	words, err := h.WordService.GetTopicWords(c, id, userID.(string), &req)
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -94,6 +152,12 @@ func (h *WordHandler) GetAllWordsByTopicID(c *gin.Context) {
 
 func (h *WordHandler) UpdateWord(c *gin.Context) {
 
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	id := c.Param("word_id")
 
 	var req UpdateWordRequest
@@ -102,26 +166,298 @@ func (h *WordHandler) UpdateWord(c *gin.Context) {
 		return
 	}
 
-	err := h.WordService.UpdateWord(c, id, &req)
+	expectedVersion, err := helper.ParseIfMatch(c)
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
 		return
 	}
+	req.ExpectedVersion = expectedVersion
 
-	helper.SendSuccess(c, http.StatusOK, "success", nil)
+	word, err := h.WordService.UpdateWord(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SetETag(c, word.Version)
+	helper.SendSuccess(c, http.StatusOK, "success", word)
 
 }
 
 func (h *WordHandler) DeleteWord(c *gin.Context) {
 
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
 	id := c.Param("word_id")
 
-	err := h.WordService.DeleteWord(c, id)
+	err := h.WordService.DeleteWord(c, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
 	helper.SendSuccess(c, http.StatusOK, "success", nil)
 	
-}
\ No newline at end of file
+}
+func (h *WordHandler) BatchWords(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req BatchWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.WordService.BatchWords(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+
+}
+
+func (h *WordHandler) MoveWords(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req TransferWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.WordService.MoveWords(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+
+}
+
+func (h *WordHandler) CopyWords(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req TransferWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.WordService.CopyWords(c, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+
+}
+
+func (h *WordHandler) MoveTopicWords(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req MoveTopicWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.WordService.MoveTopicWords(c, c.Param("topic_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+
+}
+
+func (h *WordHandler) ResetTopicProgress(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req TopicWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.WordService.ResetTopicProgress(c, c.Param("topic_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+
+}
+
+func (h *WordHandler) MarkTopicWordsKnown(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req TopicWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	result, err := h.WordService.MarkTopicWordsKnown(c, c.Param("topic_id"), userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", result)
+
+}
+
+func (h *WordHandler) ReviewWord(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req ReviewWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("word_id")
+
+	err := h.WordService.ReviewWord(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
+
+// UploadAudio takes the raw recording as the request body; the Content-Type
+// header must be an audio media type.
+func (h *WordHandler) UploadAudio(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("word_id")
+
+	audio, err := h.WordService.UploadAudio(c, id, userID.(string), c.ContentType(), c.Request.Body)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", audio)
+
+}
+
+func (h *WordHandler) GetAudio(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("word_id")
+
+	reader, audio, err := h.WordService.OpenAudio(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, audio.Size, audio.ContentType, reader, nil)
+
+}
+
+func (h *WordHandler) GenerateSpeech(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	var req GenerateSpeechRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	id := c.Param("word_id")
+
+	speech, err := h.WordService.GenerateSpeech(c, id, userID.(string), &req)
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", speech)
+
+}
+
+func (h *WordHandler) DeleteAudio(c *gin.Context) {
+
+	userID, ok := c.Get("user_id")
+	if !ok {
+		helper.SendError(c, http.StatusUnauthorized, nil, helper.ErrInvalidOperation)
+		return
+	}
+
+	id := c.Param("word_id")
+
+	err := h.WordService.DeleteAudio(c, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "success", nil)
+
+}
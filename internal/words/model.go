@@ -7,14 +7,117 @@ import (
 )
 
 type Word struct {
-	ID         primitive.ObjectID `json:"id" bson:"_id"`
-	TopicID    primitive.ObjectID `json:"topic_id" bson:"topic_id"`
-	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Word       string             `json:"word" bson:"word"`
-	Definition string             `json:"definition" bson:"definition"`
-	Example    *string            `json:"example" bson:"example"`
-	WordType   string             `json:"word_type" bson:"word_type"`
-	IsTrue     bool               `json:"is_true" bson:"is_true"`
-	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+	ID             primitive.ObjectID  `json:"id" bson:"_id"`
+	TopicID        primitive.ObjectID  `json:"topic_id" bson:"topic_id"`
+	UserID         primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	Word           string              `json:"word" bson:"word"`
+	Definition     string              `json:"definition" bson:"definition"`
+	Example        *string             `json:"example" bson:"example"`
+	WordType       string              `json:"word_type" bson:"word_type"`
+	IsTrue         bool                `json:"is_true" bson:"is_true"`
+	Tags           []string            `json:"tags" bson:"tags"`
+	SourceWordID   *primitive.ObjectID `json:"source_word_id,omitempty" bson:"source_word_id,omitempty"`
+	SourceSnapshot *WordContent        `json:"-" bson:"source_snapshot,omitempty"`
+	ReviewCount    int                 `json:"review_count" bson:"review_count"`
+	CorrectCount   int                 `json:"correct_count" bson:"correct_count"`
+	LastReviewedAt *time.Time          `json:"last_reviewed_at,omitempty" bson:"last_reviewed_at,omitempty"`
+	Pronunciation  string              `json:"pronunciation" bson:"pronunciation"`
+	PartOfSpeech   string              `json:"part_of_speech" bson:"part_of_speech"`
+	Senses         []Sense             `json:"senses" bson:"senses"`
+	Synonyms       []string            `json:"synonyms" bson:"synonyms"`
+	Antonyms       []string            `json:"antonyms" bson:"antonyms"`
+	SourceLanguage string              `json:"source_language" bson:"source_language"`
+	TargetLanguage string              `json:"target_language" bson:"target_language"`
+	Audio          *AudioAttachment    `json:"audio,omitempty" bson:"audio,omitempty"`
+	Speech         *WordSpeech         `json:"speech,omitempty" bson:"speech,omitempty"`
+	Context        *WordContext        `json:"context,omitempty" bson:"context,omitempty"`
+	NoteTypeID     *primitive.ObjectID `json:"note_type_id,omitempty" bson:"note_type_id,omitempty"`
+	Fields         map[string]string   `json:"fields,omitempty" bson:"fields,omitempty"`
+	SchemaVersion  int                 `json:"schema_version" bson:"schema_version"`
+	Version        int64               `json:"version" bson:"version"`
+	CreatedAt      time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+// WordProgress is one user's review history of a word, kept apart from the
+// word so that everyone who can see a shared topic studies it on their own.
+// Words carry the same fields: stored, they hold the progress recorded before
+// it was kept per user, which belongs to the word's creator; returned, they
+// hold the progress of the user asking.
+type WordProgress struct {
+	ID             string             `json:"-" bson:"_id"`
+	WordID         primitive.ObjectID `json:"word_id" bson:"word_id"`
+	UserID         primitive.ObjectID `json:"-" bson:"user_id"`
+	IsTrue         bool               `json:"is_true" bson:"is_true"`
+	ReviewCount    int                `json:"review_count" bson:"review_count"`
+	CorrectCount   int                `json:"correct_count" bson:"correct_count"`
+	LastReviewedAt *time.Time         `json:"last_reviewed_at,omitempty" bson:"last_reviewed_at,omitempty"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// WordContent is the part of a word that is copied from an upstream deck and
+// compared when syncing a clone; progress fields are never part of it.
+type WordContent struct {
+	Word          string   `json:"word" bson:"word"`
+	Definition    string   `json:"definition" bson:"definition"`
+	Example       *string  `json:"example" bson:"example"`
+	WordType      string   `json:"word_type" bson:"word_type"`
+	Pronunciation string   `json:"pronunciation,omitempty" bson:"pronunciation,omitempty"`
+	PartOfSpeech  string   `json:"part_of_speech,omitempty" bson:"part_of_speech,omitempty"`
+	Senses        []Sense  `json:"senses,omitempty" bson:"senses,omitempty"`
+	Synonyms      []string `json:"synonyms,omitempty" bson:"synonyms,omitempty"`
+	Antonyms      []string `json:"antonyms,omitempty" bson:"antonyms,omitempty"`
+
+	Fields map[string]string `json:"fields,omitempty" bson:"fields,omitempty"`
+}
+
+type Sense struct {
+	Definition string   `json:"definition" bson:"definition"`
+	Examples   []string `json:"examples" bson:"examples"`
+}
+
+// AudioAttachment points at a recording in the blob store. Clones share the
+// key of their source, so a blob is only deleted once no word references it.
+type AudioAttachment struct {
+	Key         string    `json:"-" bson:"key"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	UploadedAt  time.Time `json:"uploaded_at" bson:"uploaded_at"`
+}
+
+// WordContext records where a word was mined from: the source file and a
+// subtitle timestamp or book chapter within it.
+type WordContext struct {
+	Source    string `json:"source" bson:"source"`
+	Reference string `json:"reference,omitempty" bson:"reference,omitempty"`
+}
+
+// WordSpeech points at synthesized audio of a word and its example. The
+// clips are shared through the speech cache and are never deleted with the
+// word.
+type WordSpeech struct {
+	Word    *SpeechAudio `json:"word,omitempty" bson:"word,omitempty"`
+	Example *SpeechAudio `json:"example,omitempty" bson:"example,omitempty"`
+}
+
+type SpeechAudio struct {
+	Text     string `json:"-" bson:"text"`
+	URL      string `json:"url" bson:"url"`
+	Language string `json:"language" bson:"language"`
+	Voice    string `json:"voice" bson:"voice"`
+}
+
+func (w *Word) Content() WordContent {
+	return WordContent{
+		Word:          w.Word,
+		Definition:    w.Definition,
+		Example:       w.Example,
+		WordType:      w.WordType,
+		Pronunciation: w.Pronunciation,
+		PartOfSpeech:  w.PartOfSpeech,
+		Senses:        w.Senses,
+		Synonyms:      w.Synonyms,
+		Antonyms:      w.Antonyms,
+		Fields:        w.Fields,
+	}
 }
@@ -0,0 +1,116 @@
+package words
+
+import (
+	"context"
+
+	"flashcard/internal/notetypes"
+)
+
+// FieldValues returns the values a word's templates are rendered with.
+// Words without a note type expose their fixed columns under the field names
+// of the default note type.
+func (w *Word) FieldValues() map[string]string {
+
+	if w.NoteTypeID != nil {
+		values := make(map[string]string, len(w.Fields))
+		for name, value := range w.Fields {
+			values[name] = value
+		}
+		return values
+	}
+
+	return map[string]string{
+		"Word":          w.Word,
+		"Definition":    w.Definition,
+		"Example":       stringValue(w.Example),
+		"Pronunciation": w.Pronunciation,
+		"PartOfSpeech":  w.PartOfSpeech,
+	}
+}
+
+// setNoteType switches a word to the note type with the given id; an empty
+// id or "default" returns it to the fixed columns. Values of fields the old
+// and new layouts share are carried over, and the first two fields fall back
+// to the word and its definition.
+func (s *wordService) setNoteType(c context.Context, word *Word, id string, userID string) error {
+
+	if id == "" || id == notetypes.DefaultNoteTypeID {
+		word.NoteTypeID = nil
+		word.Fields = nil
+		return nil
+	}
+
+	noteType, err := s.noteTypes.GetNoteType(c, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if word.NoteTypeID != nil && *word.NoteTypeID == noteType.ID {
+		return nil
+	}
+
+	previous := word.FieldValues()
+	fields := make(map[string]string, len(noteType.Fields))
+	for _, field := range noteType.Fields {
+		if value, ok := previous[field.Name]; ok {
+			fields[field.Name] = value
+		}
+	}
+	fillColumnFields(fields, noteType, word, false)
+
+	noteTypeID := noteType.ID
+	word.NoteTypeID = &noteTypeID
+	word.Fields = fields
+
+	return nil
+}
+
+// applyNoteFields validates a word's field values against its note type and
+// mirrors the first field into the word column and the second into the
+// definition, so lists, search and review keep working for any layout.
+// When columnsEdited is set, an edit made through the fixed columns is
+// written back into those fields first.
+func (s *wordService) applyNoteFields(c context.Context, word *Word, columnsEdited bool) error {
+
+	if word.NoteTypeID == nil {
+		word.Fields = nil
+		return nil
+	}
+
+	noteType, err := s.noteTypes.ResolveNoteType(c, word.NoteTypeID)
+	if err != nil {
+		return err
+	}
+
+	fields := word.FieldValues()
+	fillColumnFields(fields, noteType, word, columnsEdited)
+
+	values, err := noteType.ValidateFields(fields)
+	if err != nil {
+		return err
+	}
+
+	word.Fields = values
+	word.Word = values[noteType.Fields[0].Name]
+	word.Definition = ""
+	if len(noteType.Fields) > 1 {
+		word.Definition = values[noteType.Fields[1].Name]
+	}
+	word.Senses = legacySenses(word.Definition, word.Example)
+
+	return nil
+}
+
+func fillColumnFields(fields map[string]string, noteType *notetypes.NoteType, word *Word, overwrite bool) {
+
+	columns := []string{word.Word, word.Definition}
+	for i, value := range columns {
+		if i >= len(noteType.Fields) || value == "" {
+			continue
+		}
+		name := noteType.Fields[i].Name
+		if overwrite || fields[name] == "" {
+			fields[name] = value
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package words
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// startingProgress is a user's progress on the word before they have a
+// progress document of their own: what the word stores for its creator, and
+// nothing for anyone else.
+func (w *Word) startingProgress(userID primitive.ObjectID) *WordProgress {
+
+	progress := &WordProgress{ID: progressID(w.ID, userID), WordID: w.ID, UserID: userID}
+	if userID == w.UserID {
+		progress.IsTrue = w.IsTrue
+		progress.ReviewCount = w.ReviewCount
+		progress.CorrectCount = w.CorrectCount
+		progress.LastReviewedAt = w.LastReviewedAt
+	}
+
+	return progress
+}
+
+func (w *Word) applyProgress(progress *WordProgress) {
+	w.IsTrue = progress.IsTrue
+	w.ReviewCount = progress.ReviewCount
+	w.CorrectCount = progress.CorrectCount
+	w.LastReviewedAt = progress.LastReviewedAt
+}
+
+// progressOf returns the user's progress on each of the words, by word ID.
+func (s *wordService) progressOf(c context.Context, userID primitive.ObjectID, words []*Word) (map[primitive.ObjectID]*WordProgress, error) {
+
+	stored, err := s.progressRepository.GetProgress(c, userID, wordIDs(words))
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[primitive.ObjectID]*WordProgress, len(words))
+	for _, p := range stored {
+		progress[p.WordID] = p
+	}
+	for _, word := range words {
+		if _, ok := progress[word.ID]; !ok {
+			progress[word.ID] = word.startingProgress(userID)
+		}
+	}
+
+	return progress, nil
+}
+
+// withProgress fills in the user's progress on words about to be returned.
+// Words must not be written back afterwards.
+func (s *wordService) withProgress(c context.Context, userID primitive.ObjectID, words ...*Word) error {
+
+	progress, err := s.progressOf(c, userID, words)
+	if err != nil {
+		return err
+	}
+
+	for _, word := range words {
+		word.applyProgress(progress[word.ID])
+	}
+
+	return nil
+}
+
+// saveProgress changes the user's progress on each word with update and
+// stores it.
+func (s *wordService) saveProgress(c context.Context, userID primitive.ObjectID, words []*Word, update func(*WordProgress)) error {
+
+	progress, err := s.progressOf(c, userID, words)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	saved := make([]*WordProgress, 0, len(progress))
+	for _, p := range progress {
+		update(p)
+		p.UpdatedAt = now
+		saved = append(saved, p)
+	}
+
+	return s.progressRepository.SaveProgress(c, saved)
+}
+
+func resetWordProgress(progress *WordProgress) {
+	progress.IsTrue = false
+	progress.ReviewCount = 0
+	progress.CorrectCount = 0
+	progress.LastReviewedAt = nil
+}
+
+// filterKnown keeps the words whose progress matches isTrue, or all of them
+// when it is nil.
+func filterKnown(words []*Word, isTrue *bool) []*Word {
+
+	if isTrue == nil {
+		return words
+	}
+
+	kept := make([]*Word, 0, len(words))
+	for _, word := range words {
+		if word.IsTrue == *isTrue {
+			kept = append(kept, word)
+		}
+	}
+
+	return kept
+}
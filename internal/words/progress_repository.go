@@ -0,0 +1,161 @@
+package words
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProgressRepository stores each user's progress on words. Documents are
+// keyed by word and user, so a user has at most one per word.
+type ProgressRepository interface {
+	GetProgress(c context.Context, userID primitive.ObjectID, wordIDs []primitive.ObjectID) ([]*WordProgress, error)
+	GetProgressUpdatedSince(c context.Context, userID primitive.ObjectID, since time.Time) ([]*WordProgress, error)
+	GetStudiedWordIDs(c context.Context, wordIDs []primitive.ObjectID) ([]primitive.ObjectID, error)
+	RecordReview(c context.Context, word *Word, userID primitive.ObjectID, correct bool, reviewedAt time.Time) error
+	SaveProgress(c context.Context, progress []*WordProgress) error
+	DeleteProgressByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error
+	DeleteProgressByUserID(c context.Context, userID primitive.ObjectID) error
+}
+
+type progressRepository struct {
+	collection *mongo.Collection
+}
+
+func NewProgressRepository(collection *mongo.Collection) ProgressRepository {
+	return &progressRepository{collection: collection}
+}
+
+func progressID(wordID, userID primitive.ObjectID) string {
+	return wordID.Hex() + ":" + userID.Hex()
+}
+
+func (r *progressRepository) GetProgress(c context.Context, userID primitive.ObjectID, wordIDs []primitive.ObjectID) ([]*WordProgress, error) {
+
+	if len(wordIDs) == 0 {
+		return nil, nil
+	}
+
+	return r.find(c, bson.M{"user_id": userID, "word_id": bson.M{"$in": wordIDs}})
+}
+
+func (r *progressRepository) GetProgressUpdatedSince(c context.Context, userID primitive.ObjectID, since time.Time) ([]*WordProgress, error) {
+	return r.find(c, bson.M{"user_id": userID, "updated_at": bson.M{"$gt": since}})
+}
+
+// GetStudiedWordIDs returns which of the words anyone has reviewed.
+func (r *progressRepository) GetStudiedWordIDs(c context.Context, wordIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+
+	if len(wordIDs) == 0 {
+		return nil, nil
+	}
+
+	values, err := r.collection.Distinct(c, "word_id", bson.M{"word_id": bson.M{"$in": wordIDs}, "review_count": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// RecordReview counts a review of the word by the user. Their first review
+// starts from the word's startingProgress.
+func (r *progressRepository) RecordReview(c context.Context, word *Word, userID primitive.ObjectID, correct bool, reviewedAt time.Time) error {
+
+	start := word.startingProgress(userID)
+	_, err := r.collection.InsertOne(c, start)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	inc := bson.M{"review_count": 1}
+	if correct {
+		inc["correct_count"] = 1
+	}
+
+	update := bson.M{
+		"$inc": inc,
+		"$set": bson.M{"is_true": correct, "last_reviewed_at": reviewedAt, "updated_at": reviewedAt},
+	}
+
+	_, err = r.collection.UpdateOne(c, bson.M{"_id": start.ID}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveProgress replaces the given progress documents, creating missing ones.
+func (r *progressRepository) SaveProgress(c context.Context, progress []*WordProgress) error {
+
+	if len(progress) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(progress))
+	for _, p := range progress {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": p.ID}).
+			SetReplacement(p).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(c, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *progressRepository) DeleteProgressByWordIDs(c context.Context, wordIDs []primitive.ObjectID) error {
+
+	if len(wordIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(c, bson.M{"word_id": bson.M{"$in": wordIDs}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *progressRepository) DeleteProgressByUserID(c context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(c, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *progressRepository) find(c context.Context, filter bson.M) ([]*WordProgress, error) {
+
+	var progress []*WordProgress
+
+	cursor, err := r.collection.Find(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var p WordProgress
+		if err := cursor.Decode(&p); err != nil {
+			return nil, err
+		}
+		progress = append(progress, &p)
+	}
+
+	return progress, nil
+}
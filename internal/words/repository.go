@@ -2,19 +2,64 @@ package words
 
 import (
 	"context"
+	"errors"
+	"flashcard/helper"
+	"fmt"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// WordWrite is one operation of a bulk write; exactly one of Insert, Update
+// and Delete is set. Updates and deletes only apply while the stored word is
+// still at ExpectedVersion.
+type WordWrite struct {
+	Insert *Word
+	Update *WordFieldUpdate
+	Delete *WordDeletion
+}
+
+type WordFieldUpdate struct {
+	ID              primitive.ObjectID
+	ExpectedVersion int64
+	Set             bson.M
+	Unset           bson.M
+}
+
+type WordDeletion struct {
+	ID              primitive.ObjectID
+	ExpectedVersion int64
+}
+
 type WordRepository interface{
 	CreateWord(c context.Context, word *Word) error
+	CreateWords(c context.Context, words []*Word) error
 	GetAllWords(c context.Context, req *SearchWordRequest) ([]*Word, error)
+	GetWordsInTopics(c context.Context, topicIDs []primitive.ObjectID, req *SearchWordRequest) ([]*Word, error)
 	GetWordByID(c context.Context, id primitive.ObjectID) (*Word, error)
 	GetWordsByTopicID(c context.Context, id primitive.ObjectID, req *SearchWordRequest) ([]*Word, error)
-	UpdateWord(c context.Context, id primitive.ObjectID, word *Word) error
+	UpdateWordFields(c context.Context, id primitive.ObjectID, expectedVersion int64, set bson.M, unset bson.M) (bool, error)
+	BulkWriteWords(c context.Context, writes []*WordWrite) ([]error, error)
+	UpdateWordsInTopic(c context.Context, topicID primitive.ObjectID, wordIDs []primitive.ObjectID, set bson.M, unset bson.M) (int64, error)
 	DeleteWord(c context.Context, id primitive.ObjectID) error
+	DeleteWordsByIDs(c context.Context, ids []primitive.ObjectID) error
+	ReassignWords(c context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) error
+	DeleteWordsByTopicID(c context.Context, topicID primitive.ObjectID) error
+	GetWordsByUserID(c context.Context, userID primitive.ObjectID) ([]*Word, error)
+	GetWordsBelowSchemaVersion(c context.Context, version int, limit int64) ([]*Word, error)
+	SetAudio(c context.Context, id primitive.ObjectID, audio *AudioAttachment) error
+	CountWordsByAudioKey(c context.Context, key string) (int64, error)
+	SetSpeech(c context.Context, id primitive.ObjectID, speech *WordSpeech) error
+	UpgradeWordSchema(c context.Context, word *Word) error
+	CountWordsByNoteType(c context.Context, noteTypeID primitive.ObjectID) (int64, error)
+	CountWordsByTopicID(c context.Context, topicID primitive.ObjectID) (int64, error)
+	CountWordsByTopicIDs(c context.Context, topicIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error)
+	GetWordsByIDs(c context.Context, ids []primitive.ObjectID) ([]*Word, error)
+	GetWordsUpdatedSince(c context.Context, topicIDs []primitive.ObjectID, since time.Time) ([]*Word, error)
 }
 
 type wordRepository struct {
@@ -33,20 +78,38 @@ func (r *wordRepository) CreateWord(c context.Context, word *Word) error {
 	return nil
 }
 
+func (r *wordRepository) CreateWords(c context.Context, words []*Word) error {
+
+	if len(words) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(words))
+	for _, word := range words {
+		documents = append(documents, word)
+	}
+
+	_, err := r.collection.InsertMany(c, documents)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *wordRepository) GetAllWords(c context.Context, req *SearchWordRequest) ([]*Word, error) {
 
 	var words []*Word
 
 	filter := bson.M{}
-	if req.Istrue != nil {
-		filter["is_true"] = *req.Istrue
-	}
 	if req.TopicID != nil {
 		filter["topic_id"] = *req.TopicID
 	}
 	if req.Word != nil {
 		filter["word"] = *req.Word
 	}
+	if req.Tag != nil {
+		filter["tags"] = strings.ToLower(*req.Tag)
+	}
 
 	cursor, err := r.collection.Find(c, filter)
 	if err != nil {
@@ -59,6 +122,7 @@ func (r *wordRepository) GetAllWords(c context.Context, req *SearchWordRequest)
 		if err := cursor.Decode(&word); err != nil {
 			return nil, err
 		}
+		word.Upgrade()
 		words = append(words, &word)
 	}
 	
@@ -66,6 +130,42 @@ func (r *wordRepository) GetAllWords(c context.Context, req *SearchWordRequest)
 
 }
 
+// GetWordsInTopics searches the words of the given topics. The topic of the
+// request is ignored in favour of topicIDs.
+func (r *wordRepository) GetWordsInTopics(c context.Context, topicIDs []primitive.ObjectID, req *SearchWordRequest) ([]*Word, error) {
+
+	if len(topicIDs) == 0 {
+		return []*Word{}, nil
+	}
+
+	filter := bson.M{"topic_id": bson.M{"$in": topicIDs}}
+	if req.Word != nil {
+		filter["word"] = *req.Word
+	}
+	if req.Tag != nil {
+		filter["tags"] = strings.ToLower(*req.Tag)
+	}
+
+	words := []*Word{}
+
+	cursor, err := r.collection.Find(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var word Word
+		if err := cursor.Decode(&word); err != nil {
+			return nil, err
+		}
+		word.Upgrade()
+		words = append(words, &word)
+	}
+
+	return words, nil
+}
+
 func (r *wordRepository) GetWordByID(c context.Context, id primitive.ObjectID) (*Word, error) {
 	
 	filter := bson.M{"_id": id}
@@ -79,11 +179,39 @@ func (r *wordRepository) GetWordByID(c context.Context, id primitive.ObjectID) (
 		}
 		return nil, err
 	}
+
+	word.Upgrade()
 	
 	return &word, nil
 
 }
 
+func (r *wordRepository) GetWordsByIDs(c context.Context, ids []primitive.ObjectID) ([]*Word, error) {
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var words []*Word
+
+	cursor, err := r.collection.Find(c, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var word Word
+		if err := cursor.Decode(&word); err != nil {
+			return nil, err
+		}
+		word.Upgrade()
+		words = append(words, &word)
+	}
+
+	return words, nil
+}
+
 func (r *wordRepository) GetWordsByTopicID(c context.Context, id primitive.ObjectID, req *SearchWordRequest) ([]*Word, error) {
 	
 	filter := bson.M{"topic_id": id}
@@ -91,10 +219,10 @@ func (r *wordRepository) GetWordsByTopicID(c context.Context, id primitive.Objec
 	if req.Word != nil {
 		filter["word"] = *req.Word
 	}
-
-	if req.Istrue != nil {
-		filter["is_true"] = *req.Istrue
+	if req.Tag != nil {
+		filter["tags"] = strings.ToLower(*req.Tag)
 	}
+
 	
 	var words []*Word
 	
@@ -109,6 +237,7 @@ func (r *wordRepository) GetWordsByTopicID(c context.Context, id primitive.Objec
 		if err := cursor.Decode(&word); err != nil {
 			return nil, err
 		}
+		word.Upgrade()
 		words = append(words, &word)
 	}
 	
@@ -116,16 +245,144 @@ func (r *wordRepository) GetWordsByTopicID(c context.Context, id primitive.Objec
 
 }
 
-func (r *wordRepository) UpdateWord(c context.Context, id primitive.ObjectID, word *Word) error {
-	filter := bson.M{"_id": id}
-	update := bson.M{"$set": word}
-	_, err := r.collection.UpdateOne(c, filter, update)
+// UpdateWordFields applies a field-level update only if the stored word is
+// still at expectedVersion, and reports whether it was.
+func (r *wordRepository) UpdateWordFields(c context.Context, id primitive.ObjectID, expectedVersion int64, set bson.M, unset bson.M) (bool, error) {
+
+	result, err := r.collection.UpdateOne(c, helper.VersionFilter(id, expectedVersion), helper.VersionedUpdate(set, unset))
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// BulkWriteWords runs the writes as one unordered bulk write and returns an
+// error per write, nil for the ones that were applied. A conditional write
+// that matched nothing is reported as a precondition failure.
+func (r *wordRepository) BulkWriteWords(c context.Context, writes []*WordWrite) ([]error, error) {
+
+	results := make([]error, len(writes))
+	if len(writes) == 0 {
+		return results, nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(writes))
+	var conditional int64
+	for _, write := range writes {
+		switch {
+		case write.Insert != nil:
+			models = append(models, mongo.NewInsertOneModel().SetDocument(write.Insert))
+		case write.Update != nil:
+			conditional++
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(helper.VersionFilter(write.Update.ID, write.Update.ExpectedVersion)).
+				SetUpdate(helper.VersionedUpdate(write.Update.Set, write.Update.Unset)))
+		case write.Delete != nil:
+			conditional++
+			models = append(models, mongo.NewDeleteOneModel().
+				SetFilter(helper.VersionFilter(write.Delete.ID, write.Delete.ExpectedVersion)))
+		}
+	}
+
+	result, err := r.collection.BulkWrite(c, models, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			results[writeErr.Index] = writeErr
+			if writes[writeErr.Index].Insert == nil {
+				conditional--
+			}
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if result == nil || result.MatchedCount+result.DeletedCount >= conditional {
+		return results, nil
+	}
+
+	return results, r.markUnappliedWrites(c, writes, results)
+}
+
+// markUnappliedWrites finds which conditional writes of a bulk write did not
+// match, by checking the versions the words are now stored at.
+func (r *wordRepository) markUnappliedWrites(c context.Context, writes []*WordWrite, results []error) error {
+
+	ids := make([]primitive.ObjectID, 0, len(writes))
+	for i, write := range writes {
+		if results[i] != nil {
+			continue
+		}
+		if write.Update != nil {
+			ids = append(ids, write.Update.ID)
+		}
+		if write.Delete != nil {
+			ids = append(ids, write.Delete.ID)
+		}
+	}
+
+	cursor, err := r.collection.Find(c, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"version": 1}))
 	if err != nil {
 		return err
 	}
+	defer cursor.Close(c)
+
+	versions := make(map[primitive.ObjectID]int64, len(ids))
+	for cursor.Next(c) {
+		var stored struct {
+			ID      primitive.ObjectID `bson:"_id"`
+			Version int64              `bson:"version"`
+		}
+		if err := cursor.Decode(&stored); err != nil {
+			return err
+		}
+		versions[stored.ID] = stored.Version
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	for i, write := range writes {
+		if results[i] != nil {
+			continue
+		}
+		if write.Update != nil {
+			if version, ok := versions[write.Update.ID]; !ok || version != write.Update.ExpectedVersion+1 {
+				results[i] = fmt.Errorf("%w: word was modified concurrently", helper.ErrPreconditionFailed)
+			}
+		}
+		if write.Delete != nil {
+			if _, ok := versions[write.Delete.ID]; ok {
+				results[i] = fmt.Errorf("%w: word was modified concurrently", helper.ErrPreconditionFailed)
+			}
+		}
+	}
+
 	return nil
 }
 
+// UpdateWordsInTopic applies the same update to the given words of a topic
+// and bumps each word's version.
+func (r *wordRepository) UpdateWordsInTopic(c context.Context, topicID primitive.ObjectID, wordIDs []primitive.ObjectID, set bson.M, unset bson.M) (int64, error) {
+
+	if len(wordIDs) == 0 {
+		return 0, nil
+	}
+
+	filter := bson.M{"topic_id": topicID, "_id": bson.M{"$in": wordIDs}}
+
+	update := helper.VersionedUpdate(set, unset)
+	update["$inc"] = bson.M{"version": 1}
+
+	result, err := r.collection.UpdateMany(c, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
 func (r *wordRepository) DeleteWord(c context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 	_, err := r.collection.DeleteOne(c, filter)
@@ -133,4 +390,243 @@ func (r *wordRepository) DeleteWord(c context.Context, id primitive.ObjectID) er
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *wordRepository) DeleteWordsByIDs(c context.Context, ids []primitive.ObjectID) error {
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(c, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReassignWords makes userID the author of the given words and bumps their
+// versions.
+func (r *wordRepository) ReassignWords(c context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) error {
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// The progress stored on a word is its creator's, so it does not pass to
+	// the new owner.
+	update := bson.M{
+		"$set":   bson.M{"user_id": userID, "is_true": false, "review_count": 0, "correct_count": 0, "updated_at": time.Now()},
+		"$unset": bson.M{"last_reviewed_at": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	_, err := r.collection.UpdateMany(c, bson.M{"_id": bson.M{"$in": ids}}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wordRepository) DeleteWordsByTopicID(c context.Context, topicID primitive.ObjectID) error {
+	filter := bson.M{"topic_id": topicID}
+	_, err := r.collection.DeleteMany(c, filter)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wordRepository) GetWordsByUserID(c context.Context, userID primitive.ObjectID) ([]*Word, error) {
+
+	var words []*Word
+
+	cursor, err := r.collection.Find(c, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var word Word
+		if err := cursor.Decode(&word); err != nil {
+			return nil, err
+		}
+		word.Upgrade()
+		words = append(words, &word)
+	}
+
+	return words, nil
+}
+
+// GetWordsBelowSchemaVersion returns words as stored, without upgrading
+// them, so the migration can tell which ones still need to be rewritten.
+func (r *wordRepository) GetWordsBelowSchemaVersion(c context.Context, version int, limit int64) ([]*Word, error) {
+
+	filter := bson.M{"schema_version": bson.M{"$not": bson.M{"$gte": version}}}
+
+	var words []*Word
+
+	cursor, err := r.collection.Find(c, filter, options.Find().SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var word Word
+		if err := cursor.Decode(&word); err != nil {
+			return nil, err
+		}
+		words = append(words, &word)
+	}
+
+	return words, nil
+}
+
+func (r *wordRepository) SetAudio(c context.Context, id primitive.ObjectID, audio *AudioAttachment) error {
+
+	update := bson.M{
+		"$unset": bson.M{"audio": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$inc":   bson.M{"version": 1},
+	}
+	if audio != nil {
+		update = bson.M{
+			"$set": bson.M{"audio": audio, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		}
+	}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wordRepository) SetSpeech(c context.Context, id primitive.ObjectID, speech *WordSpeech) error {
+
+	update := bson.M{
+		"$unset": bson.M{"speech": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$inc":   bson.M{"version": 1},
+	}
+	if speech != nil {
+		update = bson.M{
+			"$set": bson.M{"speech": speech, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		}
+	}
+
+	_, err := r.collection.UpdateOne(c, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *wordRepository) CountWordsByAudioKey(c context.Context, key string) (int64, error) {
+	return r.collection.CountDocuments(c, bson.M{"audio.key": key})
+}
+
+func (r *wordRepository) CountWordsByNoteType(c context.Context, noteTypeID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(c, bson.M{"note_type_id": noteTypeID})
+}
+
+func (r *wordRepository) CountWordsByTopicID(c context.Context, topicID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(c, bson.M{"topic_id": topicID})
+}
+
+// CountWordsByTopicIDs counts the words of several topics in one query.
+// Topics without words are missing from the result.
+func (r *wordRepository) CountWordsByTopicIDs(c context.Context, topicIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+
+	counts := make(map[primitive.ObjectID]int64, len(topicIDs))
+	if len(topicIDs) == 0 {
+		return counts, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"topic_id": bson.M{"$in": topicIDs}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$topic_id", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(c, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var group struct {
+			TopicID primitive.ObjectID `bson:"_id"`
+			Count   int64              `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return nil, err
+		}
+		counts[group.TopicID] = group.Count
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *wordRepository) GetWordsUpdatedSince(c context.Context, topicIDs []primitive.ObjectID, since time.Time) ([]*Word, error) {
+
+	if len(topicIDs) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"topic_id":   bson.M{"$in": topicIDs},
+		"updated_at": bson.M{"$gt": since},
+	}
+
+	var words []*Word
+
+	cursor, err := r.collection.Find(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		var word Word
+		if err := cursor.Decode(&word); err != nil {
+			return nil, err
+		}
+		word.Upgrade()
+		words = append(words, &word)
+	}
+
+	return words, nil
+}
+
+// UpgradeWordSchema persists only the fields added by a schema upgrade and
+// skips words that were already rewritten at that version.
+func (r *wordRepository) UpgradeWordSchema(c context.Context, word *Word) error {
+
+	filter := bson.M{
+		"_id":            word.ID,
+		"schema_version": bson.M{"$not": bson.M{"$gte": word.SchemaVersion}},
+	}
+
+	set := bson.M{
+		"senses":         word.Senses,
+		"part_of_speech": word.PartOfSpeech,
+		"schema_version": word.SchemaVersion,
+	}
+	if word.SourceSnapshot != nil {
+		set["source_snapshot"] = word.SourceSnapshot
+	}
+
+	_, err := r.collection.UpdateOne(c, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	return nil
+}
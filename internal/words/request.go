@@ -1,23 +1,116 @@
 package words
 
+// CreateWordRequest accepts an optional client-generated ID so that offline
+// clients can create words before they first sync.
 type CreateWordRequest struct {
-	TopicID    string  `json:"topic_id" bson:"topic_id"`
-	Word       string  `json:"word" bson:"word"`
-	Definition string  `json:"definition" bson:"definition"`
-	Example    *string `json:"example" bson:"example"`
-	WordType   string  `json:"word_type" bson:"word_type"`
+	ID         string   `json:"id" bson:"id"`
+	TopicID    string   `json:"topic_id" bson:"topic_id"`
+	Word       string   `json:"word" bson:"word"`
+	Definition string   `json:"definition" bson:"definition"`
+	Example    *string  `json:"example" bson:"example"`
+	WordType   string   `json:"word_type" bson:"word_type"`
+	Tags       []string `json:"tags" bson:"tags"`
+
+	Pronunciation  string   `json:"pronunciation" bson:"pronunciation"`
+	PartOfSpeech   string   `json:"part_of_speech" bson:"part_of_speech"`
+	Senses         []Sense  `json:"senses" bson:"senses"`
+	Synonyms       []string `json:"synonyms" bson:"synonyms"`
+	Antonyms       []string `json:"antonyms" bson:"antonyms"`
+	SourceLanguage string   `json:"source_language" bson:"source_language"`
+	TargetLanguage string   `json:"target_language" bson:"target_language"`
+
+	NoteTypeID string            `json:"note_type_id" bson:"note_type_id"`
+	Fields     map[string]string `json:"fields" bson:"fields"`
+
+	Context *WordContext `json:"context,omitempty" bson:"context,omitempty"`
+
+	// Autofill fills the definition, part of speech, pronunciation and
+	// example from the dictionary when they are left empty.
+	Autofill bool `json:"autofill" bson:"-"`
 }
 
 type UpdateWordRequest struct {
-	Word       *string `json:"word" bson:"word"`
-	Definition *string `json:"definition" bson:"definition"`
-	Example    *string `json:"example" bson:"example"`
-	WordType   *string `json:"word_type" bson:"word_type"`
-	IsTrue     *bool   `json:"is_true" bson:"is_true"`
+	Word       *string   `json:"word" bson:"word"`
+	Definition *string   `json:"definition" bson:"definition"`
+	Example    *string   `json:"example" bson:"example"`
+	WordType   *string   `json:"word_type" bson:"word_type"`
+	IsTrue     *bool     `json:"is_true" bson:"is_true"`
+	Tags       *[]string `json:"tags" bson:"tags"`
+
+	Pronunciation  *string   `json:"pronunciation" bson:"pronunciation"`
+	PartOfSpeech   *string   `json:"part_of_speech" bson:"part_of_speech"`
+	Senses         *[]Sense  `json:"senses" bson:"senses"`
+	Synonyms       *[]string `json:"synonyms" bson:"synonyms"`
+	Antonyms       *[]string `json:"antonyms" bson:"antonyms"`
+	SourceLanguage *string   `json:"source_language" bson:"source_language"`
+	TargetLanguage *string   `json:"target_language" bson:"target_language"`
+
+	NoteTypeID *string            `json:"note_type_id" bson:"note_type_id"`
+	Fields     *map[string]string `json:"fields" bson:"fields"`
+
+	// ExpectedVersion is taken from the If-Match header; nil skips the check.
+	ExpectedVersion *int64 `json:"-" bson:"-"`
 }
 
 type SearchWordRequest struct {
-	TopicID *string `json:"topic_id" bson:"topic_id"`
-	Word    *string `json:"word" bson:"word"`
-	Istrue  *bool   `json:"is_true" bson:"is_true"`
+	TopicID *string `form:"topic_id" json:"topic_id" bson:"topic_id"`
+	Word    *string `form:"word" json:"word" bson:"word"`
+	Istrue  *bool   `form:"is_true" json:"is_true" bson:"is_true"`
+	Tag     *string `form:"tag" json:"tag" bson:"tag"`
+}
+
+// MergeUpstreamRequest picks upstream changes by upstream word ID. ApplyAll
+// takes every change except removing words the learner edited or studied.
+type MergeUpstreamRequest struct {
+	ApplyAll bool     `json:"apply_all" bson:"apply_all"`
+	Add      []string `json:"add" bson:"add"`
+	Update   []string `json:"update" bson:"update"`
+	Remove   []string `json:"remove" bson:"remove"`
+}
+
+type ReviewWordRequest struct {
+	Correct bool `json:"correct" bson:"correct"`
+}
+
+// BatchWordRequest mixes creates, updates and deletes of many words. The
+// operations are independent: one failing does not stop the others.
+type BatchWordRequest struct {
+	Operations []BatchWordOperation `json:"operations" bson:"operations"`
+}
+
+// BatchWordOperation is one entry of a batch. Word carries the new word for
+// a create and Changes the fields to update; Version, when set, must match
+// the stored version for an update or delete to apply.
+type BatchWordOperation struct {
+	Op      string             `json:"op" bson:"op"`
+	ID      string             `json:"id" bson:"id"`
+	Version *int64             `json:"version" bson:"version"`
+	Word    *CreateWordRequest `json:"word" bson:"word"`
+	Changes *UpdateWordRequest `json:"changes" bson:"changes"`
+}
+
+// TopicWordsRequest selects words of a topic for a bulk action; an empty
+// WordIDs selects every word in the topic.
+type TopicWordsRequest struct {
+	WordIDs []string `json:"word_ids" bson:"word_ids"`
+}
+
+type MoveTopicWordsRequest struct {
+	TargetTopicID string   `json:"target_topic_id" bson:"target_topic_id"`
+	WordIDs       []string `json:"word_ids" bson:"word_ids"`
+	ResetProgress bool     `json:"reset_progress" bson:"reset_progress"`
+}
+
+// TransferWordsRequest moves or copies words, possibly from several topics,
+// into a topic the user owns. Review state is kept unless ResetProgress.
+type TransferWordsRequest struct {
+	WordIDs       []string `json:"word_ids" bson:"word_ids"`
+	TargetTopicID string   `json:"target_topic_id" bson:"target_topic_id"`
+	ResetProgress bool     `json:"reset_progress" bson:"reset_progress"`
+}
+
+// GenerateSpeechRequest picks the voice for synthesized audio; an empty
+// voice uses the provider's default for the word's language.
+type GenerateSpeechRequest struct {
+	Voice string `form:"voice" json:"voice"`
 }
@@ -1 +1,114 @@
-package words
\ No newline at end of file
+package words
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UpstreamWordChange struct {
+	UpstreamWordID    primitive.ObjectID  `json:"upstream_word_id"`
+	LocalWordID       *primitive.ObjectID `json:"local_word_id,omitempty"`
+	Upstream          *WordContent        `json:"upstream,omitempty"`
+	Local             *WordContent        `json:"local,omitempty"`
+	ChangedFields     []string            `json:"changed_fields,omitempty"`
+	ConflictingFields []string            `json:"conflicting_fields,omitempty"`
+	HasProgress       bool                `json:"has_progress,omitempty"`
+}
+
+// UpstreamDiff lists the upstream changes of a cloned deck. Conflicts are
+// only reported by a merge: words removed upstream that were kept because
+// the learner edited or studied them.
+type UpstreamDiff struct {
+	Added     []*UpstreamWordChange `json:"added"`
+	Changed   []*UpstreamWordChange `json:"changed"`
+	Removed   []*UpstreamWordChange `json:"removed"`
+	Conflicts []*UpstreamWordChange `json:"conflicts,omitempty"`
+}
+
+// TopicProgress summarises the review history of every word in a topic.
+// Retention is the share of correct answers across all recorded reviews.
+type TopicProgress struct {
+	WordCount         int        `json:"word_count"`
+	CompletedCount    int        `json:"completed_count"`
+	ReviewedCount     int        `json:"reviewed_count"`
+	ReviewCount       int        `json:"review_count"`
+	CorrectCount      int        `json:"correct_count"`
+	CompletionPercent float64    `json:"completion_percent"`
+	RetentionPercent  float64    `json:"retention_percent"`
+	LastStudiedAt     *time.Time `json:"last_studied_at,omitempty"`
+}
+
+// Add merges another topic's progress into p, e.g. when summing a folder.
+func (p *TopicProgress) Add(other *TopicProgress) {
+	p.WordCount += other.WordCount
+	p.CompletedCount += other.CompletedCount
+	p.ReviewedCount += other.ReviewedCount
+	p.ReviewCount += other.ReviewCount
+	p.CorrectCount += other.CorrectCount
+	if other.LastStudiedAt != nil && (p.LastStudiedAt == nil || other.LastStudiedAt.After(*p.LastStudiedAt)) {
+		p.LastStudiedAt = other.LastStudiedAt
+	}
+}
+
+func (p *TopicProgress) UpdatePercentages() {
+	p.CompletionPercent = 0
+	if p.WordCount > 0 {
+		p.CompletionPercent = float64(p.CompletedCount) / float64(p.WordCount) * 100
+	}
+	p.RetentionPercent = 0
+	if p.ReviewCount > 0 {
+		p.RetentionPercent = float64(p.CorrectCount) / float64(p.ReviewCount) * 100
+	}
+}
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+
+	BatchStatusApplied   = "applied"
+	BatchStatusUnchanged = "unchanged"
+	BatchStatusFailed    = "failed"
+)
+
+type BatchOperationResult struct {
+	Index     int    `json:"index"`
+	Op        string `json:"op"`
+	ID        string `json:"id,omitempty"`
+	Status    string `json:"status"`
+	Version   int64  `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+type BatchWordResponse struct {
+	Applied int                     `json:"applied"`
+	Failed  int                     `json:"failed"`
+	Results []*BatchOperationResult `json:"results"`
+}
+
+type BulkActionResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// TranslationSuggestion proposes translations of a word and its example;
+// nothing is saved until the user accepts them through an update.
+type TranslationSuggestion struct {
+	SourceLanguage string  `json:"source_language"`
+	TargetLanguage string  `json:"target_language"`
+	Word           string  `json:"word"`
+	Example        *string `json:"example,omitempty"`
+}
+
+type TopicWordCount struct {
+	TopicID   primitive.ObjectID `json:"topic_id"`
+	WordCount int64              `json:"word_count"`
+}
+
+// TransferWordsResponse returns the words as they now are in the target
+// topic and the word counts of every topic involved.
+type TransferWordsResponse struct {
+	Words  []*Word           `json:"words"`
+	Topics []*TopicWordCount `json:"topics"`
+}
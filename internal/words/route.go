@@ -13,9 +13,22 @@ func RegisterRoutes(r *gin.Engine, handler *WordHandler) {
 		wordGroup.POST("", middleware.JWTAuthMiddleware(), handler.CreateWord)
 		wordGroup.GET("", middleware.JWTAuthMiddleware(), handler.GetAllWords)
 		wordGroup.GET("/:word_id", middleware.JWTAuthMiddleware(), handler.GetWordByID)
+		wordGroup.POST("/batch", middleware.JWTAuthMiddleware(), handler.BatchWords)
+		wordGroup.POST("/move", middleware.JWTAuthMiddleware(), handler.MoveWords)
+		wordGroup.POST("/copy", middleware.JWTAuthMiddleware(), handler.CopyWords)
 		wordGroup.GET("/topic/:topic_id", middleware.JWTAuthMiddleware(), handler.GetAllWordsByTopicID)
+		wordGroup.POST("/topic/:topic_id/move", middleware.JWTAuthMiddleware(), handler.MoveTopicWords)
+		wordGroup.POST("/topic/:topic_id/reset-progress", middleware.JWTAuthMiddleware(), handler.ResetTopicProgress)
+		wordGroup.POST("/topic/:topic_id/mark-known", middleware.JWTAuthMiddleware(), handler.MarkTopicWordsKnown)
 		wordGroup.PUT("/:word_id", middleware.JWTAuthMiddleware(), handler.UpdateWord)
 		wordGroup.DELETE("/:word_id", middleware.JWTAuthMiddleware(), handler.DeleteWord)
+		wordGroup.GET("/:word_id/render", middleware.JWTAuthMiddleware(), handler.RenderWord)
+		wordGroup.GET("/:word_id/translations", middleware.JWTAuthMiddleware(), handler.SuggestTranslations)
+		wordGroup.POST("/:word_id/review", middleware.JWTAuthMiddleware(), handler.ReviewWord)
+		wordGroup.PUT("/:word_id/audio", middleware.JWTAuthMiddleware(), handler.UploadAudio)
+		wordGroup.GET("/:word_id/audio", middleware.JWTAuthMiddleware(), handler.GetAudio)
+		wordGroup.DELETE("/:word_id/audio", middleware.JWTAuthMiddleware(), handler.DeleteAudio)
+		wordGroup.POST("/:word_id/speech", middleware.JWTAuthMiddleware(), handler.GenerateSpeech)
 	}
 
 }
\ No newline at end of file
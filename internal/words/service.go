@@ -2,71 +2,155 @@ package words
 
 import (
 	"context"
+	"errors"
+	"flashcard/helper"
+	"flashcard/internal/dictionary"
+	"flashcard/internal/notetypes"
+	"flashcard/internal/speech"
+	"flashcard/internal/storage"
+	"flashcard/internal/translation"
 	"fmt"
+	"io"
+	"mime"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type WordService interface {
 	CreateWord(c context.Context, req *CreateWordRequest, userID string) error
 	GetAllWords(c context.Context, req *SearchWordRequest) ([]*Word, error)
-	GetWordByID(c context.Context, id string) (*Word, error)
+	SearchWords(c context.Context, userID string, req *SearchWordRequest) ([]*Word, error)
+	GetWordByID(c context.Context, id string, userID string) (*Word, error)
 	GetWordsByTopicID(c context.Context, id string, req *SearchWordRequest) ([]*Word, error)
-	UpdateWord(c context.Context, id string, req *UpdateWordRequest) error
-	DeleteWord(c context.Context, id string) error
+	GetTopicWords(c context.Context, topicID string, userID string, req *SearchWordRequest) ([]*Word, error)
+	GetWordsByUserID(c context.Context, userID string) ([]*Word, error)
+	UpdateWord(c context.Context, id string, userID string, req *UpdateWordRequest) (*Word, error)
+	DeleteWord(c context.Context, id string, userID string) error
+	BatchWords(c context.Context, userID string, req *BatchWordRequest) (*BatchWordResponse, error)
+	MoveTopicWords(c context.Context, topicID string, userID string, req *MoveTopicWordsRequest) (*BulkActionResponse, error)
+	ResetTopicProgress(c context.Context, topicID string, userID string, req *TopicWordsRequest) (*BulkActionResponse, error)
+	MarkTopicWordsKnown(c context.Context, topicID string, userID string, req *TopicWordsRequest) (*BulkActionResponse, error)
+	MoveWords(c context.Context, userID string, req *TransferWordsRequest) (*TransferWordsResponse, error)
+	CopyWords(c context.Context, userID string, req *TransferWordsRequest) (*TransferWordsResponse, error)
+	DeleteTopicWords(c context.Context, topicID string) error
+	ReviewWord(c context.Context, id string, userID string, req *ReviewWordRequest) error
+	GetTopicProgress(c context.Context, topicID string, userID string) (*TopicProgress, error)
+	CountTopicWords(c context.Context, topicIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error)
+	UploadAudio(c context.Context, id string, userID string, contentType string, body io.Reader) (*AudioAttachment, error)
+	OpenAudio(c context.Context, id string, userID string) (io.ReadCloser, *AudioAttachment, error)
+	DeleteAudio(c context.Context, id string, userID string) error
+	GenerateSpeech(c context.Context, id string, userID string, req *GenerateSpeechRequest) (*WordSpeech, error)
+	MigrateWordSchema(c context.Context) (int, error)
+	GetWordsUpdatedSince(c context.Context, userID primitive.ObjectID, topicIDs []primitive.ObjectID, since time.Time) ([]*Word, error)
+	RenderWord(c context.Context, id string, userID string) ([]*notetypes.RenderedCard, error)
+	SuggestTranslations(c context.Context, id string, userID string) (*TranslationSuggestion, error)
+	DeleteUserData(c context.Context, userID string) error
+	CloneWords(c context.Context, sourceTopicID, targetTopicID, userID string) error
+	DiffUpstream(c context.Context, localTopicID, upstreamTopicID string) (*UpstreamDiff, error)
+	MergeUpstream(c context.Context, localTopicID, upstreamTopicID, userID string, req *MergeUpstreamRequest) (*UpstreamDiff, error)
 }
 
-type wordService struct {
-	wordRepository WordRepository
+type TopicRevisionTracker interface {
+	IncrementRevision(c context.Context, topicID primitive.ObjectID) error
 }
 
-func NewWordService(wordRepository WordRepository) WordService {
-	return &wordService{wordRepository: wordRepository}
+const (
+	ActivityWordAdded   = "word_added"
+	ActivityWordUpdated = "word_updated"
+	ActivityWordDeleted = "word_deleted"
+)
+
+// TopicAccess checks a user's membership role on the topic a word belongs to
+// and records word changes in the topic's activity feed. It is implemented by
+// the topics package so that words does not depend on it.
+type TopicAccess interface {
+	CanViewTopic(c context.Context, topicID, userID primitive.ObjectID) error
+	CanEditTopic(c context.Context, topicID, userID primitive.ObjectID) error
+	CanOwnTopic(c context.Context, topicID, userID primitive.ObjectID) error
+	TopicOwnerID(c context.Context, topicID primitive.ObjectID) (primitive.ObjectID, error)
+	AccessibleTopicIDs(c context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	GetTopicLanguages(c context.Context, topicID primitive.ObjectID) (string, string, error)
+	RecordWordActivity(c context.Context, topicID, userID primitive.ObjectID, action string, word *Word) error
 }
 
-func (s *wordService) CreateWord(c context.Context, req *CreateWordRequest, userID string) error {
+// CardSync keeps the study cards derived from words in step with them. It is
+// implemented by the cards package, which owns generation and scheduling.
+// Schedules are kept per user, so resetting, marking known and copying act
+// on one user's.
+type CardSync interface {
+	SyncWordCards(c context.Context, words ...*Word) error
+	DeleteWordCards(c context.Context, wordIDs ...primitive.ObjectID) error
+	ResetWordCards(c context.Context, userID primitive.ObjectID, wordIDs ...primitive.ObjectID) error
+	MarkWordCardsKnown(c context.Context, userID primitive.ObjectID, wordIDs ...primitive.ObjectID) error
+	CopyWordCards(c context.Context, userID primitive.ObjectID, copies map[primitive.ObjectID]*Word) error
+	DeleteTopicCards(c context.Context, topicID primitive.ObjectID) error
+}
 
-	if req.TopicID == "" {
-		return fmt.Errorf("topic id is required")
-	}
+// DeletionLog records deleted words so that offline clients can drop their
+// local copies on the next sync.
+type DeletionLog interface {
+	RecordWordDeletions(c context.Context, topicID primitive.ObjectID, wordIDs ...primitive.ObjectID) error
+}
 
-	if req.Word == "" {
-		return fmt.Errorf("word is required")
-	}
+const migrationBatchSize = 500
 
-	if req.Definition == "" {
-		return fmt.Errorf("definition is required")
-	}
+const maxContextLength = 300
 
-	if userID == "" {
-		return fmt.Errorf("user id is required")
+type wordService struct {
+	wordRepository     WordRepository
+	progressRepository ProgressRepository
+	topicRevisions     TopicRevisionTracker
+	topicAccess        TopicAccess
+	blobStore          storage.BlobStore
+	maxAudioBytes      int64
+	noteTypes          notetypes.NoteTypeService
+	cardSync           CardSync
+	deletionLog        DeletionLog
+	dictionary         dictionary.DictionaryService
+	translator         translation.Translator
+	speech             speech.SpeechService
+}
+
+func NewWordService(wordRepository WordRepository, progressRepository ProgressRepository, topicRevisions TopicRevisionTracker, topicAccess TopicAccess, blobStore storage.BlobStore, maxAudioBytes int64, noteTypes notetypes.NoteTypeService, cardSync CardSync, deletionLog DeletionLog, dictionary dictionary.DictionaryService, translator translation.Translator, speech speech.SpeechService) WordService {
+	return &wordService{
+		wordRepository:     wordRepository,
+		progressRepository: progressRepository,
+		topicRevisions:     topicRevisions,
+		topicAccess:        topicAccess,
+		blobStore:          blobStore,
+		maxAudioBytes:      maxAudioBytes,
+		noteTypes:          noteTypes,
+		cardSync:           cardSync,
+		deletionLog:        deletionLog,
+		dictionary:         dictionary,
+		translator:         translator,
+		speech:             speech,
 	}
+}
 
-	objectID, err := primitive.ObjectIDFromHex(userID)
+func (s *wordService) CreateWord(c context.Context, req *CreateWordRequest, userID string) error {
+
+	word, err := s.newWord(c, req, userID)
 	if err != nil {
 		return err
 	}
 
-	objectTopicID, err := primitive.ObjectIDFromHex(req.TopicID)
-	if err != nil {
+	if err := s.wordRepository.CreateWord(c, word); err != nil {
 		return err
 	}
 
-	word := &Word{
-		ID:         primitive.NewObjectID(),
-		TopicID:    objectTopicID,
-		UserID:     objectID,
-		Word:       req.Word,
-		Definition: req.Definition,
-		Example:    req.Example,
-		IsTrue:     false,
-		WordType:   req.WordType,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	if err := s.cardSync.SyncWordCards(c, word); err != nil {
+		return err
+	}
+
+	if err := s.topicRevisions.IncrementRevision(c, word.TopicID); err != nil {
+		return err
 	}
 
-	return s.wordRepository.CreateWord(c, word)
+	return s.topicAccess.RecordWordActivity(c, word.TopicID, word.UserID, ActivityWordAdded, word)
 
 }
 
@@ -74,18 +158,63 @@ func (s *wordService) GetAllWords(c context.Context, req *SearchWordRequest) ([]
 	return s.wordRepository.GetAllWords(c, req)
 }
 
-func (s *wordService) GetWordByID(c context.Context, id string) (*Word, error) {
+// SearchWords searches the words of the topics the user can view, or of one
+// of them when the request names a topic.
+func (s *wordService) SearchWords(c context.Context, userID string, req *SearchWordRequest) ([]*Word, error) {
 
-	if id == "" {
-		return nil, fmt.Errorf("word id is required")
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	var topicIDs []primitive.ObjectID
+	if req.TopicID != nil && *req.TopicID != "" {
+		topicID, err := primitive.ObjectIDFromHex(*req.TopicID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.topicAccess.CanViewTopic(c, topicID, objectUserID); err != nil {
+			return nil, err
+		}
+		topicIDs = []primitive.ObjectID{topicID}
+	} else {
+		topicIDs, err = s.topicAccess.AccessibleTopicIDs(c, objectUserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query := *req
+	query.Istrue = nil
+
+	found, err := s.wordRepository.GetWordsInTopics(c, topicIDs, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withProgress(c, objectUserID, found...); err != nil {
+		return nil, err
+	}
+
+	return filterKnown(found, req.Istrue), nil
+}
+
+func (s *wordService) GetWordByID(c context.Context, id string, userID string) (*Word, error) {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.wordRepository.GetWordByID(c, objectID)
+	if err := s.topicAccess.CanViewTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.withProgress(c, objectUserID, word); err != nil {
+		return nil, err
+	}
+
+	return word, nil
 
 }
 
@@ -104,56 +233,1299 @@ func (s *wordService) GetWordsByTopicID(c context.Context, id string, req *Searc
 
 }
 
-func (s *wordService) UpdateWord(c context.Context, id string, req *UpdateWordRequest) error {
+func (s *wordService) GetTopicWords(c context.Context, topicID string, userID string, req *SearchWordRequest) ([]*Word, error) {
 
-	if id == "" {
-		return fmt.Errorf("word id is required")
+	if topicID == "" {
+		return nil, fmt.Errorf("topic id is required")
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := primitive.ObjectIDFromHex(topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanViewTopic(c, objectID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	query := *req
+	query.Istrue = nil
+
+	topicWords, err := s.wordRepository.GetWordsByTopicID(c, objectID, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withProgress(c, objectUserID, topicWords...); err != nil {
+		return nil, err
+	}
+
+	return filterKnown(topicWords, req.Istrue), nil
+
+}
+
+func (s *wordService) UpdateWord(c context.Context, id string, userID string, req *UpdateWordRequest) (*Word, error) {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanEditTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	before := word.Content()
+	expectedVersion := word.Version
+
+	set, unset, err := s.editWord(c, word, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsTrue != nil {
+		known := *req.IsTrue
+		if err := s.saveProgress(c, objectUserID, []*Word{word}, func(p *WordProgress) { p.IsTrue = known }); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return word, s.withProgress(c, objectUserID, word)
+	}
+
+	updated, err := s.wordRepository.UpdateWordFields(c, word.ID, expectedVersion, set, unset)
+	if err != nil {
+		return nil, err
+	}
+
+	if !updated {
+		return nil, fmt.Errorf("%w: word was modified concurrently", helper.ErrPreconditionFailed)
+	}
+
+	if err := s.cardSync.SyncWordCards(c, word); err != nil {
+		return nil, err
+	}
+
+	if err := s.topicRevisions.IncrementRevision(c, word.TopicID); err != nil {
+		return nil, err
+	}
+
+	if len(diffWordContent(before, word.Content())) > 0 {
+		if err := s.topicAccess.RecordWordActivity(c, word.TopicID, objectUserID, ActivityWordUpdated, word); err != nil {
+			return nil, err
+		}
+	}
+
+	return word, s.withProgress(c, objectUserID, word)
+}
+
+func (s *wordService) DeleteWord(c context.Context, id string, userID string) error {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
 	if err != nil {
 		return err
 	}
 
-	word, err := s.wordRepository.GetWordByID(c, objectID)
+	if err := s.topicAccess.CanEditTopic(c, word.TopicID, objectUserID); err != nil {
+		return err
+	}
+
+	if err := s.wordRepository.DeleteWord(c, word.ID); err != nil {
+		return err
+	}
+
+	if err := s.cardSync.DeleteWordCards(c, word.ID); err != nil {
+		return err
+	}
+
+	if err := s.progressRepository.DeleteProgressByWordIDs(c, []primitive.ObjectID{word.ID}); err != nil {
+		return err
+	}
+
+	if err := s.deletionLog.RecordWordDeletions(c, word.TopicID, word.ID); err != nil {
+		return err
+	}
+
+	if err := s.releaseAudio(c, word); err != nil {
+		return err
+	}
+
+	if err := s.topicRevisions.IncrementRevision(c, word.TopicID); err != nil {
+		return err
+	}
+
+	return s.topicAccess.RecordWordActivity(c, word.TopicID, objectUserID, ActivityWordDeleted, word)
+
+}
+
+func (s *wordService) DeleteTopicWords(c context.Context, topicID string) error {
+
+	if topicID == "" {
+		return fmt.Errorf("topic id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(topicID)
 	if err != nil {
 		return err
 	}
 
-	if req.Word != nil {
-		word.Word = *req.Word
+	topicWords, err := s.wordRepository.GetWordsByTopicID(c, objectID, &SearchWordRequest{})
+	if err != nil {
+		return err
 	}
 
-	if req.Definition != nil {
-		word.Definition = *req.Definition
+	if err := s.wordRepository.DeleteWordsByTopicID(c, objectID); err != nil {
+		return err
 	}
 
-	if req.Example != nil {
-		word.Example = req.Example
+	if err := s.cardSync.DeleteTopicCards(c, objectID); err != nil {
+		return err
 	}
 
-	if req.WordType != nil {
-		word.WordType = *req.WordType
+	if err := s.progressRepository.DeleteProgressByWordIDs(c, wordIDs(topicWords)); err != nil {
+		return err
 	}
 
-	if req.IsTrue != nil {
-		word.IsTrue = *req.IsTrue
+	return s.releaseAudio(c, topicWords...)
+
+}
+
+func (s *wordService) ReviewWord(c context.Context, id string, userID string, req *ReviewWordRequest) error {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return err
 	}
 
-	return s.wordRepository.UpdateWord(c, objectID, word)
+	if err := s.topicAccess.CanViewTopic(c, word.TopicID, objectUserID); err != nil {
+		return err
+	}
+
+	return s.progressRepository.RecordReview(c, word, objectUserID, req.Correct, time.Now())
+
 }
 
-func (s *wordService) DeleteWord(c context.Context, id string) error {
+// GetTopicProgress summarises the user's progress on the words of a topic.
+func (s *wordService) GetTopicProgress(c context.Context, topicID string, userID string) (*TopicProgress, error) {
 
-	if id == "" {
-		return fmt.Errorf("word id is required")
+	objectID, err := primitive.ObjectIDFromHex(topicID)
+	if err != nil {
+		return nil, err
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topicWords, err := s.wordRepository.GetWordsByTopicID(c, objectID, &SearchWordRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	wordProgress, err := s.progressOf(c, objectUserID, topicWords)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &TopicProgress{WordCount: len(topicWords)}
+	for _, p := range wordProgress {
+		if p.IsTrue {
+			progress.CompletedCount++
+		}
+		if p.ReviewCount > 0 {
+			progress.ReviewedCount++
+		}
+		progress.ReviewCount += p.ReviewCount
+		progress.CorrectCount += p.CorrectCount
+		if p.LastReviewedAt != nil && (progress.LastStudiedAt == nil || p.LastReviewedAt.After(*progress.LastStudiedAt)) {
+			progress.LastStudiedAt = p.LastReviewedAt
+		}
+	}
+
+	progress.UpdatePercentages()
+
+	return progress, nil
+
+}
+
+// CountTopicWords returns the number of words in each of the given topics.
+func (s *wordService) CountTopicWords(c context.Context, topicIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	return s.wordRepository.CountWordsByTopicIDs(c, topicIDs)
+}
+
+// GetWordsByUserID returns every word the user created, across all topics.
+func (s *wordService) GetWordsByUserID(c context.Context, userID string) ([]*Word, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.wordRepository.GetWordsByUserID(c, objectID)
+}
+
+// DeleteUserData deletes the words the user created in topics they own, or
+// in topics that no longer exist. Words they contributed to other users'
+// topics stay in those topics and are handed over to the topic owner.
+func (s *wordService) DeleteUserData(c context.Context, userID string) error {
+
+	if userID == "" {
+		return fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	userWords, err := s.wordRepository.GetWordsByUserID(c, objectID)
+	if err != nil {
+		return err
+	}
+
+	wordsByTopic := make(map[primitive.ObjectID][]*Word)
+	for _, word := range userWords {
+		wordsByTopic[word.TopicID] = append(wordsByTopic[word.TopicID], word)
+	}
+
+	var deleted []*Word
+	for topicID, topicWords := range wordsByTopic {
+		ownerID, err := s.topicAccess.TopicOwnerID(c, topicID)
+		if err != nil && !errors.Is(err, helper.ErrResourceNotFound) {
+			return err
+		}
+
+		if err != nil || ownerID == objectID {
+			deleted = append(deleted, topicWords...)
+			continue
+		}
+
+		if err := s.wordRepository.ReassignWords(c, wordIDs(topicWords), ownerID); err != nil {
+			return err
+		}
+		for _, word := range topicWords {
+			word.UserID = ownerID
+		}
+		if err := s.cardSync.SyncWordCards(c, topicWords...); err != nil {
+			return err
+		}
+	}
+
+	ids := wordIDs(deleted)
+	if err := s.wordRepository.DeleteWordsByIDs(c, ids); err != nil {
+		return err
+	}
+
+	if err := s.cardSync.DeleteWordCards(c, ids...); err != nil {
+		return err
+	}
+
+	if err := s.progressRepository.DeleteProgressByWordIDs(c, ids); err != nil {
+		return err
+	}
+
+	if err := s.progressRepository.DeleteProgressByUserID(c, objectID); err != nil {
+		return err
+	}
+
+	wordIDsByTopic := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, word := range deleted {
+		wordIDsByTopic[word.TopicID] = append(wordIDsByTopic[word.TopicID], word.ID)
+	}
+	for topicID, topicWordIDs := range wordIDsByTopic {
+		if err := s.deletionLog.RecordWordDeletions(c, topicID, topicWordIDs...); err != nil {
+			return err
+		}
+	}
+
+	return s.releaseAudio(c, deleted...)
+
+}
+
+func (s *wordService) CloneWords(c context.Context, sourceTopicID, targetTopicID, userID string) error {
+
+	sourceID, err := primitive.ObjectIDFromHex(sourceTopicID)
+	if err != nil {
+		return err
+	}
+
+	sourceWords, err := s.wordRepository.GetWordsByTopicID(c, sourceID, &SearchWordRequest{})
 	if err != nil {
 		return err
 	}
 
-	return s.wordRepository.DeleteWord(c, objectID)
+	return s.addClones(c, sourceWords, targetTopicID, userID)
+
+}
+
+func (s *wordService) DiffUpstream(c context.Context, localTopicID, upstreamTopicID string) (*UpstreamDiff, error) {
+
+	localWords, upstreamWords, err := s.loadUpstreamPair(c, localTopicID, upstreamTopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	studied, err := s.studiedWords(c, localWords)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffUpstream(localWords, upstreamWords, studied), nil
+
+}
+
+func (s *wordService) MergeUpstream(c context.Context, localTopicID, upstreamTopicID, userID string, req *MergeUpstreamRequest) (*UpstreamDiff, error) {
 
+	localWords, upstreamWords, err := s.loadUpstreamPair(c, localTopicID, upstreamTopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	studied, err := s.studiedWords(c, localWords)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffUpstream(localWords, upstreamWords, studied)
+	applied := &UpstreamDiff{}
+
+	upstreamByID := make(map[primitive.ObjectID]*Word, len(upstreamWords))
+	for _, word := range upstreamWords {
+		upstreamByID[word.ID] = word
+	}
+
+	localByID := make(map[primitive.ObjectID]*Word, len(localWords))
+	for _, word := range localWords {
+		localByID[word.ID] = word
+	}
+
+	selected := func(ids []string, change *UpstreamWordChange) bool {
+		if req.ApplyAll {
+			return true
+		}
+		for _, id := range ids {
+			if id == change.UpstreamWordID.Hex() {
+				return true
+			}
+		}
+		return false
+	}
+
+	var additions []*Word
+	for _, change := range diff.Added {
+		if selected(req.Add, change) {
+			additions = append(additions, upstreamByID[change.UpstreamWordID])
+			applied.Added = append(applied.Added, change)
+		}
+	}
+
+	if err := s.addClones(c, additions, localTopicID, userID); err != nil {
+		return nil, err
+	}
+
+	for _, change := range diff.Changed {
+		if !selected(req.Update, change) {
+			continue
+		}
+
+		local := localByID[*change.LocalWordID]
+		upstream := upstreamByID[change.UpstreamWordID]
+
+		snapshot, err := bson.Marshal(local)
+		if err != nil {
+			return nil, err
+		}
+		expectedVersion := local.Version
+
+		mergeUpstreamContent(local, upstream)
+
+		set, unset, err := helper.ChangedFields(snapshot, local)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(set) > 0 || len(unset) > 0 {
+			local.Version++
+			local.UpdatedAt = time.Now()
+			set["version"] = local.Version
+			set["updated_at"] = local.UpdatedAt
+
+			updated, err := s.wordRepository.UpdateWordFields(c, local.ID, expectedVersion, set, unset)
+			if err != nil {
+				return nil, err
+			}
+			if !updated {
+				return nil, fmt.Errorf("%w: word %s was modified concurrently", helper.ErrPreconditionFailed, local.ID.Hex())
+			}
+		}
+		if err := s.cardSync.SyncWordCards(c, local); err != nil {
+			return nil, err
+		}
+		applied.Changed = append(applied.Changed, change)
+	}
+
+	for _, change := range diff.Removed {
+		if !selected(req.Remove, change) {
+			continue
+		}
+
+		// Applying everything must not throw away the learner's own work;
+		// such words are only removed when picked explicitly.
+		if req.ApplyAll && (len(change.ConflictingFields) > 0 || change.HasProgress) {
+			applied.Conflicts = append(applied.Conflicts, change)
+			continue
+		}
+
+		if err := s.wordRepository.DeleteWord(c, *change.LocalWordID); err != nil {
+			return nil, err
+		}
+		if err := s.cardSync.DeleteWordCards(c, *change.LocalWordID); err != nil {
+			return nil, err
+		}
+		if err := s.progressRepository.DeleteProgressByWordIDs(c, []primitive.ObjectID{*change.LocalWordID}); err != nil {
+			return nil, err
+		}
+		if err := s.deletionLog.RecordWordDeletions(c, localByID[*change.LocalWordID].TopicID, *change.LocalWordID); err != nil {
+			return nil, err
+		}
+		if err := s.releaseAudio(c, localByID[*change.LocalWordID]); err != nil {
+			return nil, err
+		}
+		applied.Removed = append(applied.Removed, change)
+	}
+
+	if len(applied.Changed) > 0 || len(applied.Removed) > 0 {
+		objectID, err := primitive.ObjectIDFromHex(localTopicID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.topicRevisions.IncrementRevision(c, objectID); err != nil {
+			return nil, err
+		}
+	}
+
+	return applied, nil
+
+}
+
+func (s *wordService) UploadAudio(c context.Context, id string, userID string, contentType string, body io.Reader) (*AudioAttachment, error) {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanEditTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "audio/") {
+		return nil, fmt.Errorf("an audio content type is required")
+	}
+
+	key := fmt.Sprintf("audio/%s/%s", word.ID.Hex(), primitive.NewObjectID().Hex())
+	size, err := s.blobStore.Put(c, key, io.LimitReader(body, s.maxAudioBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 || size > s.maxAudioBytes {
+		if err := s.blobStore.Delete(c, key); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("audio file must be between 1 and %d bytes", s.maxAudioBytes)
+	}
+
+	audio := &AudioAttachment{
+		Key:         key,
+		ContentType: mediaType,
+		Size:        size,
+		UploadedAt:  time.Now(),
+	}
+
+	if err := s.wordRepository.SetAudio(c, word.ID, audio); err != nil {
+		s.blobStore.Delete(c, key)
+		return nil, err
+	}
+
+	if err := s.releaseAudio(c, word); err != nil {
+		return nil, err
+	}
+
+	return audio, nil
+
+}
+
+func (s *wordService) OpenAudio(c context.Context, id string, userID string) (io.ReadCloser, *AudioAttachment, error) {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.topicAccess.CanViewTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, nil, err
+	}
+
+	if word.Audio == nil {
+		return nil, nil, fmt.Errorf("%w: word has no audio", helper.ErrResourceNotFound)
+	}
+
+	reader, err := s.blobStore.Open(c, word.Audio.Key)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, fmt.Errorf("%w: audio file is missing", helper.ErrResourceNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reader, word.Audio, nil
+
+}
+
+func (s *wordService) DeleteAudio(c context.Context, id string, userID string) error {
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.topicAccess.CanEditTopic(c, word.TopicID, objectUserID); err != nil {
+		return err
+	}
+
+	if word.Audio == nil {
+		return nil
+	}
+
+	if err := s.wordRepository.SetAudio(c, word.ID, nil); err != nil {
+		return err
+	}
+
+	return s.releaseAudio(c, word)
+
+}
+
+func (s *wordService) RenderWord(c context.Context, id string, userID string) ([]*notetypes.RenderedCard, error) {
+
+	word, err := s.GetWordByID(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteType, err := s.noteTypes.ResolveNoteType(c, word.NoteTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return noteType.Render(word.FieldValues())
+}
+
+// GetWordsUpdatedSince returns words of the given topics that changed, or
+// whose progress for the user changed, after since, with the user's
+// progress. Callers are expected to have checked access to the topics.
+func (s *wordService) GetWordsUpdatedSince(c context.Context, userID primitive.ObjectID, topicIDs []primitive.ObjectID, since time.Time) ([]*Word, error) {
+
+	changed, err := s.wordRepository.GetWordsUpdatedSince(c, topicIDs, since)
+	if err != nil {
+		return nil, err
+	}
+
+	studied, err := s.progressRepository.GetProgressUpdatedSince(c, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(changed))
+	for _, word := range changed {
+		seen[word.ID] = true
+	}
+
+	var missing []primitive.ObjectID
+	for _, p := range studied {
+		if !seen[p.WordID] {
+			missing = append(missing, p.WordID)
+		}
+	}
+
+	if len(missing) > 0 {
+		studiedWords, err := s.wordRepository.GetWordsByIDs(c, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		inTopics := make(map[primitive.ObjectID]bool, len(topicIDs))
+		for _, topicID := range topicIDs {
+			inTopics[topicID] = true
+		}
+		for _, word := range studiedWords {
+			if inTopics[word.TopicID] {
+				changed = append(changed, word)
+			}
+		}
+	}
+
+	if err := s.withProgress(c, userID, changed...); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// MigrateWordSchema rewrites words stored with an older schema version.
+// Reads already upgrade words in memory, so this only brings the stored
+// documents up to date and is safe to run in the background.
+func (s *wordService) MigrateWordSchema(c context.Context) (int, error) {
+
+	migrated := 0
+	for {
+		batch, err := s.wordRepository.GetWordsBelowSchemaVersion(c, CurrentSchemaVersion, migrationBatchSize)
+		if err != nil {
+			return migrated, err
+		}
+
+		if len(batch) == 0 {
+			return migrated, nil
+		}
+
+		for _, word := range batch {
+			word.Upgrade()
+			if err := s.wordRepository.UpgradeWordSchema(c, word); err != nil {
+				return migrated, err
+			}
+			migrated++
+		}
+	}
+
+}
+
+// releaseAudio deletes the audio blobs of the given (already updated or
+// deleted) words once no remaining word references them.
+func (s *wordService) releaseAudio(c context.Context, words ...*Word) error {
+
+	released := make(map[string]struct{})
+	for _, word := range words {
+		if word == nil || word.Audio == nil {
+			continue
+		}
+		key := word.Audio.Key
+		if _, ok := released[key]; ok {
+			continue
+		}
+		released[key] = struct{}{}
+
+		count, err := s.wordRepository.CountWordsByAudioKey(c, key)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			if err := s.blobStore.Delete(c, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// prepareCard validates the rich card fields and keeps the legacy
+// definition/example/word_type fields in step with the primary sense.
+func prepareCard(word *Word) error {
+
+	word.Senses = cleanSenses(word.Senses)
+	if len(word.Senses) == 0 {
+		word.Senses = cleanSenses(legacySenses(word.Definition, word.Example))
+	}
+
+	if len(word.Senses) == 0 && word.NoteTypeID == nil {
+		return fmt.Errorf("definition is required")
+	}
+
+	if word.PartOfSpeech != "" {
+		partOfSpeech, ok := ParsePartOfSpeech(word.PartOfSpeech)
+		if !ok {
+			return fmt.Errorf("invalid part of speech %q", word.PartOfSpeech)
+		}
+		word.PartOfSpeech = partOfSpeech
+	} else {
+		word.PartOfSpeech, _ = ParsePartOfSpeech(word.WordType)
+	}
+
+	var err error
+	if word.SourceLanguage, err = normalizeLanguageCode(word.SourceLanguage); err != nil {
+		return err
+	}
+
+	if word.TargetLanguage, err = normalizeLanguageCode(word.TargetLanguage); err != nil {
+		return err
+	}
+
+	word.Pronunciation = strings.TrimSpace(word.Pronunciation)
+	word.Synonyms = cleanList(word.Synonyms)
+	word.Antonyms = cleanList(word.Antonyms)
+	word.syncLegacyFields()
+	word.SchemaVersion = CurrentSchemaVersion
+
+	return nil
+}
+
+// newWord validates a create request and builds the word it describes
+// without persisting it.
+func (s *wordService) newWord(c context.Context, req *CreateWordRequest, userID string) (*Word, error) {
+
+	if req.TopicID == "" {
+		return nil, fmt.Errorf("topic id is required")
+	}
+
+	if req.Word == "" && req.NoteTypeID == "" {
+		return nil, fmt.Errorf("word is required")
+	}
+
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectTopicID, err := primitive.ObjectIDFromHex(req.TopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	wordID := primitive.NewObjectID()
+	if req.ID != "" {
+		if wordID, err = primitive.ObjectIDFromHex(req.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.topicAccess.CanEditTopic(c, objectTopicID, objectID); err != nil {
+		return nil, err
+	}
+
+	if req.Context != nil && (len(req.Context.Source) > maxContextLength || len(req.Context.Reference) > maxContextLength) {
+		return nil, fmt.Errorf("context source and reference must be at most %d bytes", maxContextLength)
+	}
+
+	word := &Word{
+		ID:             wordID,
+		TopicID:        objectTopicID,
+		UserID:         objectID,
+		Word:           req.Word,
+		Definition:     req.Definition,
+		Example:        req.Example,
+		IsTrue:         false,
+		WordType:       req.WordType,
+		Tags:           helper.NormalizeTags(req.Tags),
+		Pronunciation:  req.Pronunciation,
+		PartOfSpeech:   req.PartOfSpeech,
+		Senses:         req.Senses,
+		Synonyms:       req.Synonyms,
+		Antonyms:       req.Antonyms,
+		SourceLanguage: req.SourceLanguage,
+		TargetLanguage: req.TargetLanguage,
+		Context:        req.Context,
+		Version:        1,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if req.Autofill {
+		if err := s.autofill(c, word); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.setNoteType(c, word, req.NoteTypeID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.Fields != nil {
+		word.Fields = req.Fields
+	}
+
+	if err := s.applyNoteFields(c, word, false); err != nil {
+		return nil, err
+	}
+
+	if err := prepareCard(word); err != nil {
+		return nil, err
+	}
+
+	return word, nil
+
+}
+
+// editWord applies an update request to a loaded word and returns the
+// fields to $set and $unset. When anything changed the word's version is
+// bumped; the caller persists it conditionally on the previous version.
+func (s *wordService) editWord(c context.Context, word *Word, req *UpdateWordRequest, userID string) (bson.M, bson.M, error) {
+
+	if req.ExpectedVersion != nil && *req.ExpectedVersion != word.Version {
+		return nil, nil, fmt.Errorf("%w: word is at version %d", helper.ErrPreconditionFailed, word.Version)
+	}
+
+	snapshot, err := bson.Marshal(word)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.Word != nil {
+		word.Word = *req.Word
+	}
+
+	if req.Definition != nil {
+		word.Definition = *req.Definition
+	}
+
+	if req.Example != nil {
+		word.Example = req.Example
+	}
+
+	if req.WordType != nil {
+		word.WordType = *req.WordType
+		if partOfSpeech, ok := ParsePartOfSpeech(*req.WordType); ok && req.PartOfSpeech == nil {
+			word.PartOfSpeech = partOfSpeech
+		}
+	}
+
+	if req.Senses != nil {
+		word.Senses = *req.Senses
+	} else if req.Definition != nil || req.Example != nil {
+		word.applyLegacyFields()
+	}
+
+	if req.PartOfSpeech != nil {
+		word.PartOfSpeech = *req.PartOfSpeech
+	}
+
+	if req.Pronunciation != nil {
+		word.Pronunciation = *req.Pronunciation
+	}
+
+	if req.Synonyms != nil {
+		word.Synonyms = *req.Synonyms
+	}
+
+	if req.Antonyms != nil {
+		word.Antonyms = *req.Antonyms
+	}
+
+	if req.SourceLanguage != nil {
+		word.SourceLanguage = *req.SourceLanguage
+	}
+
+	if req.TargetLanguage != nil {
+		word.TargetLanguage = *req.TargetLanguage
+	}
+
+	if req.Tags != nil {
+		word.Tags = helper.NormalizeTags(*req.Tags)
+	}
+
+	if req.NoteTypeID != nil {
+		if err := s.setNoteType(c, word, *req.NoteTypeID, userID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if req.Fields != nil {
+		word.Fields = *req.Fields
+	}
+
+	columnsEdited := req.Fields == nil && (req.Word != nil || req.Definition != nil)
+	if err := s.applyNoteFields(c, word, columnsEdited); err != nil {
+		return nil, nil, err
+	}
+
+	word.dropStaleSpeech()
+
+	if err := prepareCard(word); err != nil {
+		return nil, nil, err
+	}
+
+	set, unset, err := helper.ChangedFields(snapshot, word)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return set, unset, nil
+	}
+
+	word.Version++
+	word.UpdatedAt = time.Now()
+	set["version"] = word.Version
+	set["updated_at"] = word.UpdatedAt
+
+	return set, unset, nil
+}
+
+func (s *wordService) loadWord(c context.Context, id string, userID string) (*Word, primitive.ObjectID, error) {
+
+	if id == "" {
+		return nil, primitive.NilObjectID, fmt.Errorf("word id is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	word, err := s.wordRepository.GetWordByID(c, objectID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	if word == nil {
+		return nil, primitive.NilObjectID, fmt.Errorf("%w: word not found", helper.ErrResourceNotFound)
+	}
+
+	return word, objectUserID, nil
+}
+
+func (s *wordService) loadUpstreamPair(c context.Context, localTopicID, upstreamTopicID string) ([]*Word, []*Word, error) {
+
+	localID, err := primitive.ObjectIDFromHex(localTopicID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upstreamID, err := primitive.ObjectIDFromHex(upstreamTopicID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	localWords, err := s.wordRepository.GetWordsByTopicID(c, localID, &SearchWordRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upstreamWords, err := s.wordRepository.GetWordsByTopicID(c, upstreamID, &SearchWordRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return localWords, upstreamWords, nil
+}
+
+func (s *wordService) addClones(c context.Context, sourceWords []*Word, targetTopicID, userID string) error {
+
+	if len(sourceWords) == 0 {
+		return nil
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(targetTopicID)
+	if err != nil {
+		return err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	clones := make([]*Word, 0, len(sourceWords))
+	for _, source := range sourceWords {
+		sourceWordID := source.ID
+		snapshot := source.Content()
+		clones = append(clones, &Word{
+			ID:             primitive.NewObjectID(),
+			TopicID:        targetID,
+			UserID:         objectUserID,
+			Word:           source.Word,
+			Definition:     source.Definition,
+			Example:        source.Example,
+			WordType:       source.WordType,
+			IsTrue:         false,
+			Tags:           source.Tags,
+			Pronunciation:  source.Pronunciation,
+			PartOfSpeech:   source.PartOfSpeech,
+			Senses:         source.Senses,
+			Synonyms:       source.Synonyms,
+			Antonyms:       source.Antonyms,
+			SourceLanguage: source.SourceLanguage,
+			TargetLanguage: source.TargetLanguage,
+			Audio:          source.Audio,
+			Speech:         source.Speech,
+			Context:        source.Context,
+			NoteTypeID:     source.NoteTypeID,
+			Fields:         source.Fields,
+			SchemaVersion:  CurrentSchemaVersion,
+			Version:        1,
+			SourceWordID:   &sourceWordID,
+			SourceSnapshot: &snapshot,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+
+	if err := s.wordRepository.CreateWords(c, clones); err != nil {
+		return err
+	}
+
+	if err := s.cardSync.SyncWordCards(c, clones...); err != nil {
+		return err
+	}
+
+	return s.topicRevisions.IncrementRevision(c, targetID)
+}
+
+// studiedWords reports which of the words anyone has reviewed.
+func (s *wordService) studiedWords(c context.Context, words []*Word) (map[primitive.ObjectID]bool, error) {
+
+	ids, err := s.progressRepository.GetStudiedWordIDs(c, wordIDs(words))
+	if err != nil {
+		return nil, err
+	}
+
+	studied := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		studied[id] = true
+	}
+	for _, word := range words {
+		if word.ReviewCount > 0 {
+			studied[word.ID] = true
+		}
+	}
+
+	return studied, nil
+}
+
+// diffUpstream compares a cloned deck with its source. A field counts as
+// changed upstream when it differs from the snapshot taken at the last sync,
+// and as conflicting when the learner has edited it locally as well. For a
+// word removed upstream, the conflicting fields are the learner's edits.
+func diffUpstream(localWords, upstreamWords []*Word, studied map[primitive.ObjectID]bool) *UpstreamDiff {
+
+	diff := &UpstreamDiff{
+		Added:   []*UpstreamWordChange{},
+		Changed: []*UpstreamWordChange{},
+		Removed: []*UpstreamWordChange{},
+	}
+
+	localBySource := make(map[primitive.ObjectID]*Word, len(localWords))
+	for _, word := range localWords {
+		if word.SourceWordID != nil {
+			localBySource[*word.SourceWordID] = word
+		}
+	}
+
+	upstreamIDs := make(map[primitive.ObjectID]struct{}, len(upstreamWords))
+	for _, upstream := range upstreamWords {
+		upstreamIDs[upstream.ID] = struct{}{}
+		upstreamContent := upstream.Content()
+
+		local, ok := localBySource[upstream.ID]
+		if !ok {
+			diff.Added = append(diff.Added, &UpstreamWordChange{
+				UpstreamWordID: upstream.ID,
+				Upstream:       &upstreamContent,
+			})
+			continue
+		}
+
+		localContent := local.Content()
+		base := localContent
+		if local.SourceSnapshot != nil {
+			base = *local.SourceSnapshot
+		}
+
+		changed := diffWordContent(base, upstreamContent)
+		if len(changed) == 0 {
+			continue
+		}
+
+		var conflicting []string
+		for _, field := range diffWordContent(base, localContent) {
+			if containsField(changed, field) && containsField(diffWordContent(localContent, upstreamContent), field) {
+				conflicting = append(conflicting, field)
+			}
+		}
+
+		localWordID := local.ID
+		diff.Changed = append(diff.Changed, &UpstreamWordChange{
+			UpstreamWordID:    upstream.ID,
+			LocalWordID:       &localWordID,
+			Upstream:          &upstreamContent,
+			Local:             &localContent,
+			ChangedFields:     changed,
+			ConflictingFields: conflicting,
+		})
+	}
+
+	for _, local := range localWords {
+		if local.SourceWordID == nil {
+			continue
+		}
+		if _, ok := upstreamIDs[*local.SourceWordID]; ok {
+			continue
+		}
+		localWordID := local.ID
+		localContent := local.Content()
+		var edited []string
+		if local.SourceSnapshot != nil {
+			edited = diffWordContent(*local.SourceSnapshot, localContent)
+		}
+		diff.Removed = append(diff.Removed, &UpstreamWordChange{
+			UpstreamWordID:    *local.SourceWordID,
+			LocalWordID:       &localWordID,
+			Local:             &localContent,
+			ConflictingFields: edited,
+			HasProgress:       studied[local.ID],
+		})
+	}
+
+	return diff
+}
+
+// mergeUpstreamContent applies upstream edits only to the fields the learner
+// has not changed, and leaves progress untouched.
+func mergeUpstreamContent(local, upstream *Word) {
+
+	localContent := local.Content()
+	upstreamContent := upstream.Content()
+	base := localContent
+	if local.SourceSnapshot != nil {
+		base = *local.SourceSnapshot
+	}
+	edited := diffWordContent(base, localContent)
+
+	for _, field := range diffWordContent(base, upstreamContent) {
+		if containsField(edited, field) {
+			continue
+		}
+		switch field {
+		case "word":
+			local.Word = upstream.Word
+		case "definition":
+			local.Definition = upstream.Definition
+		case "example":
+			local.Example = upstream.Example
+		case "word_type":
+			local.WordType = upstream.WordType
+		case "pronunciation":
+			local.Pronunciation = upstream.Pronunciation
+		case "part_of_speech":
+			local.PartOfSpeech = upstream.PartOfSpeech
+		case "senses":
+			local.Senses = upstream.Senses
+		case "synonyms":
+			local.Synonyms = upstream.Synonyms
+		case "antonyms":
+			local.Antonyms = upstream.Antonyms
+		case "fields":
+			local.Fields = upstream.Fields
+		}
+	}
+
+	local.syncLegacyFields()
+
+	local.SourceSnapshot = &upstreamContent
+}
+
+func diffWordContent(a, b WordContent) []string {
+
+	var fields []string
+	if a.Word != b.Word {
+		fields = append(fields, "word")
+	}
+	if a.Definition != b.Definition {
+		fields = append(fields, "definition")
+	}
+	if stringValue(a.Example) != stringValue(b.Example) {
+		fields = append(fields, "example")
+	}
+	if a.WordType != b.WordType {
+		fields = append(fields, "word_type")
+	}
+	if a.Pronunciation != b.Pronunciation {
+		fields = append(fields, "pronunciation")
+	}
+	if a.PartOfSpeech != b.PartOfSpeech {
+		fields = append(fields, "part_of_speech")
+	}
+	if !equalSenses(a.Senses, b.Senses) {
+		fields = append(fields, "senses")
+	}
+	if !equalStrings(a.Synonyms, b.Synonyms) {
+		fields = append(fields, "synonyms")
+	}
+	if !equalStrings(a.Antonyms, b.Antonyms) {
+		fields = append(fields, "antonyms")
+	}
+	if !equalFieldValues(a.Fields, b.Fields) {
+		fields = append(fields, "fields")
+	}
+	return fields
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSenses(a, b []Sense) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Definition != b[i].Definition || !equalStrings(a[i].Examples, b[i].Examples) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalFieldValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
@@ -0,0 +1,85 @@
+package words
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GenerateSpeech synthesizes audio of the word and its example in the word's
+// source language, falling back to its topic's. Unchanged text reuses the
+// cached clip, so regenerating is cheap.
+func (s *wordService) GenerateSpeech(c context.Context, id string, userID string, req *GenerateSpeechRequest) (*WordSpeech, error) {
+
+	if s.speech == nil {
+		return nil, fmt.Errorf("text-to-speech is not configured")
+	}
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanEditTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	topicLanguage, _, err := s.topicAccess.GetTopicLanguages(c, word.TopicID)
+	if err != nil {
+		return nil, err
+	}
+	language := firstNonEmpty(word.SourceLanguage, topicLanguage)
+
+	generated := &WordSpeech{}
+	if generated.Word, err = s.speak(c, word.Word, language, req.Voice); err != nil {
+		return nil, err
+	}
+
+	if example := strings.TrimSpace(stringValue(word.Example)); example != "" {
+		if generated.Example, err = s.speak(c, example, language, req.Voice); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.wordRepository.SetSpeech(c, word.ID, generated); err != nil {
+		return nil, err
+	}
+
+	return generated, nil
+}
+
+func (s *wordService) speak(c context.Context, text string, language string, voice string) (*SpeechAudio, error) {
+
+	clip, err := s.speech.Synthesize(c, text, language, voice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpeechAudio{
+		Text:     strings.TrimSpace(text),
+		URL:      clip.URL(),
+		Language: clip.Language,
+		Voice:    clip.Voice,
+	}, nil
+}
+
+// dropStaleSpeech forgets generated audio whose text no longer matches the
+// word, so clients never play an outdated pronunciation.
+func (w *Word) dropStaleSpeech() {
+
+	if w.Speech == nil {
+		return
+	}
+
+	if w.Speech.Word != nil && w.Speech.Word.Text != strings.TrimSpace(w.Word) {
+		w.Speech.Word = nil
+	}
+
+	if w.Speech.Example != nil && w.Speech.Example.Text != strings.TrimSpace(stringValue(w.Example)) {
+		w.Speech.Example = nil
+	}
+
+	if w.Speech.Word == nil && w.Speech.Example == nil {
+		w.Speech = nil
+	}
+}
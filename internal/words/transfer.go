@@ -0,0 +1,288 @@
+package words
+
+import (
+	"context"
+	"flashcard/helper"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MoveWords reassigns words the user can edit to a topic they own. The
+// words keep their IDs, and their cards follow them.
+func (s *wordService) MoveWords(c context.Context, userID string, req *TransferWordsRequest) (*TransferWordsResponse, error) {
+
+	targetID, objectUserID, selected, err := s.loadTransfer(c, userID, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs := topicIDs(selected)
+
+	if err := s.moveWords(c, objectUserID, targetID, selected, req.ResetProgress); err != nil {
+		return nil, err
+	}
+
+	return s.transferResponse(c, objectUserID, selected, append(sourceIDs, targetID))
+}
+
+// CopyWords adds copies of words the user can view to a topic they own. The
+// copies belong to the user and start from the user's progress and schedule
+// on the source unless the progress is reset.
+func (s *wordService) CopyWords(c context.Context, userID string, req *TransferWordsRequest) (*TransferWordsResponse, error) {
+
+	targetID, objectUserID, selected, err := s.loadTransfer(c, userID, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	copies := make([]*Word, 0, len(selected))
+	copiesBySource := make(map[primitive.ObjectID]*Word, len(selected))
+	for _, source := range selected {
+		word := *source
+		word.ID = primitive.NewObjectID()
+		word.TopicID = targetID
+		word.UserID = objectUserID
+		word.SourceWordID = nil
+		word.SourceSnapshot = nil
+		word.Version = 1
+		word.CreatedAt = now
+		word.UpdatedAt = now
+		resetProgress(&word)
+		copies = append(copies, &word)
+		copiesBySource[source.ID] = &word
+	}
+
+	if err := s.wordRepository.CreateWords(c, copies); err != nil {
+		return nil, err
+	}
+
+	if req.ResetProgress {
+		err = s.cardSync.SyncWordCards(c, copies...)
+	} else {
+		err = s.copyProgress(c, objectUserID, selected, copiesBySource)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicRevisions.IncrementRevision(c, targetID); err != nil {
+		return nil, err
+	}
+
+	for _, word := range copies {
+		if err := s.topicAccess.RecordWordActivity(c, targetID, objectUserID, ActivityWordAdded, word); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.transferResponse(c, objectUserID, copies, []primitive.ObjectID{targetID})
+}
+
+// moveWords rewrites the topic of each word, per source topic, and leaves
+// tombstones behind so offline clients of a source topic drop the words.
+// Words already in the target are left alone.
+func (s *wordService) moveWords(c context.Context, objectUserID primitive.ObjectID, targetID primitive.ObjectID, selected []*Word, reset bool) error {
+
+	now := time.Now()
+	set := bson.M{"topic_id": targetID, "updated_at": now}
+
+	bySource := make(map[primitive.ObjectID][]*Word)
+	for _, word := range selected {
+		if word.TopicID != targetID {
+			bySource[word.TopicID] = append(bySource[word.TopicID], word)
+		}
+	}
+
+	if len(bySource) == 0 {
+		return nil
+	}
+
+	var moved []*Word
+	for sourceID, sourceWords := range bySource {
+		if _, err := s.wordRepository.UpdateWordsInTopic(c, sourceID, wordIDs(sourceWords), set, nil); err != nil {
+			return err
+		}
+
+		if err := s.deletionLog.RecordWordDeletions(c, sourceID, wordIDs(sourceWords)...); err != nil {
+			return err
+		}
+
+		if err := s.topicRevisions.IncrementRevision(c, sourceID); err != nil {
+			return err
+		}
+
+		for _, word := range sourceWords {
+			if err := s.topicAccess.RecordWordActivity(c, sourceID, objectUserID, ActivityWordDeleted, word); err != nil {
+				return err
+			}
+			word.TopicID = targetID
+			word.Version++
+			word.UpdatedAt = now
+		}
+		moved = append(moved, sourceWords...)
+	}
+
+	if err := s.cardSync.SyncWordCards(c, moved...); err != nil {
+		return err
+	}
+
+	if reset {
+		if err := s.saveProgress(c, objectUserID, moved, resetWordProgress); err != nil {
+			return err
+		}
+		if err := s.cardSync.ResetWordCards(c, objectUserID, wordIDs(moved)...); err != nil {
+			return err
+		}
+	}
+
+	if err := s.topicRevisions.IncrementRevision(c, targetID); err != nil {
+		return err
+	}
+
+	for _, word := range moved {
+		if err := s.topicAccess.RecordWordActivity(c, targetID, objectUserID, ActivityWordAdded, word); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadTransfer validates a move or copy: the user must own the target and be
+// able to view every source topic, or edit it when the words leave it.
+func (s *wordService) loadTransfer(c context.Context, userID string, req *TransferWordsRequest, editSources bool) (primitive.ObjectID, primitive.ObjectID, []*Word, error) {
+
+	if len(req.WordIDs) == 0 {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, fmt.Errorf("at least one word id is required")
+	}
+
+	if len(req.WordIDs) > maxBatchOperations {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, fmt.Errorf("at most %d words can be transferred at once", maxBatchOperations)
+	}
+
+	if req.TargetTopicID == "" {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, fmt.Errorf("target topic id is required")
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(req.TargetTopicID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	if err := s.topicAccess.CanOwnTopic(c, targetID, objectUserID); err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(req.WordIDs))
+	requested := make(map[primitive.ObjectID]bool, len(req.WordIDs))
+	for _, id := range req.WordIDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return primitive.NilObjectID, primitive.NilObjectID, nil, err
+		}
+		if !requested[objectID] {
+			requested[objectID] = true
+			ids = append(ids, objectID)
+		}
+	}
+
+	selected, err := s.wordRepository.GetWordsByIDs(c, ids)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+
+	if len(selected) != len(ids) {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, fmt.Errorf("%w: word not found", helper.ErrResourceNotFound)
+	}
+
+	for _, topicID := range topicIDs(selected) {
+		authorize := s.topicAccess.CanViewTopic
+		if editSources {
+			authorize = s.topicAccess.CanEditTopic
+		}
+		if err := authorize(c, topicID, objectUserID); err != nil {
+			return primitive.NilObjectID, primitive.NilObjectID, nil, err
+		}
+	}
+
+	return targetID, objectUserID, selected, nil
+}
+
+func (s *wordService) transferResponse(c context.Context, userID primitive.ObjectID, transferred []*Word, topics []primitive.ObjectID) (*TransferWordsResponse, error) {
+
+	if err := s.withProgress(c, userID, transferred...); err != nil {
+		return nil, err
+	}
+
+	response := &TransferWordsResponse{Words: transferred, Topics: make([]*TopicWordCount, 0, len(topics))}
+
+	counted := make(map[primitive.ObjectID]bool, len(topics))
+	for _, topicID := range topics {
+		if counted[topicID] {
+			continue
+		}
+		counted[topicID] = true
+
+		count, err := s.wordRepository.CountWordsByTopicID(c, topicID)
+		if err != nil {
+			return nil, err
+		}
+		response.Topics = append(response.Topics, &TopicWordCount{TopicID: topicID, WordCount: count})
+	}
+
+	return response, nil
+}
+
+// copyProgress gives the copies the user's progress on their source words,
+// keyed by source word ID, and the user's schedules of their cards.
+func (s *wordService) copyProgress(c context.Context, userID primitive.ObjectID, sources []*Word, copies map[primitive.ObjectID]*Word) error {
+
+	progress, err := s.progressOf(c, userID, sources)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	copied := make([]*WordProgress, 0, len(copies))
+	for sourceID, word := range copies {
+		p := *progress[sourceID]
+		p.ID = progressID(word.ID, userID)
+		p.WordID = word.ID
+		p.UpdatedAt = now
+		copied = append(copied, &p)
+	}
+
+	if err := s.progressRepository.SaveProgress(c, copied); err != nil {
+		return err
+	}
+
+	return s.cardSync.CopyWordCards(c, userID, copies)
+}
+
+func resetProgress(word *Word) {
+	word.ReviewCount = 0
+	word.CorrectCount = 0
+	word.IsTrue = false
+	word.LastReviewedAt = nil
+}
+
+func topicIDs(words []*Word) []primitive.ObjectID {
+	seen := make(map[primitive.ObjectID]bool)
+	ids := make([]primitive.ObjectID, 0)
+	for _, word := range words {
+		if !seen[word.TopicID] {
+			seen[word.TopicID] = true
+			ids = append(ids, word.TopicID)
+		}
+	}
+	return ids
+}
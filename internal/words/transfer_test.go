@@ -0,0 +1,233 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"flashcard/helper"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMoveWords(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	sourceA := primitive.NewObjectID()
+	sourceB := primitive.NewObjectID()
+	viewOnly := primitive.NewObjectID()
+	targetID := primitive.NewObjectID()
+
+	cases := []struct {
+		name      string
+		ids       func(a, b, inTarget, viewed *Word) []string
+		reset     bool
+		wantErr   error
+		wantMoved int
+		check     func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word)
+	}{
+		{
+			name:      "words from several topics move and leave tombstones",
+			ids:       func(a, b, inTarget, viewed *Word) []string { return []string{a.ID.Hex(), b.ID.Hex()} },
+			wantMoved: 2,
+			check: func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word) {
+				for _, word := range []*Word{a, b} {
+					if got := ts.words.words[word.ID]; got.TopicID != targetID || got.Version != 2 {
+						t.Errorf("%s in %s at version %d, want the target at version 2", word.Word, got.TopicID.Hex(), got.Version)
+					}
+				}
+				if len(ts.deletion.deleted) != 2 {
+					t.Errorf("tombstoned %d words, want 2", len(ts.deletion.deleted))
+				}
+				counts := make(map[primitive.ObjectID]int64)
+				for _, topic := range resp.Topics {
+					counts[topic.TopicID] = topic.WordCount
+				}
+				if counts[sourceA] != 0 || counts[sourceB] != 0 || counts[targetID] != 3 {
+					t.Errorf("topic counts = %v, want both sources empty and 3 in the target", counts)
+				}
+			},
+		},
+		{
+			name:      "a word named twice moves once",
+			ids:       func(a, b, inTarget, viewed *Word) []string { return []string{a.ID.Hex(), a.ID.Hex()} },
+			wantMoved: 1,
+			check: func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word) {
+				if got := ts.words.words[a.ID].Version; got != 2 {
+					t.Errorf("word a at version %d, want 2", got)
+				}
+			},
+		},
+		{
+			name:      "a word already in the target is left alone",
+			ids:       func(a, b, inTarget, viewed *Word) []string { return []string{a.ID.Hex(), inTarget.ID.Hex()} },
+			wantMoved: 2,
+			check: func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word) {
+				if got := ts.words.words[inTarget.ID].Version; got != 1 {
+					t.Errorf("word in the target at version %d, want 1", got)
+				}
+				if len(ts.deletion.deleted) != 1 || ts.deletion.deleted[0] != a.ID {
+					t.Errorf("tombstoned %v, want only word a", ts.deletion.deleted)
+				}
+			},
+		},
+		{
+			name: "a missing word moves nothing",
+			ids: func(a, b, inTarget, viewed *Word) []string {
+				return []string{a.ID.Hex(), primitive.NewObjectID().Hex()}
+			},
+			wantErr: helper.ErrResourceNotFound,
+			check: func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word) {
+				if got := ts.words.words[a.ID].TopicID; got != sourceA {
+					t.Errorf("word a moved to %s", got.Hex())
+				}
+			},
+		},
+		{
+			name:    "words of a topic the user only views cannot move",
+			ids:     func(a, b, inTarget, viewed *Word) []string { return []string{a.ID.Hex(), viewed.ID.Hex()} },
+			wantErr: helper.ErrPermissionDenied,
+			check: func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word) {
+				if got := ts.words.words[a.ID].TopicID; got != sourceA {
+					t.Errorf("word a moved to %s", got.Hex())
+				}
+			},
+		},
+		{
+			name:      "resetting clears the user's progress and cards",
+			ids:       func(a, b, inTarget, viewed *Word) []string { return []string{a.ID.Hex()} },
+			reset:     true,
+			wantMoved: 1,
+			check: func(t *testing.T, ts *testWordService, resp *TransferWordsResponse, a, b, inTarget, viewed *Word) {
+				if p := ts.progress.progress[progressID(a.ID, userID)]; p == nil || p.ReviewCount != 0 || p.IsTrue {
+					t.Errorf("progress = %+v, want reset", p)
+				}
+				if resp.Words[0].ReviewCount != 0 {
+					t.Errorf("response shows %d reviews, want 0", resp.Words[0].ReviewCount)
+				}
+				if len(ts.cards.reset) != 1 {
+					t.Errorf("reset cards of %d words, want 1", len(ts.cards.reset))
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			a := testWord(sourceA, userID, "alpha")
+			a.IsTrue, a.ReviewCount = true, 3
+			b := testWord(sourceB, userID, "beta")
+			inTarget := testWord(targetID, userID, "gamma")
+			viewed := testWord(viewOnly, primitive.NewObjectID(), "delta")
+
+			ts := newTestWordService(a, b, inTarget, viewed)
+			ts.access.grant(sourceA, userID, "owner")
+			ts.access.grant(sourceB, userID, "editor")
+			ts.access.grant(viewOnly, userID, "viewer")
+			ts.access.grant(targetID, userID, "owner")
+
+			resp, err := ts.MoveWords(context.Background(), userID.Hex(), &TransferWordsRequest{
+				TargetTopicID: targetID.Hex(),
+				WordIDs:       tc.ids(a, b, inTarget, viewed),
+				ResetProgress: tc.reset,
+			})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("MoveWords: %v", err)
+			} else if len(resp.Words) != tc.wantMoved {
+				t.Errorf("got %d words, want %d", len(resp.Words), tc.wantMoved)
+			}
+
+			if tc.check != nil {
+				tc.check(t, ts, resp, a, b, inTarget, viewed)
+			}
+		})
+	}
+}
+
+func TestCopyWords(t *testing.T) {
+
+	ownerID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+	sourceID := primitive.NewObjectID()
+	targetID := primitive.NewObjectID()
+	otherTarget := primitive.NewObjectID()
+
+	cases := []struct {
+		name       string
+		target     primitive.ObjectID
+		reset      bool
+		withStudy  bool
+		wantErr    error
+		wantKnown  bool
+		wantCopied bool
+	}{
+		{name: "copies start from the user's own progress", target: targetID, withStudy: true, wantKnown: true, wantCopied: true},
+		{name: "the owner's progress does not pass to a viewer's copy", target: targetID, wantCopied: true},
+		{name: "resetting starts copies fresh", target: targetID, withStudy: true, reset: true},
+		{name: "copying into a topic the user does not own fails", target: otherTarget, wantErr: helper.ErrPermissionDenied},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			source := testWord(sourceID, ownerID, "alpha")
+			source.IsTrue, source.ReviewCount, source.CorrectCount = true, 5, 5
+
+			ts := newTestWordService(source)
+			ts.access.grant(sourceID, ownerID, "owner")
+			ts.access.grant(sourceID, userID, "viewer")
+			ts.access.grant(targetID, userID, "owner")
+			ts.access.grant(otherTarget, userID, "editor")
+			if tc.withStudy {
+				ts.progress.progress[progressID(source.ID, userID)] = &WordProgress{ID: progressID(source.ID, userID), WordID: source.ID, UserID: userID, IsTrue: true, ReviewCount: 2, CorrectCount: 2}
+			}
+
+			resp, err := ts.CopyWords(context.Background(), userID.Hex(), &TransferWordsRequest{
+				TargetTopicID: tc.target.Hex(),
+				WordIDs:       []string{source.ID.Hex()},
+				ResetProgress: tc.reset,
+			})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+				if len(ts.words.words) != 1 {
+					t.Errorf("got %d stored words, want only the source", len(ts.words.words))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CopyWords: %v", err)
+			}
+
+			if len(resp.Words) != 1 {
+				t.Fatalf("got %d copies, want 1", len(resp.Words))
+			}
+			copied := resp.Words[0]
+			stored := ts.words.words[copied.ID]
+			if copied.ID == source.ID || stored == nil || stored.TopicID != targetID || stored.UserID != userID {
+				t.Fatalf("copy = %+v, want a new word of the user in the target", stored)
+			}
+			if stored.IsTrue || stored.ReviewCount != 0 {
+				t.Errorf("stored copy carries progress: is_true %v, %d reviews", stored.IsTrue, stored.ReviewCount)
+			}
+			if copied.IsTrue != tc.wantKnown {
+				t.Errorf("copy known = %v, want %v", copied.IsTrue, tc.wantKnown)
+			}
+			if got := ts.words.words[source.ID]; got.ReviewCount != 5 {
+				t.Errorf("source has %d reviews, want it untouched", got.ReviewCount)
+			}
+			if (ts.cards.copied != nil) != tc.wantCopied {
+				t.Errorf("copied card schedules = %v, want %v", ts.cards.copied != nil, tc.wantCopied)
+			}
+			if !tc.wantCopied && len(ts.cards.synced) != 1 {
+				t.Errorf("synced cards of %d words, want fresh cards for the copy", len(ts.cards.synced))
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package words
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SuggestTranslations translates a word and its example into the word's
+// target language, falling back to its topic's. The source language is the
+// word's, then the topic's, and otherwise left to the provider to detect.
+func (s *wordService) SuggestTranslations(c context.Context, id string, userID string) (*TranslationSuggestion, error) {
+
+	if s.translator == nil {
+		return nil, fmt.Errorf("translation is not configured")
+	}
+
+	word, objectUserID, err := s.loadWord(c, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicAccess.CanViewTopic(c, word.TopicID, objectUserID); err != nil {
+		return nil, err
+	}
+
+	topicLanguage, topicTargetLanguage, err := s.topicAccess.GetTopicLanguages(c, word.TopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := &TranslationSuggestion{
+		SourceLanguage: firstNonEmpty(word.SourceLanguage, topicLanguage),
+		TargetLanguage: firstNonEmpty(word.TargetLanguage, topicTargetLanguage),
+	}
+
+	if suggestion.TargetLanguage == "" {
+		return nil, fmt.Errorf("topic has no target language")
+	}
+
+	if suggestion.Word, err = s.translator.Translate(c, word.Word, suggestion.SourceLanguage, suggestion.TargetLanguage); err != nil {
+		return nil, err
+	}
+
+	if example := strings.TrimSpace(stringValue(word.Example)); example != "" {
+		translated, err := s.translator.Translate(c, example, suggestion.SourceLanguage, suggestion.TargetLanguage)
+		if err != nil {
+			return nil, err
+		}
+		suggestion.Example = &translated
+	}
+
+	return suggestion, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
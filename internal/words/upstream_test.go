@@ -0,0 +1,263 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"flashcard/helper"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// upstreamFixture is a deck and a clone of it synced before the deck changed:
+//
+//   - same: unchanged on both sides
+//   - changed: definition changed upstream
+//   - edited: definition changed upstream and locally
+//   - added: new upstream
+//   - dropped: removed upstream, untouched locally
+//   - rewritten: removed upstream, edited locally
+//   - studied: removed upstream, studied by a member of the clone
+//   - reviewed: removed upstream, studied before progress was kept per user
+type upstreamFixture struct {
+	upstreamID, localID, userID primitive.ObjectID
+	upstream                    map[string]*Word
+	local                       map[string]*Word
+}
+
+func newUpstreamFixture() *upstreamFixture {
+
+	f := &upstreamFixture{
+		upstreamID: primitive.NewObjectID(),
+		localID:    primitive.NewObjectID(),
+		userID:     primitive.NewObjectID(),
+		upstream:   make(map[string]*Word),
+		local:      make(map[string]*Word),
+	}
+	authorID := primitive.NewObjectID()
+
+	for _, name := range []string{"same", "changed", "edited", "dropped", "rewritten", "studied", "reviewed"} {
+		source := testWord(f.upstreamID, authorID, name)
+		clone := testWord(f.localID, f.userID, name)
+		sourceID := source.ID
+		snapshot := copyWord(source).Content()
+		clone.SourceWordID = &sourceID
+		clone.SourceSnapshot = &snapshot
+		f.upstream[name] = source
+		f.local[name] = clone
+	}
+	f.upstream["added"] = testWord(f.upstreamID, authorID, "added")
+
+	define(f.upstream["changed"], "changed upstream")
+	define(f.upstream["edited"], "edited upstream")
+	define(f.local["edited"], "edited locally")
+	define(f.local["rewritten"], "rewritten locally")
+	f.local["reviewed"].ReviewCount = 2
+	for _, name := range []string{"dropped", "rewritten", "studied", "reviewed"} {
+		delete(f.upstream, name)
+	}
+
+	return f
+}
+
+// define edits a word's definition the way an update does, keeping its
+// first sense in step.
+func define(word *Word, definition string) {
+	word.Definition = definition
+	word.Senses[0].Definition = definition
+}
+
+func (f *upstreamFixture) service() *testWordService {
+
+	var all []*Word
+	for _, word := range f.upstream {
+		all = append(all, word)
+	}
+	for _, word := range f.local {
+		all = append(all, word)
+	}
+
+	ts := newTestWordService(all...)
+	ts.access.grant(f.localID, f.userID, "owner")
+
+	studied := f.local["studied"]
+	memberID := primitive.NewObjectID()
+	ts.progress.progress[progressID(studied.ID, memberID)] = &WordProgress{ID: progressID(studied.ID, memberID), WordID: studied.ID, UserID: memberID, ReviewCount: 1}
+
+	return ts
+}
+
+// names maps the changes back to fixture names through their upstream IDs.
+func (f *upstreamFixture) names(changes []*UpstreamWordChange) []string {
+
+	byID := make(map[primitive.ObjectID]string)
+	for name, word := range f.upstream {
+		byID[word.ID] = name
+	}
+	for name, word := range f.local {
+		byID[*word.SourceWordID] = name
+	}
+
+	names := make([]string, 0, len(changes))
+	for _, change := range changes {
+		names = append(names, byID[change.UpstreamWordID])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sameNames(got []string, want ...string) bool {
+	sort.Strings(want)
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiffUpstream(t *testing.T) {
+
+	f := newUpstreamFixture()
+	ts := f.service()
+
+	diff, err := ts.DiffUpstream(context.Background(), f.localID.Hex(), f.upstreamID.Hex())
+	if err != nil {
+		t.Fatalf("DiffUpstream: %v", err)
+	}
+
+	if got := f.names(diff.Added); !sameNames(got, "added") {
+		t.Errorf("added = %v", got)
+	}
+	if got := f.names(diff.Changed); !sameNames(got, "changed", "edited") {
+		t.Errorf("changed = %v", got)
+	}
+	if got := f.names(diff.Removed); !sameNames(got, "dropped", "rewritten", "studied", "reviewed") {
+		t.Errorf("removed = %v", got)
+	}
+
+	for _, change := range append(diff.Changed, diff.Removed...) {
+		name := f.names([]*UpstreamWordChange{change})[0]
+		wantConflicts := name == "edited" || name == "rewritten"
+		if got := len(change.ConflictingFields) > 0; got != wantConflicts {
+			t.Errorf("%s: conflicting fields %v, want conflicts %v", name, change.ConflictingFields, wantConflicts)
+		}
+		wantProgress := name == "studied" || name == "reviewed"
+		if change.HasProgress != wantProgress {
+			t.Errorf("%s: has progress %v, want %v", name, change.HasProgress, wantProgress)
+		}
+	}
+}
+
+func TestMergeUpstream(t *testing.T) {
+
+	cases := []struct {
+		name string
+		// request builds the merge request from the fixture.
+		request       func(f *upstreamFixture) *MergeUpstreamRequest
+		concurrent    bool
+		wantErr       error
+		wantAdded     []string
+		wantChanged   []string
+		wantRemoved   []string
+		wantConflicts []string
+		wantKept      []string
+	}{
+		{
+			name:          "applying everything keeps edited and studied words",
+			request:       func(f *upstreamFixture) *MergeUpstreamRequest { return &MergeUpstreamRequest{ApplyAll: true} },
+			wantAdded:     []string{"added"},
+			wantChanged:   []string{"changed", "edited"},
+			wantRemoved:   []string{"dropped"},
+			wantConflicts: []string{"rewritten", "studied", "reviewed"},
+			wantKept:      []string{"same", "changed", "edited", "rewritten", "studied", "reviewed"},
+		},
+		{
+			name: "words picked explicitly are removed despite edits and progress",
+			request: func(f *upstreamFixture) *MergeUpstreamRequest {
+				return &MergeUpstreamRequest{Remove: []string{f.local["rewritten"].SourceWordID.Hex(), f.local["studied"].SourceWordID.Hex()}}
+			},
+			wantRemoved: []string{"rewritten", "studied"},
+			wantKept:    []string{"same", "changed", "edited", "dropped", "reviewed"},
+		},
+		{
+			name:     "nothing picked changes nothing",
+			request:  func(f *upstreamFixture) *MergeUpstreamRequest { return &MergeUpstreamRequest{} },
+			wantKept: []string{"same", "changed", "edited", "dropped", "rewritten", "studied", "reviewed"},
+		},
+		{
+			name: "a word changed while merging is a version conflict",
+			request: func(f *upstreamFixture) *MergeUpstreamRequest {
+				return &MergeUpstreamRequest{Update: []string{f.upstream["changed"].ID.Hex()}}
+			},
+			concurrent: true,
+			wantErr:    helper.ErrPreconditionFailed,
+			wantKept:   []string{"same", "changed", "edited", "dropped", "rewritten", "studied", "reviewed"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			f := newUpstreamFixture()
+			ts := f.service()
+			if tc.concurrent {
+				ts.words.beforeUpdate = func() { ts.words.words[f.local["changed"].ID].Version++ }
+			}
+
+			applied, err := ts.MergeUpstream(context.Background(), f.localID.Hex(), f.upstreamID.Hex(), f.userID.Hex(), tc.request(f))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("MergeUpstream: %v", err)
+			} else {
+				if got := f.names(applied.Added); !sameNames(got, tc.wantAdded...) {
+					t.Errorf("added = %v, want %v", got, tc.wantAdded)
+				}
+				if got := f.names(applied.Changed); !sameNames(got, tc.wantChanged...) {
+					t.Errorf("changed = %v, want %v", got, tc.wantChanged)
+				}
+				if got := f.names(applied.Removed); !sameNames(got, tc.wantRemoved...) {
+					t.Errorf("removed = %v, want %v", got, tc.wantRemoved)
+				}
+				if got := f.names(applied.Conflicts); !sameNames(got, tc.wantConflicts...) {
+					t.Errorf("conflicts = %v, want %v", got, tc.wantConflicts)
+				}
+			}
+
+			for _, name := range tc.wantKept {
+				if _, ok := ts.words.words[f.local[name].ID]; !ok {
+					t.Errorf("local word %q was removed", name)
+				}
+			}
+			for _, name := range tc.wantRemoved {
+				if _, ok := ts.words.words[f.local[name].ID]; ok {
+					t.Errorf("local word %q was kept", name)
+				}
+			}
+
+			if containsField(tc.wantChanged, "changed") {
+				if got := ts.words.words[f.local["changed"].ID].Definition; got != "changed upstream" {
+					t.Errorf("changed word has definition %q, want the upstream one", got)
+				}
+			}
+			if containsField(tc.wantChanged, "edited") {
+				if got := ts.words.words[f.local["edited"].ID].Definition; got != "edited locally" {
+					t.Errorf("edited word has definition %q, want the learner's kept", got)
+				}
+			}
+			if len(tc.wantAdded) > 0 {
+				if count, _ := ts.words.CountWordsByTopicID(context.Background(), f.localID); count != int64(len(tc.wantKept)+len(tc.wantAdded)) {
+					t.Errorf("clone has %d words, want the added word too", count)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -11,6 +12,18 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, userID string, sessionVersion int) error
+}
+
+var sessionValidator SessionValidator
+
+// SetSessionValidator lets the auth middleware reject tokens whose session
+// was revoked (e.g. after a password change) before they expire.
+func SetSessionValidator(validator SessionValidator) {
+	sessionValidator = validator
+}
+
 func JWTAuthMiddleware() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
@@ -57,6 +70,20 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+			if tokenType, ok := claims["typ"].(string); ok && tokenType != "access" {
+				c.JSON(401, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+			if sessionValidator != nil {
+				userID, _ := claims["user_id"].(string)
+				sessionVersion, _ := claims["sv"].(float64)
+				if err := sessionValidator.ValidateSession(c, userID, int(sessionVersion)); err != nil {
+					c.JSON(401, gin.H{"error": "Session revoked"})
+					c.Abort()
+					return
+				}
+			}
 			c.Set("user_id", claims["user_id"])
 		}
 
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"flashcard/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitStore keeps token buckets keyed by client. The in-memory store is
+// enough for a single instance; a shared store (e.g. Redis) can implement the
+// same interface when the API is scaled out.
+type RateLimitStore interface {
+	Take(key string, limit RateLimit) (bool, time.Duration)
+}
+
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// maxKeyBodyBytes bounds the body JSONFieldKey reads. Auth requests are small
+// JSON documents, and the body is read before the limit is checked.
+const maxKeyBodyBytes = 8 << 10
+
+var (
+	errTooManyRequests = errors.New("too many requests")
+	errBodyTooLarge    = errors.New("request body too large")
+)
+
+type tokenBucket struct {
+	tokens    float64
+	window    time.Duration
+	updatedAt time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryRateLimitStore) Take(key string, limit RateLimit) (bool, time.Duration) {
+
+	now := time.Now()
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Window.Seconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, window: limit.Window, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	bucket.updatedAt = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) > bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func RateLimitMiddleware(store RateLimitStore, limit RateLimit, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		if limit.Requests <= 0 || limit.Window <= 0 {
+			c.Next()
+			return
+		}
+
+		key := keyFunc(c)
+		if c.IsAborted() {
+			return
+		}
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter := store.Take(key, limit)
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds(retryAfter)))
+			helper.SendError(c, http.StatusTooManyRequests, errTooManyRequests, helper.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.FullPath() + ":" + c.ClientIP()
+}
+
+// JSONFieldKey keys the bucket on a field of the JSON body (e.g. the email of
+// a login attempt) and restores the body for the handler. Bodies larger than
+// maxKeyBodyBytes are rejected.
+func JSONFieldKey(field string) RateLimitKeyFunc {
+
+	return func(c *gin.Context) string {
+
+		if c.Request.Body == nil {
+			return ""
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxKeyBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				helper.SendError(c, http.StatusRequestEntityTooLarge, errBodyTooLarge, helper.ErrInvalidRequest)
+				c.Abort()
+			}
+			return ""
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+
+		value, ok := payload[field].(string)
+		if !ok || value == "" {
+			return ""
+		}
+
+		return "account:" + c.FullPath() + ":" + strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}